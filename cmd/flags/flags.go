@@ -32,6 +32,11 @@ var (
 		Name:  "height",
 		Usage: "block height",
 	}
+	RederiveFlag = &cli.BoolFlag{
+		Name:  "rederive",
+		Value: false,
+		Usage: "derive the witness from the database again and compare it with the stored one",
+	}
 	ServiceNameFlag = &cli.StringFlag{
 		Name:  "service",
 		Usage: "service name(committer, witness)",
@@ -41,6 +46,14 @@ var (
 		Value: 1000,
 		Usage: "batch size for reading history record from the database",
 	}
+	LevelDBFileFlag = &cli.StringFlag{
+		Name:  "leveldb",
+		Usage: "the leveldb tree database directory to migrate",
+	}
+	SnapshotFileFlag = &cli.StringFlag{
+		Name:  "snapshot",
+		Usage: "the snapshot file",
+	}
 	PProfEnabledFlag = &cli.BoolFlag{
 		Name:  "pprof",
 		Value: false,
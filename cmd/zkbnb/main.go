@@ -21,6 +21,11 @@ import (
 	"github.com/bnb-chain/zkbnb/service/witness"
 	"github.com/bnb-chain/zkbnb/tools/dbinitializer"
 	"github.com/bnb-chain/zkbnb/tools/recovery"
+	"github.com/bnb-chain/zkbnb/tools/rollback"
+	"github.com/bnb-chain/zkbnb/tools/snapshot"
+	"github.com/bnb-chain/zkbnb/tools/treemigrate"
+	"github.com/bnb-chain/zkbnb/tools/treeverify"
+	"github.com/bnb-chain/zkbnb/tools/witnesscheck"
 
 	"net/http"
 )
@@ -88,6 +93,29 @@ func main() {
 					startMetricsServer(cCtx)
 					return witness.Run(cCtx.String(flags.ConfigFlag.Name))
 				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "Check whether the stored witness of a block satisfies the circuit",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+							flags.RederiveFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockHeightFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return witnesscheck.CheckBlockWitness(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+								cCtx.Bool(flags.RederiveFlag.Name),
+							)
+						},
+					},
+				},
 			},
 			{
 				Name:  "monitor",
@@ -211,6 +239,25 @@ func main() {
 							)
 						},
 					},
+					{
+						Name:  "rollback",
+						Usage: "Roll back the l2 state to a block height, e.g. after blocks are reverted on l1",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockHeightFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return rollback.RollbackToHeight(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+							)
+						},
+					},
 				},
 			},
 			{
@@ -241,6 +288,99 @@ func main() {
 							return nil
 						},
 					},
+					{
+						Name:  "verify",
+						Usage: "Verify the treedb against the database at a block height",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+							flags.ServiceNameFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockHeightFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return treeverify.VerifyTreeDB(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "migrate",
+						Usage: "Migrate a leveldb treedb to pebble",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.LevelDBFileFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) ||
+								!cCtx.IsSet(flags.LevelDBFileFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return treemigrate.MigrateTreeDB(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.LevelDBFileFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "snapshot",
+				Usage: "Export and import the l2 state at a verified block height",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export the accounts and nfts at a verified block height into a snapshot file",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+							flags.SnapshotFileFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockHeightFlag.Name) ||
+								!cCtx.IsSet(flags.SnapshotFileFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return snapshot.ExportSnapshot(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+								cCtx.String(flags.SnapshotFileFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "import",
+						Usage: "Import a snapshot file into an initialized database and empty treedb",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.SnapshotFileFlag,
+							flags.ServiceNameFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.SnapshotFileFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return snapshot.ImportSnapshot(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.SnapshotFileFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+							)
+						},
+					},
 				},
 			},
 		},
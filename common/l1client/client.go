@@ -0,0 +1,468 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package l1client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+)
+
+const (
+	defaultRequestTimeout      = 10 // seconds
+	defaultHealthCheckInterval = 15 // seconds
+	defaultMaxFailures         = 3
+	defaultMaxLagBlocks        = 20
+)
+
+var (
+	ErrNoEndpoints       = errors.New("no l1 rpc endpoints configured")
+	ErrNoHealthyEndpoint = errors.New("no healthy l1 rpc endpoint")
+	ErrQuorumNotReached  = errors.New("l1 rpc endpoints quorum not reached")
+)
+
+type Config struct {
+	// Quorum is the number of endpoints which must return identical results
+	// for quorum reads(logs and headers), values less than 2 disable the check.
+	//nolint:staticcheck
+	Quorum int `json:",optional"`
+	// An endpoint whose head is more than MaxLagBlocks behind the best known
+	// head is treated as unhealthy until it catches up.
+	//nolint:staticcheck
+	MaxLagBlocks uint64 `json:",optional"`
+	// Consecutive failed requests before an endpoint is treated as unhealthy.
+	//nolint:staticcheck
+	MaxFailures int `json:",optional"`
+	// Timeout of a single rpc request, in seconds.
+	//nolint:staticcheck
+	RequestTimeout int64 `json:",optional"`
+	// Interval between two endpoint health checks, in seconds.
+	//nolint:staticcheck
+	HealthCheckInterval int64 `json:",optional"`
+}
+
+func (c *Config) sanitize() *Config {
+	if c.MaxLagBlocks == 0 {
+		c.MaxLagBlocks = defaultMaxLagBlocks
+	}
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = defaultMaxFailures
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = defaultRequestTimeout
+	}
+	if c.HealthCheckInterval <= 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	return c
+}
+
+type endpoint struct {
+	url      string
+	cli      *rpc.ProviderClient
+	head     uint64
+	failures int
+	lagging  bool
+}
+
+func (e *endpoint) healthy(maxFailures int) bool {
+	return !e.lagging && e.failures < maxFailures
+}
+
+// Client is a l1 rpc client backed by several endpoints. Requests are sent to
+// the preferred healthy endpoint and fail over to the others on errors, reads
+// used to ingest l1 events can additionally require agreement of a quorum.
+type Client struct {
+	config    Config
+	endpoints []*endpoint
+
+	mu        sync.Mutex
+	preferred int
+	lastCheck time.Time
+}
+
+// ParseEndpoints splits a sysconfig value holding one or several comma
+// separated rpc endpoints.
+func ParseEndpoints(value string) []string {
+	var endpoints []string
+	for _, url := range strings.Split(value, ",") {
+		url = strings.TrimSpace(url)
+		if url != "" {
+			endpoints = append(endpoints, url)
+		}
+	}
+	return endpoints
+}
+
+func NewClient(urls []string, config Config) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	config.sanitize()
+	if config.Quorum > len(urls) {
+		return nil, fmt.Errorf("quorum %d is larger than the number of endpoints %d", config.Quorum, len(urls))
+	}
+	c := &Client{config: config}
+	for _, url := range urls {
+		cli, err := rpc.NewClient(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial l1 rpc endpoint %s, err: %v", url, err)
+		}
+		c.endpoints = append(c.endpoints, &endpoint{url: url, cli: cli})
+	}
+	return c, nil
+}
+
+// Provider returns the raw client of the preferred healthy endpoint, it is
+// used for contract bindings and transaction submission.
+func (c *Client) Provider() *rpc.ProviderClient {
+	c.maybeCheckHealth()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoints[c.preferred].cli
+}
+
+// CheckHealth refreshes the head of every endpoint, marks lagging endpoints
+// and switches the preferred endpoint if it is no longer healthy.
+func (c *Client) CheckHealth() {
+	heads := make([]uint64, len(c.endpoints))
+	errs := make([]error, len(c.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range c.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := c.requestContext()
+			defer cancel()
+			heads[i], errs[i] = e.cli.BlockNumber(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastCheck = time.Now()
+	var best uint64
+	for i := range c.endpoints {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+	for i, e := range c.endpoints {
+		if errs[i] != nil {
+			e.failures++
+			logx.Errorf("l1 rpc endpoint %s health check failed, err: %v", e.url, errs[i])
+			continue
+		}
+		e.head = heads[i]
+		e.failures = 0
+		lagging := best-e.head > c.config.MaxLagBlocks
+		if lagging && !e.lagging {
+			logx.Errorf("l1 rpc endpoint %s is lagging, head: %d, best head: %d", e.url, e.head, best)
+		}
+		e.lagging = lagging
+	}
+	c.selectPreferred()
+}
+
+// selectPreferred must be called with c.mu held.
+func (c *Client) selectPreferred() {
+	if c.endpoints[c.preferred].healthy(c.config.MaxFailures) {
+		return
+	}
+	for i, e := range c.endpoints {
+		if e.healthy(c.config.MaxFailures) {
+			logx.Infof("switch preferred l1 rpc endpoint from %s to %s", c.endpoints[c.preferred].url, e.url)
+			c.preferred = i
+			return
+		}
+	}
+}
+
+func (c *Client) maybeCheckHealth() {
+	c.mu.Lock()
+	due := time.Since(c.lastCheck) >= time.Duration(c.config.HealthCheckInterval)*time.Second
+	c.mu.Unlock()
+	if due {
+		c.CheckHealth()
+	}
+}
+
+func (c *Client) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(c.config.RequestTimeout)*time.Second)
+}
+
+// candidates returns the healthy endpoints, preferred one first.
+func (c *Client) candidates() []*endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	candidates := make([]*endpoint, 0, len(c.endpoints))
+	for i := 0; i < len(c.endpoints); i++ {
+		e := c.endpoints[(c.preferred+i)%len(c.endpoints)]
+		if e.healthy(c.config.MaxFailures) {
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates
+}
+
+// executionErrors are the messages of the json-rpc errors reporting how a
+// call executed, nodes send them with or without revert data.
+var executionErrors = []string{
+	"execution reverted",
+	"gas required exceeds allowance",
+	"exceeds block gas limit",
+	"out of gas",
+	"intrinsic gas too low",
+	"invalid opcode",
+}
+
+// isValidAnswer reports whether the error is an answer of a healthy endpoint,
+// like a missing receipt of a pending tx or a call which reverted or ran out
+// of gas. Only transport errors, timeouts and other node errors fail over.
+func isValidAnswer(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return true
+	}
+	var rpcErr gethrpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.ErrorCode() == 3 { // execution reverted with revert data
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Error())
+	for _, executionError := range executionErrors {
+		if strings.Contains(msg, executionError) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) report(e *endpoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	logx.Errorf("l1 rpc endpoint %s request failed, failures: %d, err: %v", e.url, e.failures, err)
+	c.selectPreferred()
+}
+
+// call runs fn against the healthy endpoints in order until one succeeds.
+func (c *Client) call(fn func(ctx context.Context, cli *rpc.ProviderClient) error) error {
+	c.maybeCheckHealth()
+	candidates := c.candidates()
+	if len(candidates) == 0 {
+		return ErrNoHealthyEndpoint
+	}
+	var err error
+	for _, e := range candidates {
+		ctx, cancel := c.requestContext()
+		err = fn(ctx, e.cli)
+		cancel()
+		if err == nil || isValidAnswer(err) {
+			c.report(e, nil)
+			return err
+		}
+		c.report(e, err)
+	}
+	return err
+}
+
+// quorumCall runs fn against the healthy endpoints concurrently and returns
+// the first result reported identically, according to digest, by a quorum.
+func (c *Client) quorumCall(fn func(ctx context.Context, cli *rpc.ProviderClient) (interface{}, error),
+	digest func(result interface{}) common.Hash) (interface{}, error) {
+	if c.config.Quorum < 2 {
+		var result interface{}
+		err := c.call(func(ctx context.Context, cli *rpc.ProviderClient) (err error) {
+			result, err = fn(ctx, cli)
+			return err
+		})
+		return result, err
+	}
+
+	c.maybeCheckHealth()
+	candidates := c.candidates()
+	if len(candidates) < c.config.Quorum {
+		return nil, ErrQuorumNotReached
+	}
+	results := make([]interface{}, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, e := range candidates {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := c.requestContext()
+			defer cancel()
+			results[i], errs[i] = fn(ctx, e.cli)
+		}(i, e)
+	}
+	wg.Wait()
+
+	votes := make(map[common.Hash]int)
+	for i, e := range candidates {
+		c.report(e, errs[i])
+		if errs[i] != nil {
+			continue
+		}
+		key := digest(results[i])
+		votes[key]++
+		if votes[key] >= c.config.Quorum {
+			return results[i], nil
+		}
+	}
+	return nil, ErrQuorumNotReached
+}
+
+func (c *Client) ChainID(ctx context.Context) (chainId *big.Int, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		chainId, err = cli.ChainID(ctx)
+		return err
+	})
+	return chainId, err
+}
+
+func (c *Client) GetHeight() (height uint64, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		height, err = cli.BlockNumber(ctx)
+		return err
+	})
+	return height, err
+}
+
+// GetPendingNonce returns the next nonce of the account including the txs
+// in the tx pool.
+func (c *Client) GetPendingNonce(account common.Address) (nonce uint64, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		nonce, err = cli.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+// GetConfirmedNonce returns the next nonce of the account at the latest block.
+func (c *Client) GetConfirmedNonce(account common.Address) (nonce uint64, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		nonce, err = cli.NonceAt(ctx, account, nil)
+		return err
+	})
+	return nonce, err
+}
+
+func (c *Client) GetLatestBlockHeader() (header *types.Header, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		header, err = cli.HeaderByNumber(ctx, nil)
+		return err
+	})
+	return header, err
+}
+
+// FeeHistory returns the base fees and the priority fees at the given
+// percentiles of the latest blockCount blocks.
+func (c *Client) FeeHistory(blockCount uint64, rewardPercentiles []float64) (feeHistory *ethereum.FeeHistory, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		feeHistory, err = cli.FeeHistory(ctx, blockCount, nil, rewardPercentiles)
+		return err
+	})
+	return feeHistory, err
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		gasPrice, err = cli.SuggestGasPrice(ctx)
+		return err
+	})
+	return gasPrice, err
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (gasTipCap *big.Int, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		gasTipCap, err = cli.SuggestGasTipCap(ctx)
+		return err
+	})
+	return gasTipCap, err
+}
+
+// CallContract executes the call against the latest l1 state.
+func (c *Client) CallContract(msg ethereum.CallMsg) (result []byte, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		result, err = cli.CallContract(ctx, msg, nil)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) EstimateGas(msg ethereum.CallMsg) (gas uint64, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		gas, err = cli.EstimateGas(ctx, msg)
+		return err
+	})
+	return gas, err
+}
+
+func (c *Client) GetTransactionReceipt(txHash string) (receipt *types.Receipt, err error) {
+	err = c.call(func(ctx context.Context, cli *rpc.ProviderClient) error {
+		receipt, err = cli.TransactionReceipt(ctx, common.HexToHash(txHash))
+		return err
+	})
+	return receipt, err
+}
+
+// GetBlockHeaderByNumber returns the header agreed by the configured quorum.
+func (c *Client) GetBlockHeaderByNumber(height *big.Int) (*types.Header, error) {
+	result, err := c.quorumCall(func(ctx context.Context, cli *rpc.ProviderClient) (interface{}, error) {
+		return cli.HeaderByNumber(ctx, height)
+	}, func(result interface{}) common.Hash {
+		return result.(*types.Header).Hash()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*types.Header), nil
+}
+
+// FilterLogs returns the logs agreed by the configured quorum.
+func (c *Client) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	result, err := c.quorumCall(func(ctx context.Context, cli *rpc.ProviderClient) (interface{}, error) {
+		return cli.FilterLogs(ctx, query)
+	}, func(result interface{}) common.Hash {
+		return LogsDigest(result.([]types.Log))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]types.Log), nil
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package l1client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubNode struct {
+	head      uint64
+	txHash    string
+	failing   bool
+	reverting bool
+	calls     int32
+}
+
+func (n *stubNode) serve(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if n.failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == "eth_estimateGas" || req.Method == "eth_call" {
+			atomic.AddInt32(&n.calls, 1)
+		}
+		if n.reverting && req.Method == "eth_estimateGas" {
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req.ID,
+				"error":   map[string]interface{}{"code": -32000, "message": "execution reverted"},
+			}))
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = fmt.Sprintf("0x%x", n.head)
+		case "eth_getLogs":
+			result = []map[string]interface{}{{
+				"address":          "0x0000000000000000000000000000000000000001",
+				"topics":           []string{"0x0000000000000000000000000000000000000000000000000000000000000002"},
+				"data":             "0x",
+				"blockNumber":      "0x1",
+				"transactionHash":  n.txHash,
+				"transactionIndex": "0x0",
+				"blockHash":        "0x0000000000000000000000000000000000000000000000000000000000000003",
+				"logIndex":         "0x0",
+				"removed":          false,
+			}}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		}))
+	}))
+}
+
+const (
+	goodTxHash = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	badTxHash  = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+)
+
+func TestClientFailover(t *testing.T) {
+	down := &stubNode{head: 100, failing: true}
+	up := &stubNode{head: 100}
+	downServer, upServer := down.serve(t), up.serve(t)
+	defer downServer.Close()
+	defer upServer.Close()
+
+	cli, err := NewClient([]string{downServer.URL, upServer.URL}, Config{MaxFailures: 1})
+	assert.NoError(t, err)
+
+	height, err := cli.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), height)
+	assert.Equal(t, cli.endpoints[1].cli, cli.Provider())
+}
+
+func TestClientSkipsLaggingEndpoint(t *testing.T) {
+	lagging := &stubNode{head: 10}
+	synced := &stubNode{head: 100}
+	laggingServer, syncedServer := lagging.serve(t), synced.serve(t)
+	defer laggingServer.Close()
+	defer syncedServer.Close()
+
+	cli, err := NewClient([]string{laggingServer.URL, syncedServer.URL}, Config{MaxLagBlocks: 5})
+	assert.NoError(t, err)
+
+	height, err := cli.GetHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), height)
+}
+
+func TestClientQuorumFilterLogs(t *testing.T) {
+	nodes := []*stubNode{
+		{head: 100, txHash: badTxHash},
+		{head: 100, txHash: goodTxHash},
+		{head: 100, txHash: goodTxHash},
+	}
+	var urls []string
+	for _, node := range nodes {
+		server := node.serve(t)
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	cli, err := NewClient(urls, Config{Quorum: 2})
+	assert.NoError(t, err)
+
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: big.NewInt(1)}
+	logs, err := cli.FilterLogs(query)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, goodTxHash, logs[0].TxHash.Hex())
+
+	nodes[2].txHash = badTxHash
+	logs, err = cli.FilterLogs(query)
+	assert.NoError(t, err)
+	assert.Equal(t, badTxHash, logs[0].TxHash.Hex())
+
+	nodes[1].failing = true
+	nodes[2].txHash = goodTxHash
+	_, err = cli.FilterLogs(query)
+	assert.Equal(t, ErrQuorumNotReached, err)
+}
+
+func TestClientRevertIsValidAnswer(t *testing.T) {
+	nodes := []*stubNode{{head: 100, reverting: true}, {head: 100, reverting: true}}
+	var urls []string
+	for _, node := range nodes {
+		server := node.serve(t)
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	cli, err := NewClient(urls, Config{MaxFailures: 1})
+	assert.NoError(t, err)
+
+	// a revert without data is the answer of the preferred endpoint, it is
+	// neither retried on the others nor counted as a failure
+	_, err = cli.EstimateGas(ethereum.CallMsg{})
+	assert.Error(t, err)
+	assert.True(t, isValidAnswer(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&nodes[0].calls)+atomic.LoadInt32(&nodes[1].calls))
+	for _, e := range cli.endpoints {
+		assert.Equal(t, 0, e.failures)
+	}
+	assert.Equal(t, 2, len(cli.candidates()))
+
+	assert.False(t, isValidAnswer(fmt.Errorf("dial tcp: connection refused")))
+}
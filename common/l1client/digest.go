@@ -0,0 +1,54 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package l1client
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// LogsDigest hashes the fields of the logs which identify them on chain, two
+// endpoints agree on a log query if and only if their digests are equal.
+func LogsDigest(logs []types.Log) common.Hash {
+	hasher := crypto.NewKeccakState()
+	buf := make([]byte, 8)
+	for _, log := range logs {
+		binary.BigEndian.PutUint64(buf, log.BlockNumber)
+		hasher.Write(buf)
+		hasher.Write(log.BlockHash.Bytes())
+		hasher.Write(log.TxHash.Bytes())
+		binary.BigEndian.PutUint64(buf, uint64(log.Index))
+		hasher.Write(buf)
+		hasher.Write(log.Address.Bytes())
+		for _, topic := range log.Topics {
+			hasher.Write(topic.Bytes())
+		}
+		hasher.Write(crypto.Keccak256(log.Data))
+		if log.Removed {
+			hasher.Write([]byte{1})
+		} else {
+			hasher.Write([]byte{0})
+		}
+	}
+	var digest common.Hash
+	hasher.Read(digest[:]) //nolint:errcheck
+	return digest
+}
@@ -0,0 +1,61 @@
+package prove
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+)
+
+const rootSize = 32
+
+// MockProof checks that the block witness can be assigned to the block circuit
+// and returns a proof made of the curve generators with the real public inputs
+// of the block. It is only accepted by a verifier contract which skips the
+// pairing check, and is meant for development networks and tests where
+// loading the proving keys is impractical.
+func MockProof(cBlock *circuit.Block, blockSizes []int) (proof *FormattedProof, err error) {
+	if err = checkBlockWitnessShape(cBlock, blockSizes); err != nil {
+		return nil, err
+	}
+	if _, err = circuit.SetBlockWitness(cBlock); err != nil {
+		return nil, fmt.Errorf("invalid block witness, err: %v", err)
+	}
+
+	_, _, g1, g2 := bn254.Generators()
+	proof = new(FormattedProof)
+	proof.A = [2]*big.Int{g1.X.ToBigIntRegular(new(big.Int)), g1.Y.ToBigIntRegular(new(big.Int))}
+	// same order as the raw encoding of G2 points used by FormatProof
+	proof.B = [2][2]*big.Int{
+		{g2.X.A1.ToBigIntRegular(new(big.Int)), g2.X.A0.ToBigIntRegular(new(big.Int))},
+		{g2.Y.A1.ToBigIntRegular(new(big.Int)), g2.Y.A0.ToBigIntRegular(new(big.Int))},
+	}
+	proof.C = [2]*big.Int{g1.X.ToBigIntRegular(new(big.Int)), g1.Y.ToBigIntRegular(new(big.Int))}
+	proof.Inputs = publicInputs(cBlock.OldStateRoot, cBlock.NewStateRoot, cBlock.BlockCommitment)
+	return proof, nil
+}
+
+func checkBlockWitnessShape(cBlock *circuit.Block, blockSizes []int) error {
+	if len(cBlock.OldStateRoot) != rootSize || len(cBlock.NewStateRoot) != rootSize {
+		return fmt.Errorf("invalid state roots of block %d", cBlock.BlockNumber)
+	}
+	if len(cBlock.BlockCommitment) != rootSize {
+		return fmt.Errorf("invalid commitment of block %d", cBlock.BlockNumber)
+	}
+	if cBlock.Gas == nil {
+		return fmt.Errorf("missing gas witness of block %d", cBlock.BlockNumber)
+	}
+	for _, tx := range cBlock.Txs {
+		if tx == nil {
+			return fmt.Errorf("missing tx witness of block %d", cBlock.BlockNumber)
+		}
+	}
+	for _, blockSize := range blockSizes {
+		if len(cBlock.Txs) == blockSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("block %d has %d txs which is not an optional block size", cBlock.BlockNumber, len(cBlock.Txs))
+}
@@ -0,0 +1,31 @@
+package prove
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+)
+
+func TestCheckBlockWitnessShape(t *testing.T) {
+	root := bytes.Repeat([]byte{1}, rootSize)
+	cBlock := &circuit.Block{
+		BlockNumber:     1,
+		OldStateRoot:    root,
+		NewStateRoot:    root,
+		BlockCommitment: root,
+		Txs:             []*circuit.Tx{{}},
+		Gas:             &circuit.Gas{},
+	}
+	assert.NoError(t, checkBlockWitnessShape(cBlock, []int{1, 10}))
+	assert.Error(t, checkBlockWitnessShape(cBlock, []int{10}))
+
+	cBlock.Txs = []*circuit.Tx{nil}
+	assert.Error(t, checkBlockWitnessShape(cBlock, []int{1, 10}))
+
+	cBlock.Txs = []*circuit.Tx{{}}
+	cBlock.BlockCommitment = root[1:]
+	assert.Error(t, checkBlockWitnessShape(cBlock, []int{1, 10}))
+}
@@ -2,6 +2,7 @@ package prove
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -10,10 +11,12 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"github.com/bnb-chain/zkbnb-crypto/circuit"
 	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	zkbnbTypes "github.com/bnb-chain/zkbnb/types"
 )
 
 func LoadProvingKey(filepath string) (pk groth16.ProvingKey, err error) {
@@ -41,39 +44,85 @@ func LoadVerifyingKey(filepath string) (verifyingKey groth16.VerifyingKey, err e
 	return verifyingKey, nil
 }
 
+// CompileBlockCircuit compiles the circuit of blocks with blockSize txs.
+func CompileBlockCircuit(blockSize int) (frontend.CompiledConstraintSystem, error) {
+	var blockConstraints circuit.BlockConstraints
+	blockConstraints.TxsCount = blockSize
+	blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
+	for i := 0; i < blockConstraints.TxsCount; i++ {
+		blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
+	}
+	blockConstraints.GasAssetIds = zkbnbTypes.GasAssets[:]
+	blockConstraints.GasAccountIndex = zkbnbTypes.GasAccount
+	blockConstraints.Gas = circuit.GetZeroGasConstraints(zkbnbTypes.GasAssets[:])
+
+	logx.Infof("start compile block size %d blockConstraints", blockConstraints.TxsCount)
+	cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		return nil, err
+	}
+	logx.Infof("blockConstraints constraints: %d", cs.GetNbConstraints())
+	logx.Info("finish compile blockConstraints")
+	return cs, nil
+}
+
 func GenerateProof(
 	r1cs frontend.CompiledConstraintSystem,
 	provingKey groth16.ProvingKey,
-	verifyingKey groth16.VerifyingKey,
 	cBlock *circuit.Block,
 ) (proof groth16.Proof, err error) {
-	// verify CryptoBlock
 	blockWitness, err := circuit.SetBlockWitness(cBlock)
 	if err != nil {
 		return proof, err
 	}
-	var verifyWitness circuit.BlockConstraints
-	verifyWitness.OldStateRoot = cBlock.OldStateRoot
-	verifyWitness.NewStateRoot = cBlock.NewStateRoot
-	verifyWitness.BlockCommitment = cBlock.BlockCommitment
 	witness, err := frontend.NewWitness(&blockWitness, ecc.BN254)
 	if err != nil {
 		return proof, err
 	}
-	vWitness, err := frontend.NewWitness(&verifyWitness, ecc.BN254, frontend.PublicOnly())
-	if err != nil {
-		return proof, err
-	}
 	proof, err = groth16.Prove(r1cs, provingKey, witness, backend.WithHints(types.Keccak256))
 	if err != nil {
 		return proof, err
 	}
-	err = groth16.Verify(proof, verifyingKey, vWitness)
+
+	return proof, nil
+}
+
+// VerifyProof verifies the formatted proof exactly as it is submitted to the
+// verifier contract, so that both a bad proof and a bad formatting are caught.
+func VerifyProof(fProof *FormattedProof, verifyingKey groth16.VerifyingKey) error {
+	var verifyWitness circuit.BlockConstraints
+	verifyWitness.OldStateRoot = fProof.Inputs[0]
+	verifyWitness.NewStateRoot = fProof.Inputs[1]
+	verifyWitness.BlockCommitment = fProof.Inputs[2]
+	return verifyProof(fProof, verifyingKey, &verifyWitness)
+}
+
+// verifyProof verifies the formatted proof of the circuit whose public
+// witness is assigned the inputs of the proof.
+func verifyProof(fProof *FormattedProof, verifyingKey groth16.VerifyingKey, publicWitness frontend.Circuit) error {
+	const fpSize = 4 * 8
+	proofBytes := make([]byte, 0, fpSize*8)
+	for _, e := range []*big.Int{
+		fProof.A[0], fProof.A[1],
+		fProof.B[0][0], fProof.B[0][1], fProof.B[1][0], fProof.B[1][1],
+		fProof.C[0], fProof.C[1],
+	} {
+		if e == nil || e.Sign() < 0 || e.BitLen() > fpSize*8 {
+			return errors.New("invalid proof encoding")
+		}
+		proofBytes = append(proofBytes, e.FillBytes(make([]byte, fpSize))...)
+	}
+	proof := groth16.NewProof(ecc.BN254)
+	_, err := proof.ReadFrom(bytes.NewReader(proofBytes))
 	if err != nil {
-		return proof, err
+		return fmt.Errorf("invalid proof encoding, err: %v", err)
 	}
 
-	return proof, nil
+	vWitness, err := frontend.NewWitness(publicWitness, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(proof, verifyingKey, vWitness)
 }
 
 type FormattedProof struct {
@@ -102,9 +151,16 @@ func FormatProof(oProof groth16.Proof, oldRoot, newRoot, commitment []byte) (pro
 	proof.C[0] = new(big.Int).SetBytes(proofBytes[fpSize*6 : fpSize*7])
 	proof.C[1] = new(big.Int).SetBytes(proofBytes[fpSize*7 : fpSize*8])
 
-	// public witness
-	proof.Inputs[0] = new(big.Int).SetBytes(oldRoot)
-	proof.Inputs[1] = new(big.Int).SetBytes(newRoot)
-	proof.Inputs[2] = new(big.Int).SetBytes(commitment)
+	proof.Inputs = publicInputs(oldRoot, newRoot, commitment)
 	return proof, nil
 }
+
+// publicInputs is the public witness of a block in the order the verifier
+// contract expects it.
+func publicInputs(oldRoot, newRoot, commitment []byte) [3]*big.Int {
+	return [3]*big.Int{
+		new(big.Int).SetBytes(oldRoot),
+		new(big.Int).SetBytes(newRoot),
+		new(big.Int).SetBytes(commitment),
+	}
+}
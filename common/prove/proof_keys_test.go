@@ -0,0 +1,69 @@
+package prove
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rootsCircuit has the public inputs of the block circuit, the square of the
+// secret is their sum.
+type rootsCircuit struct {
+	OldStateRoot    frontend.Variable `gnark:",public"`
+	NewStateRoot    frontend.Variable `gnark:",public"`
+	BlockCommitment frontend.Variable `gnark:",public"`
+	Secret          frontend.Variable
+}
+
+func (c *rootsCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.Secret, c.Secret), api.Add(c.OldStateRoot, c.NewStateRoot, c.BlockCommitment))
+	return nil
+}
+
+func TestVerifyFormattedProof(t *testing.T) {
+	cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &rootsCircuit{})
+	require.NoError(t, err)
+	provingKey, verifyingKey, err := groth16.Setup(cs)
+	require.NoError(t, err)
+
+	witness, err := frontend.NewWitness(&rootsCircuit{
+		OldStateRoot:    1,
+		NewStateRoot:    2,
+		BlockCommitment: 6,
+		Secret:          3,
+	}, ecc.BN254)
+	require.NoError(t, err)
+	proof, err := groth16.Prove(cs, provingKey, witness)
+	require.NoError(t, err)
+
+	fProof, err := FormatProof(proof, big.NewInt(1).Bytes(), big.NewInt(2).Bytes(), big.NewInt(6).Bytes())
+	require.NoError(t, err)
+	publicWitness := func(fProof *FormattedProof) frontend.Circuit {
+		return &rootsCircuit{
+			OldStateRoot:    fProof.Inputs[0],
+			NewStateRoot:    fProof.Inputs[1],
+			BlockCommitment: fProof.Inputs[2],
+		}
+	}
+	assert.NoError(t, verifyProof(fProof, verifyingKey, publicWitness(fProof)))
+
+	// a tampered public input
+	tampered := *fProof
+	tampered.Inputs[2] = big.NewInt(7)
+	assert.Error(t, verifyProof(&tampered, verifyingKey, publicWitness(&tampered)))
+
+	// a tampered proof
+	tampered = *fProof
+	tampered.C[0] = new(big.Int).Add(fProof.C[0], big.NewInt(1))
+	assert.Error(t, verifyProof(&tampered, verifyingKey, publicWitness(&tampered)))
+
+	tampered = *fProof
+	tampered.A[1] = nil
+	assert.Error(t, verifyProof(&tampered, verifyingKey, publicWitness(&tampered)))
+}
@@ -0,0 +1,82 @@
+package prove
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	zkbnbTypes "github.com/bnb-chain/zkbnb/types"
+)
+
+// txCheckConstraints verifies a single tx of a block, so that an unsatisfied
+// block can be narrowed down to its first invalid tx.
+type txCheckConstraints struct {
+	CreatedAt frontend.Variable
+	Tx        circuit.TxConstraints
+}
+
+func (c txCheckConstraints) Define(api frontend.API) error {
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	var roots [types.NbRoots]frontend.Variable
+	for i := range roots {
+		roots[i] = 0
+	}
+	_, _, _, _, err = circuit.VerifyTransaction(api, c.Tx, hFunc, c.CreatedAt, zkbnbTypes.GasAssets[:], roots)
+	return err
+}
+
+// CheckBlockWitness runs the constraint solver of the block circuit on the
+// witness without proving it.
+func CheckBlockWitness(cs frontend.CompiledConstraintSystem, cBlock *circuit.Block) error {
+	blockWitness, err := circuit.SetBlockWitness(cBlock)
+	if err != nil {
+		return fmt.Errorf("invalid block witness, err: %v", err)
+	}
+	witness, err := frontend.NewWitness(&blockWitness, ecc.BN254)
+	if err != nil {
+		return err
+	}
+	return cs.IsSolved(witness, backend.WithHints(types.Keccak256))
+}
+
+// FindUnsatisfiedTx checks the state root chaining and the constraints of
+// every tx of the block and returns the index of the first invalid tx, or -1
+// if all txs are valid on their own.
+func FindUnsatisfiedTx(cBlock *circuit.Block) (index int, err error) {
+	txTemplate := txCheckConstraints{Tx: circuit.GetZeroTxConstraint()}
+	cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &txTemplate, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		return -1, err
+	}
+
+	stateRoot := cBlock.OldStateRoot
+	for i, tx := range cBlock.Txs {
+		if !bytes.Equal(stateRoot, tx.StateRootBefore) {
+			return i, fmt.Errorf("state root before does not match the state root after the previous tx")
+		}
+		stateRoot = tx.StateRootAfter
+
+		txWitness, err := circuit.SetTxWitness(tx)
+		if err != nil {
+			return i, fmt.Errorf("invalid tx witness, err: %v", err)
+		}
+		witness, err := frontend.NewWitness(&txCheckConstraints{CreatedAt: cBlock.CreatedAt, Tx: txWitness}, ecc.BN254)
+		if err != nil {
+			return i, err
+		}
+		if err = cs.IsSolved(witness, backend.WithHints(types.Keccak256)); err != nil {
+			return i, err
+		}
+	}
+	return -1, nil
+}
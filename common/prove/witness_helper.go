@@ -18,6 +18,7 @@
 package prove
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -62,6 +63,58 @@ func NewWitnessHelper(treeCtx *tree.Context, accountTree, nftTree bsmt.SparseMer
 	}
 }
 
+// ConstructBlockWitness executes the txs of the block on the trees and returns
+// the witness of the block.
+func (w *WitnessHelper) ConstructBlockWitness(block *block.Block, finalityBlockNr uint64) (*circuit.Block, error) {
+	var oldStateRoot, newStateRoot []byte
+	txsWitness := make([]*TxWitness, 0, block.BlockSize)
+	// scan each transaction
+	err := w.ResetCache(block.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	for idx, tx := range block.Txs {
+		txWitness, err := w.ConstructTxWitness(tx, finalityBlockNr)
+		if err != nil {
+			return nil, err
+		}
+		txsWitness = append(txsWitness, txWitness)
+		// if it is the first tx of the block
+		if idx == 0 {
+			oldStateRoot = txWitness.StateRootBefore
+		}
+		// if it is the last tx of the block
+		if idx == len(block.Txs)-1 {
+			newStateRoot = txWitness.StateRootAfter
+		}
+	}
+
+	emptyTxCount := int(block.BlockSize) - len(block.Txs)
+	for i := 0; i < emptyTxCount; i++ {
+		txsWitness = append(txsWitness, circuit.EmptyTx(newStateRoot))
+	}
+
+	gasWitness, err := w.ConstructGasWitness(block)
+	if err != nil {
+		return nil, err
+	}
+
+	newStateRoot = tree.ComputeStateRootHash(w.accountTree.Root(), w.nftTree.Root())
+	if common.Bytes2Hex(newStateRoot) != block.StateRoot {
+		return nil, errors.New("state root doesn't match")
+	}
+
+	return &circuit.Block{
+		BlockNumber:     block.BlockHeight,
+		CreatedAt:       block.CreatedAt.UnixMilli(),
+		OldStateRoot:    oldStateRoot,
+		NewStateRoot:    newStateRoot,
+		BlockCommitment: common.FromHex(block.BlockCommitment),
+		Txs:             txsWitness,
+		Gas:             gasWitness,
+	}, nil
+}
+
 func (w *WitnessHelper) ConstructTxWitness(oTx *tx.Tx, finalityBlockNr uint64,
 ) (cryptoTx *TxWitness, err error) {
 	switch oTx.TxType {
@@ -30,6 +30,21 @@ const (
 	MaxRetryTimes  = 3
 )
 
+// Lock is implemented by redis.RedisLock and MemoryLock.
+type Lock interface {
+	Acquire() (bool, error)
+	Release() (bool, error)
+}
+
+// GetLockByKey returns a redis lock, or an in-process lock if there is no
+// redis connection.
+func GetLockByKey(conn *redis.Redis, keyLock string) Lock {
+	if conn == nil {
+		return NewMemoryLock(keyLock)
+	}
+	return GetRedisLockByKey(conn, keyLock)
+}
+
 func GetRedisLockByKey(conn *redis.Redis, keyLock string) (redisLock *redis.RedisLock) {
 	// get lock
 	redisLock = redis.NewRedisLock(conn, keyLock)
@@ -38,7 +53,7 @@ func GetRedisLockByKey(conn *redis.Redis, keyLock string) (redisLock *redis.Redi
 	return redisLock
 }
 
-func TryAcquireLock(redisLock *redis.RedisLock) (err error) {
+func TryAcquireLock(redisLock Lock) (err error) {
 	// lock
 	ok, err := redisLock.Acquire()
 	if err != nil {
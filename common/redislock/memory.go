@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package redislock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stringx"
+)
+
+var (
+	memoryLocksMu sync.Mutex
+	memoryLocks   = make(map[string]*memoryLockHolder)
+)
+
+type memoryLockHolder struct {
+	id       string
+	expireAt time.Time
+}
+
+// MemoryLock is the in-process version of the redis lock, it only excludes
+// the routines of the same process and expires like the redis one.
+type MemoryLock struct {
+	key string
+	id  string
+}
+
+func NewMemoryLock(key string) *MemoryLock {
+	return &MemoryLock{
+		key: key,
+		id:  stringx.Randn(8),
+	}
+}
+
+func (l *MemoryLock) Acquire() (bool, error) {
+	memoryLocksMu.Lock()
+	defer memoryLocksMu.Unlock()
+
+	now := time.Now()
+	if holder, ok := memoryLocks[l.key]; ok && holder.id != l.id && now.Before(holder.expireAt) {
+		return false, nil
+	}
+	memoryLocks[l.key] = &memoryLockHolder{
+		id:       l.id,
+		expireAt: now.Add(LockExpiryTime * time.Second),
+	}
+	return true, nil
+}
+
+func (l *MemoryLock) Release() (bool, error) {
+	memoryLocksMu.Lock()
+	defer memoryLocksMu.Unlock()
+
+	holder, ok := memoryLocks[l.key]
+	if !ok || holder.id != l.id {
+		return false, nil
+	}
+	delete(memoryLocks, l.key)
+	return true, nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package redislock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLock(t *testing.T) {
+	first := GetLockByKey(nil, "test_memory_lock")
+	second := GetLockByKey(nil, "test_memory_lock")
+
+	require.NoError(t, TryAcquireLock(first))
+	ok, err := second.Acquire()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// only the holder can release the lock
+	ok, err = second.Release()
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = first.Release()
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, TryAcquireLock(second))
+	_, _ = second.Release()
+}
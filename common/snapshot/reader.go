@@ -0,0 +1,173 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+)
+
+// Reader reads a snapshot and verifies the checksum of every chunk, the
+// digest and the counts are verified when the footer is read.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	footer *Footer
+	digest hash.Hash
+
+	chunks   int
+	accounts int64
+	nfts     int64
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	sr := &Reader{r: bufio.NewReader(r), digest: sha256.New()}
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(sr.r, magic); err != nil || string(magic) != Magic {
+		return nil, ErrInvalidMagic
+	}
+	kind, payload, err := sr.readChunk()
+	if err != nil {
+		return nil, err
+	}
+	if kind != ChunkHeader {
+		return nil, ErrCorrupted
+	}
+	sr.header = &Header{}
+	if err = json.Unmarshal(payload, sr.header); err != nil {
+		return nil, ErrCorrupted
+	}
+	if sr.header.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, sr.header.Version)
+	}
+	if sr.header.Block == nil || sr.header.Block.BlockHeight != sr.header.Height {
+		return nil, ErrCorrupted
+	}
+	return sr, nil
+}
+
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Footer is only set once Next returned io.EOF.
+func (r *Reader) Footer() *Footer {
+	return r.footer
+}
+
+// Next returns the next chunk of accounts or nfts, or io.EOF after the
+// footer is read and verified.
+func (r *Reader) Next() (*Chunk, error) {
+	if r.footer != nil {
+		return nil, io.EOF
+	}
+	kind, payload, err := r.readChunk()
+	if err != nil {
+		return nil, err
+	}
+	chunk := &Chunk{Kind: kind}
+	switch kind {
+	case ChunkAccounts:
+		err = json.Unmarshal(payload, &chunk.Accounts)
+		r.accounts += int64(len(chunk.Accounts))
+	case ChunkNfts:
+		err = json.Unmarshal(payload, &chunk.Nfts)
+		r.nfts += int64(len(chunk.Nfts))
+	case ChunkFooter:
+		footer := &Footer{}
+		if err = json.Unmarshal(payload, footer); err != nil {
+			return nil, ErrCorrupted
+		}
+		if footer.Chunks != r.chunks || footer.Accounts != r.accounts || footer.Nfts != r.nfts ||
+			footer.Digest != hex.EncodeToString(r.digest.Sum(nil)) {
+			return nil, ErrCorrupted
+		}
+		r.footer = footer
+		return nil, io.EOF
+	default:
+		return nil, ErrCorrupted
+	}
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	return chunk, nil
+}
+
+func (r *Reader) readChunk() (ChunkKind, []byte, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r.r, prefix); err != nil {
+		// the footer is missing
+		return 0, nil, ErrCorrupted
+	}
+	kind := ChunkKind(prefix[0])
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > maxChunkLength {
+		return 0, nil, ErrCorrupted
+	}
+	payload := make([]byte, length)
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		return 0, nil, ErrCorrupted
+	}
+	if _, err := io.ReadFull(r.r, checksum); err != nil {
+		return 0, nil, ErrCorrupted
+	}
+	actual := sha256.Sum256(payload)
+	if !bytes.Equal(actual[:], checksum) {
+		return 0, nil, ErrChecksumMismatch
+	}
+	if kind != ChunkFooter {
+		r.digest.Write(checksum)
+		r.chunks++
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, ErrCorrupted
+	}
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return 0, nil, ErrCorrupted
+	}
+	return kind, data, nil
+}
+
+// Verify reads the whole snapshot and returns its header and footer.
+func Verify(r io.Reader) (*Header, *Footer, error) {
+	sr, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		_, err = sr.Next()
+		if err == io.EOF {
+			return sr.Header(), sr.Footer(), nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package snapshot reads and writes portable snapshots of the l2 state.
+//
+// A snapshot file starts with a magic and is followed by chunks:
+//
+//	kind (1 byte) | length (4 bytes) | payload | sha256 of the payload (32 bytes)
+//
+// The payload is gzip compressed json. The first chunk is the header, then
+// the account and nft chunks follow, the last chunk is the footer with the
+// counts, the tree roots and the digest of all chunk checksums.
+package snapshot
+
+import (
+	"errors"
+)
+
+const (
+	Magic   = "ZKBNBSNP"
+	Version = 1
+
+	// DefaultChunkSize is the number of accounts or nfts per chunk.
+	DefaultChunkSize = 1000
+	// maxChunkLength guards against allocating a corrupted length.
+	maxChunkLength = 256 * 1024 * 1024
+)
+
+type ChunkKind uint8
+
+const (
+	ChunkHeader ChunkKind = iota + 1
+	ChunkAccounts
+	ChunkNfts
+	ChunkFooter
+)
+
+var (
+	ErrInvalidMagic       = errors.New("not a snapshot file")
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+	ErrChecksumMismatch   = errors.New("snapshot chunk checksum mismatch")
+	ErrCorrupted          = errors.New("snapshot file is corrupted")
+)
+
+type Header struct {
+	Version uint32
+	Height  int64
+	Block   *Block
+}
+
+// Block is the block at the snapshot height, without its txs.
+type Block struct {
+	BlockSize                       uint16
+	BlockCommitment                 string
+	BlockHeight                     int64
+	StateRoot                       string
+	PriorityOperations              int64
+	PendingOnChainOperationsHash    string
+	PendingOnChainOperationsPubData string
+	CommittedTxHash                 string
+	CommittedAt                     int64
+	VerifiedTxHash                  string
+	VerifiedAt                      int64
+	CreatedAt                       int64
+}
+
+type Account struct {
+	AccountIndex    int64
+	AccountName     string
+	PublicKey       string
+	AccountNameHash string
+	L1Address       string
+	Nonce           int64
+	CollectionNonce int64
+	// map[int64]*AccountAsset
+	AssetInfo string
+	AssetRoot string
+}
+
+type Nft struct {
+	NftIndex            int64
+	CreatorAccountIndex int64
+	OwnerAccountIndex   int64
+	NftContentHash      string
+	NftL1Address        string
+	NftL1TokenId        string
+	CreatorTreasuryRate int64
+	CollectionId        int64
+}
+
+type Footer struct {
+	Chunks      int
+	Accounts    int64
+	Nfts        int64
+	AccountRoot string
+	NftRoot     string
+	// hex sha256 over the checksums of the header, account and nft chunks
+	Digest string
+}
+
+// Chunk holds the records of an account or nft chunk.
+type Chunk struct {
+	Kind     ChunkKind
+	Accounts []*Account
+	Nfts     []*Nft
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestSnapshot(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, &Header{Height: 10, Block: &Block{BlockHeight: 10, StateRoot: "01"}})
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteAccounts([]*Account{{AccountIndex: 0, AssetInfo: "{}"}, {AccountIndex: 1, AssetInfo: "{}"}}))
+	assert.NoError(t, w.WriteAccounts([]*Account{{AccountIndex: 2, AssetInfo: "{}"}}))
+	assert.NoError(t, w.WriteNfts([]*Nft{{NftIndex: 0, OwnerAccountIndex: 2}}))
+	assert.NoError(t, w.Close("02", "03"))
+	return buf.Bytes()
+}
+
+func TestReadWrite(t *testing.T) {
+	data := writeTestSnapshot(t)
+
+	r, err := NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), r.Header().Height)
+	assert.Equal(t, uint32(Version), r.Header().Version)
+
+	var accounts, nfts int
+	for {
+		chunk, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		accounts += len(chunk.Accounts)
+		nfts += len(chunk.Nfts)
+	}
+	assert.Equal(t, 3, accounts)
+	assert.Equal(t, 1, nfts)
+	assert.Equal(t, 4, r.Footer().Chunks)
+	assert.Equal(t, "02", r.Footer().AccountRoot)
+	assert.Equal(t, "03", r.Footer().NftRoot)
+}
+
+func TestVerifyCorrupted(t *testing.T) {
+	data := writeTestSnapshot(t)
+	_, _, err := Verify(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	_, _, err = Verify(bytes.NewReader([]byte("something else")))
+	assert.Equal(t, ErrInvalidMagic, err)
+
+	// flip a byte of the first account chunk
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	_, _, err = Verify(bytes.NewReader(corrupted))
+	assert.Error(t, err)
+
+	// drop the footer
+	_, _, err = Verify(bytes.NewReader(data[:len(data)-40]))
+	assert.Equal(t, ErrCorrupted, err)
+}
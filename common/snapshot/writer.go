@@ -0,0 +1,107 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+)
+
+// Writer writes a snapshot, Close must be called to write the footer.
+type Writer struct {
+	w      io.Writer
+	digest hash.Hash
+	footer Footer
+}
+
+func NewWriter(w io.Writer, header *Header) (*Writer, error) {
+	if _, err := io.WriteString(w, Magic); err != nil {
+		return nil, err
+	}
+	sw := &Writer{w: w, digest: sha256.New()}
+	header.Version = Version
+	if err := sw.writeChunk(ChunkHeader, header); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (w *Writer) WriteAccounts(accounts []*Account) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	w.footer.Accounts += int64(len(accounts))
+	return w.writeChunk(ChunkAccounts, accounts)
+}
+
+func (w *Writer) WriteNfts(nfts []*Nft) error {
+	if len(nfts) == 0 {
+		return nil
+	}
+	w.footer.Nfts += int64(len(nfts))
+	return w.writeChunk(ChunkNfts, nfts)
+}
+
+// Close writes the footer with the tree roots, the underlying writer is not
+// closed.
+func (w *Writer) Close(accountRoot, nftRoot string) error {
+	w.footer.AccountRoot = accountRoot
+	w.footer.NftRoot = nftRoot
+	w.footer.Digest = hex.EncodeToString(w.digest.Sum(nil))
+	return w.writeChunk(ChunkFooter, &w.footer)
+}
+
+func (w *Writer) writeChunk(kind ChunkKind, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var payload bytes.Buffer
+	zw := gzip.NewWriter(&payload)
+	if _, err = zw.Write(data); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	prefix := make([]byte, 5)
+	prefix[0] = byte(kind)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(payload.Len()))
+	checksum := sha256.Sum256(payload.Bytes())
+	for _, b := range [][]byte{prefix, payload.Bytes(), checksum[:]} {
+		if _, err = w.w.Write(b); err != nil {
+			return err
+		}
+	}
+	if kind != ChunkFooter {
+		w.digest.Write(checksum[:])
+		w.footer.Chunks++
+	}
+	return nil
+}
+
+// Footer is complete once Close is called.
+func (w *Writer) Footer() *Footer {
+	return &w.footer
+}
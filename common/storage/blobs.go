@@ -0,0 +1,79 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+)
+
+// Blobs stores gzip compressed data in a Storage and verifies the content
+// against its hash when reading it.
+type Blobs struct {
+	storage Storage
+}
+
+func NewBlobs(s Storage) *Blobs {
+	return &Blobs{storage: s}
+}
+
+// ContentHash is the hex sha256 of the uncompressed data.
+func ContentHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (b *Blobs) Put(key string, data []byte) (hash string, err error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err != nil {
+		return "", err
+	}
+	if err = zw.Close(); err != nil {
+		return "", err
+	}
+	if err = b.storage.Put(key, buf.Bytes()); err != nil {
+		return "", err
+	}
+	return ContentHash(data), nil
+}
+
+func (b *Blobs) Get(key string, hash string) ([]byte, error) {
+	compressed, err := b.storage.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	if ContentHash(data) != hash {
+		return nil, ErrHashMismatch
+	}
+	return data, nil
+}
+
+func (b *Blobs) Delete(key string) error {
+	return b.storage.Delete(key)
+}
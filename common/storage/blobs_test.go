@@ -0,0 +1,54 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBlobs(t *testing.T) {
+	blobs, err := New(Config{Type: Local, Dir: t.TempDir()})
+	assert.NoError(t, err)
+
+	data := []byte(`{"BlockNumber":1}`)
+	hash, err := blobs.Put("witness/1", data)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentHash(data), hash)
+
+	stored, err := blobs.Get("witness/1", hash)
+	assert.NoError(t, err)
+	assert.Equal(t, data, stored)
+
+	_, err = blobs.Get("witness/1", ContentHash([]byte("other")))
+	assert.Equal(t, ErrHashMismatch, err)
+
+	assert.NoError(t, blobs.Delete("witness/1"))
+	_, err = blobs.Get("witness/1", hash)
+	assert.Equal(t, ErrNotFound, err)
+	assert.NoError(t, blobs.Delete("witness/1"))
+}
+
+func TestDatabaseStorage(t *testing.T) {
+	blobs, err := New(Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, blobs)
+
+	_, err = New(Config{Type: "s3"})
+	assert.ErrorIs(t, err, ErrUnsupportedStorage)
+}
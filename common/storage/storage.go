@@ -0,0 +1,75 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Type string
+
+const (
+	// Database keeps the data in the database tables.
+	Database Type = "database"
+	// Local keeps the data in a local directory, which may be a mounted
+	// network or object store file system.
+	Local Type = "local"
+)
+
+var (
+	ErrNotFound           = errors.New("blob not found")
+	ErrHashMismatch       = errors.New("blob content does not match its hash")
+	ErrUnsupportedStorage = errors.New("unsupported storage type")
+)
+
+// Storage stores blobs by key.
+type Storage interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+type Config struct {
+	//nolint:staticcheck
+	Type Type `json:",optional"`
+	// Dir of the local storage.
+	//nolint:staticcheck
+	Dir string `json:",optional"`
+	// Data of blocks verified on l1 is deleted once RetainBlocks more blocks
+	// are verified, only the pointer and the hash are kept.
+	//nolint:staticcheck
+	Prune bool `json:",optional"`
+	//nolint:staticcheck
+	RetainBlocks int64 `json:",optional"`
+}
+
+// New returns the blob store of the config, or nil if the data is kept in the
+// database.
+func New(c Config) (*Blobs, error) {
+	switch c.Type {
+	case Database, "":
+		return nil, nil
+	case Local:
+		s, err := NewLocalStorage(c.Dir)
+		if err != nil {
+			return nil, err
+		}
+		return NewBlobs(s), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedStorage, c.Type)
+}
@@ -49,7 +49,13 @@ type ChainConfig struct {
 	Postgres struct {
 		DataSource string
 	}
-	CacheRedis cache.CacheConf
+	// CacheDriver is redis by default, memory keeps the cache in process so that
+	// no redis is needed when all services run on one machine. Twotier keeps
+	// the recently used values of redis in process as well.
+	//nolint:staticcheck
+	CacheDriver dbcache.Driver `json:",optional"`
+	//nolint:staticcheck
+	CacheRedis cache.CacheConf `json:",optional"`
 	//nolint:staticcheck
 	CacheConfig statedb.CacheConfig `json:",optional"`
 	TreeDB      struct {
@@ -61,12 +67,16 @@ type ChainConfig struct {
 		//nolint:staticcheck
 		RoutinePoolSize    int `json:",optional"`
 		AssetTreeCacheSize int
+		//nolint:staticcheck
+		Prune tree.PruneOption `json:",optional"`
 	}
 }
 
 type BlockChain struct {
 	*sdb.ChainDB
 	Statedb *sdb.StateDB // Cache for current block changes.
+	// TreePruner must be stopped before the state db is closed.
+	TreePruner *tree.Pruner
 
 	chainConfig *ChainConfig
 	dryRun      bool //dryRun mode is used for verifying user inputs, is not for execution
@@ -99,7 +109,10 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 	if bc.currentBlock.BlockStatus == block.StatusProposing {
 		curHeight--
 	}
-	redisCache := dbcache.NewRedisCache(config.CacheRedis[0].Host, config.CacheRedis[0].Pass, 15*time.Minute)
+	redisCache, err := dbcache.NewCache(config.CacheDriver, config.CacheRedis, 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
 	treeCtx, err := tree.NewContext(moduleName, config.TreeDB.Driver, false, config.TreeDB.RoutinePoolSize, &config.TreeDB.LevelDBOption, &config.TreeDB.RedisDBOption)
 	if err != nil {
 		return nil, err
@@ -111,6 +124,8 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 		return nil, err
 	}
 	bc.processor = NewCommitProcessor(bc)
+	bc.TreePruner = tree.NewPruner(treeCtx, config.TreeDB.Prune, bc.BlockModel.GetLatestVerifiedHeight, bc.Statedb.AccountAssetTrees)
+	bc.TreePruner.Start()
 
 	// register metrics
 	if err := prometheus.Register(updateTreeMetics); err != nil {
@@ -182,7 +197,9 @@ func (bc *BlockChain) CommitNewBlock(blockSize int, createdAt int64) (*block.Blo
 	currentHeight := bc.currentBlock.BlockHeight
 
 	start := time.Now()
+	bc.TreePruner.Lock()
 	err = tree.CommitTrees(uint64(currentHeight), bc.Statedb.AccountTree, bc.Statedb.AccountAssetTrees, bc.Statedb.NftTree)
+	bc.TreePruner.Unlock()
 	if err != nil {
 		return nil, err
 	}
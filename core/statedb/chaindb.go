@@ -7,9 +7,11 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockcheckpoint"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
+	"github.com/bnb-chain/zkbnb/dao/stats"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
 )
@@ -21,6 +23,8 @@ type ChainDB struct {
 	CompressedBlockModel compressedblock.CompressedBlockModel
 	TxModel              tx.TxModel
 	PriorityRequestModel priorityrequest.PriorityRequestModel
+	BlockCheckpointModel blockcheckpoint.BlockCheckpointModel
+	StatModel            stats.StatModel
 
 	// State DB
 	AccountModel        account.AccountModel
@@ -41,6 +45,8 @@ func NewChainDB(db *gorm.DB) *ChainDB {
 		CompressedBlockModel: compressedblock.NewCompressedBlockModel(db),
 		TxModel:              tx.NewTxModel(db),
 		PriorityRequestModel: priorityrequest.NewPriorityRequestModel(db),
+		BlockCheckpointModel: blockcheckpoint.NewBlockCheckpointModel(db),
+		StatModel:            stats.NewStatModel(db),
 
 		AccountModel:        account.NewAccountModel(db),
 		AccountHistoryModel: account.NewAccountHistoryModel(db),
@@ -0,0 +1,192 @@
+package statedb
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// ErrCheckpointMismatch is returned if the checkpoints do not cover the
+// executed txs in the pool, the txs must be executed again.
+var ErrCheckpointMismatch = errors.New("checkpoint does not match the executed txs")
+
+// checkpointMark records how much of the state cache is covered by the saved
+// checkpoints, and the accounts and nfts changed since the last one.
+type checkpointMark struct {
+	txs                      int
+	pubData                  int
+	pubDataOffset            int
+	onChainOperationsPubData int
+
+	accounts map[int64]bool
+	nfts     map[int64]bool
+}
+
+func newCheckpointMark() checkpointMark {
+	return checkpointMark{
+		accounts: make(map[int64]bool, 0),
+		nfts:     make(map[int64]bool, 0),
+	}
+}
+
+// stateCheckpoint is the change of the state cache of the proposing block
+// since the previous checkpoint, the checkpoints of a block are restored in
+// order. The txs are kept in the pool and only referenced by their ids. The
+// tx details are not stored in the pool, so they are part of the checkpoint.
+type stateCheckpoint struct {
+	FromTxIndex int
+	TxIds       []uint
+	TxDetails   [][]*tx.TxDetail
+
+	// The pub data is appended to the one of the previous checkpoints.
+	PubData                         []byte
+	PubDataOffset                   []uint32
+	PendingOnChainOperationsPubData [][]byte
+	PriorityOperations              int64
+	PendingOnChainOperationsHash    []byte
+
+	// The accounts and nfts changed since the previous checkpoint, the gas
+	// changes are few and always saved in full.
+	PendingAccountMap map[int64]*types.AccountInfo
+	PendingNftMap     map[int64]*nft.L2Nft
+	PendingGasMap     map[int64]*big.Int
+
+	DirtyAccountsAndAssetsMap map[int64][]int64
+	DirtyNftMap               []int64
+}
+
+// MarshalCheckpoint serializes the change of the state cache since the last
+// checkpoint and marks it as covered, fromTxIndex is the index of the first tx
+// of the checkpoint. The checkpoint must be saved before the next one is
+// marshalled.
+func (c *StateCache) MarshalCheckpoint() (fromTxIndex int, data []byte, err error) {
+	mark := c.checkpoint
+	checkpoint := &stateCheckpoint{
+		FromTxIndex: mark.txs,
+		TxIds:       make([]uint, 0, len(c.Txs)-mark.txs),
+		TxDetails:   make([][]*tx.TxDetail, 0, len(c.Txs)-mark.txs),
+
+		PubData:                         c.PubData[mark.pubData:],
+		PubDataOffset:                   c.PubDataOffset[mark.pubDataOffset:],
+		PendingOnChainOperationsPubData: c.PendingOnChainOperationsPubData[mark.onChainOperationsPubData:],
+		PriorityOperations:              c.PriorityOperations,
+		PendingOnChainOperationsHash:    c.PendingOnChainOperationsHash,
+
+		PendingAccountMap: make(map[int64]*types.AccountInfo, len(mark.accounts)),
+		PendingNftMap:     make(map[int64]*nft.L2Nft, len(mark.nfts)),
+		PendingGasMap:     c.PendingGasMap,
+
+		DirtyAccountsAndAssetsMap: make(map[int64][]int64, len(mark.accounts)),
+		DirtyNftMap:               make([]int64, 0, len(mark.nfts)),
+	}
+	for _, executedTx := range c.Txs[mark.txs:] {
+		checkpoint.TxIds = append(checkpoint.TxIds, executedTx.ID)
+		checkpoint.TxDetails = append(checkpoint.TxDetails, executedTx.TxDetails)
+	}
+	for accountIndex := range mark.accounts {
+		if account, ok := c.PendingAccountMap[accountIndex]; ok {
+			checkpoint.PendingAccountMap[accountIndex] = account
+		}
+		assets := make([]int64, 0, len(c.dirtyAccountsAndAssetsMap[accountIndex]))
+		for assetIndex, isDirty := range c.dirtyAccountsAndAssetsMap[accountIndex] {
+			if isDirty {
+				assets = append(assets, assetIndex)
+			}
+		}
+		if len(assets) > 0 {
+			checkpoint.DirtyAccountsAndAssetsMap[accountIndex] = assets
+		}
+	}
+	for nftIndex := range mark.nfts {
+		if nft, ok := c.PendingNftMap[nftIndex]; ok {
+			checkpoint.PendingNftMap[nftIndex] = nft
+		}
+		if c.dirtyNftMap[nftIndex] {
+			checkpoint.DirtyNftMap = append(checkpoint.DirtyNftMap, nftIndex)
+		}
+	}
+	data, err = json.Marshal(checkpoint)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c.markCheckpointed()
+	return mark.txs, data, nil
+}
+
+// markCheckpointed marks the whole state cache as covered by the checkpoints.
+func (c *StateCache) markCheckpointed() {
+	c.checkpoint = newCheckpointMark()
+	c.checkpoint.txs = len(c.Txs)
+	c.checkpoint.pubData = len(c.PubData)
+	c.checkpoint.pubDataOffset = len(c.PubDataOffset)
+	c.checkpoint.onChainOperationsPubData = len(c.PendingOnChainOperationsPubData)
+}
+
+// RestoreCheckpoints replaces the state cache with the checkpoints of the
+// proposing block instead of executing the txs again. The executed txs of
+// the pool must be exactly the txs of the checkpoints.
+func (s *StateDB) RestoreCheckpoints(checkpoints [][]byte, executedTxs []*tx.Tx, blockHeight int64) error {
+	executedTxMap := make(map[uint]*tx.Tx, len(executedTxs))
+	for _, executedTx := range executedTxs {
+		executedTxMap[executedTx.ID] = executedTx
+	}
+
+	cache := NewStateCache(s.StateRoot)
+	for _, data := range checkpoints {
+		checkpoint := &stateCheckpoint{}
+		if err := json.Unmarshal(data, checkpoint); err != nil {
+			return err
+		}
+		if checkpoint.FromTxIndex != len(cache.Txs) || len(checkpoint.TxIds) != len(checkpoint.TxDetails) {
+			return ErrCheckpointMismatch
+		}
+		for i, id := range checkpoint.TxIds {
+			executedTx, ok := executedTxMap[id]
+			if !ok || executedTx.TxIndex != int64(len(cache.Txs)) || executedTx.BlockHeight != blockHeight {
+				return ErrCheckpointMismatch
+			}
+			executedTx.TxDetails = checkpoint.TxDetails[i]
+			cache.Txs = append(cache.Txs, executedTx)
+		}
+		cache.PubData = append(cache.PubData, checkpoint.PubData...)
+		cache.PubDataOffset = append(cache.PubDataOffset, checkpoint.PubDataOffset...)
+		cache.PendingOnChainOperationsPubData = append(cache.PendingOnChainOperationsPubData, checkpoint.PendingOnChainOperationsPubData...)
+		cache.PriorityOperations = checkpoint.PriorityOperations
+		cache.PendingOnChainOperationsHash = checkpoint.PendingOnChainOperationsHash
+		for accountIndex, account := range checkpoint.PendingAccountMap {
+			cache.PendingAccountMap[accountIndex] = account
+		}
+		for nftIndex, nft := range checkpoint.PendingNftMap {
+			cache.PendingNftMap[nftIndex] = nft
+		}
+		if checkpoint.PendingGasMap != nil {
+			cache.PendingGasMap = checkpoint.PendingGasMap
+		}
+		for accountIndex, assets := range checkpoint.DirtyAccountsAndAssetsMap {
+			cache.MarkAccountAssetsDirty(accountIndex, assets)
+		}
+		for _, nftIndex := range checkpoint.DirtyNftMap {
+			cache.MarkNftDirty(nftIndex)
+		}
+	}
+	if len(cache.Txs) != len(executedTxs) {
+		return ErrCheckpointMismatch
+	}
+
+	cache.markCheckpointed()
+
+	// The accounts registered in the block are counted like the executor
+	// does.
+	for accountIndex := range cache.PendingAccountMap {
+		if accountIndex >= s.AccountAssetTrees.GetNextAccountIndex() {
+			s.AccountAssetTrees.UpdateCache(accountIndex, blockHeight)
+		}
+	}
+	s.StateCache = cache
+	return nil
+}
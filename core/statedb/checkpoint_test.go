@@ -0,0 +1,167 @@
+package statedb
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func newCheckpointTestStateDB(accountNumber int64) *StateDB {
+	return &StateDB{
+		StateCache: NewStateCache("01"),
+		AccountAssetTrees: tree.NewLazyTreeCache(16, accountNumber-1, 9, func(index, block int64) bsmt.SparseMerkleTree {
+			return nil
+		}),
+	}
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	s := newCheckpointTestStateDB(3)
+	for i := 0; i < 2; i++ {
+		s.Txs = append(s.Txs, &tx.Tx{
+			TxHash:      string(rune('a' + i)),
+			TxIndex:     int64(i),
+			BlockHeight: 10,
+			TxStatus:    tx.StatusExecuted,
+			TxDetails:   []*tx.TxDetail{{AccountIndex: int64(i), Balance: "100", Order: int64(i)}},
+		})
+		s.Txs[i].ID = uint(i + 5)
+	}
+	// account 3 is registered in the block
+	s.SetPendingAccount(3, &types.AccountInfo{
+		AccountIndex: 3,
+		AccountName:  "new.legend",
+		AssetInfo: map[int64]*types.AccountAsset{
+			1: {AssetId: 1, Balance: big.NewInt(7), OfferCanceledOrFinalized: big.NewInt(0)},
+		},
+	})
+	s.SetPendingNft(2, &nft.L2Nft{NftIndex: 2, OwnerAccountIndex: 3})
+	s.SetPendingGas(0, big.NewInt(12))
+	s.MarkAccountAssetsDirty(3, []int64{1})
+	s.MarkNftDirty(2)
+	s.PubData = []byte{1, 2, 3}
+	s.PubDataOffset = []uint32{0}
+	s.PriorityOperations = 1
+
+	fromTxIndex, data, err := s.MarshalCheckpoint()
+	require.NoError(t, err)
+	assert.Equal(t, 0, fromTxIndex)
+
+	// the pool does not keep the tx details
+	executedTxs := make([]*tx.Tx, 0, len(s.Txs))
+	for i := len(s.Txs) - 1; i >= 0; i-- {
+		poolTx := *s.Txs[i]
+		poolTx.TxDetails = nil
+		executedTxs = append(executedTxs, &poolTx)
+	}
+	restored := newCheckpointTestStateDB(3)
+	require.NoError(t, restored.RestoreCheckpoints([][]byte{data}, executedTxs, 10))
+
+	require.Len(t, restored.Txs, 2)
+	for i := range s.Txs {
+		assert.Equal(t, s.Txs[i].ID, restored.Txs[i].ID)
+		assert.Equal(t, s.Txs[i].TxDetails[0].AccountIndex, restored.Txs[i].TxDetails[0].AccountIndex)
+	}
+	assert.Equal(t, "01", restored.StateRoot)
+	assert.Equal(t, s.PubData, restored.PubData)
+	assert.Equal(t, s.PubDataOffset, restored.PubDataOffset)
+	assert.Equal(t, s.PriorityOperations, restored.PriorityOperations)
+	assert.Equal(t, s.PendingOnChainOperationsHash, restored.PendingOnChainOperationsHash)
+	assert.Equal(t, "new.legend", restored.PendingAccountMap[3].AccountName)
+	assert.Equal(t, int64(7), restored.PendingAccountMap[3].AssetInfo[1].Balance.Int64())
+	assert.Equal(t, int64(3), restored.PendingNftMap[2].OwnerAccountIndex)
+	assert.Equal(t, int64(12), restored.GetPendingGas(0).Int64())
+	assert.Equal(t, s.dirtyAccountsAndAssetsMap, restored.dirtyAccountsAndAssetsMap)
+	assert.Equal(t, s.dirtyNftMap, restored.dirtyNftMap)
+	assert.Equal(t, int64(4), restored.AccountAssetTrees.GetNextAccountIndex())
+}
+
+func TestCheckpointMismatch(t *testing.T) {
+	s := newCheckpointTestStateDB(1)
+	s.Txs = append(s.Txs, &tx.Tx{TxIndex: 0, BlockHeight: 10})
+	s.Txs[0].ID = 1
+	_, data, err := s.MarshalCheckpoint()
+	require.NoError(t, err)
+
+	restored := newCheckpointTestStateDB(1)
+	// a tx was executed after the checkpoint was saved
+	second := &tx.Tx{TxIndex: 1, BlockHeight: 10}
+	second.ID = 2
+	err = restored.RestoreCheckpoints([][]byte{data}, []*tx.Tx{{Model: s.Txs[0].Model, BlockHeight: 10}, second}, 10)
+	assert.Equal(t, ErrCheckpointMismatch, err)
+
+	// the tx was executed in another block
+	err = restored.RestoreCheckpoints([][]byte{data}, []*tx.Tx{{Model: s.Txs[0].Model, BlockHeight: 9}}, 10)
+	assert.Equal(t, ErrCheckpointMismatch, err)
+	assert.Empty(t, restored.Txs)
+
+	// the checkpoint of the first txs is missing
+	s.Txs = append(s.Txs, second)
+	_, data, err = s.MarshalCheckpoint()
+	require.NoError(t, err)
+	err = restored.RestoreCheckpoints([][]byte{data}, []*tx.Tx{{Model: s.Txs[0].Model, BlockHeight: 10}, second}, 10)
+	assert.Equal(t, ErrCheckpointMismatch, err)
+}
+
+func TestCheckpointRestoreBatches(t *testing.T) {
+	s := newCheckpointTestStateDB(3)
+	executedTxs := make([]*tx.Tx, 0, 3)
+	checkpoints := make([][]byte, 0, 2)
+	for i, batch := range [][]int64{{0, 1}, {1}} {
+		for _, accountIndex := range batch {
+			executedTx := &tx.Tx{
+				TxIndex:     int64(len(s.Txs)),
+				BlockHeight: 10,
+				TxStatus:    tx.StatusExecuted,
+				TxDetails:   []*tx.TxDetail{{AccountIndex: accountIndex}},
+			}
+			executedTx.ID = uint(len(s.Txs) + 1)
+			s.Txs = append(s.Txs, executedTx)
+			s.SetPendingAccount(accountIndex, &types.AccountInfo{AccountIndex: accountIndex, Nonce: executedTx.TxIndex})
+			s.MarkAccountAssetsDirty(accountIndex, []int64{int64(i)})
+			s.SetPendingGas(0, big.NewInt(1))
+			s.PubData = append(s.PubData, byte(executedTx.TxIndex))
+
+			poolTx := *executedTx
+			poolTx.TxDetails = nil
+			executedTxs = append(executedTxs, &poolTx)
+		}
+		fromTxIndex, data, err := s.MarshalCheckpoint()
+		require.NoError(t, err)
+		assert.Equal(t, len(s.Txs)-len(batch), fromTxIndex)
+		checkpoints = append(checkpoints, data)
+	}
+
+	// the second checkpoint only has the changes of its tx
+	checkpoint := &stateCheckpoint{}
+	require.NoError(t, json.Unmarshal(checkpoints[1], checkpoint))
+	assert.Equal(t, []uint{3}, checkpoint.TxIds)
+	assert.Equal(t, []byte{2}, checkpoint.PubData)
+	assert.Len(t, checkpoint.PendingAccountMap, 1)
+	assert.Contains(t, checkpoint.PendingAccountMap, int64(1))
+	assert.ElementsMatch(t, []int64{0, 1}, checkpoint.DirtyAccountsAndAssetsMap[1])
+
+	restored := newCheckpointTestStateDB(3)
+	require.NoError(t, restored.RestoreCheckpoints(checkpoints, executedTxs, 10))
+	require.Len(t, restored.Txs, 3)
+	assert.Equal(t, s.PubData, restored.PubData)
+	assert.Equal(t, int64(0), restored.PendingAccountMap[0].Nonce)
+	assert.Equal(t, int64(2), restored.PendingAccountMap[1].Nonce)
+	assert.Equal(t, int64(3), restored.GetPendingGas(0).Int64())
+	assert.Equal(t, s.dirtyAccountsAndAssetsMap, restored.dirtyAccountsAndAssetsMap)
+
+	// a checkpoint saved after the restore only has the new txs
+	restored.Txs = append(restored.Txs, &tx.Tx{TxIndex: 3, BlockHeight: 10})
+	fromTxIndex, _, err := restored.MarshalCheckpoint()
+	require.NoError(t, err)
+	assert.Equal(t, 3, fromTxIndex)
+}
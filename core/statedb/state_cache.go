@@ -30,6 +30,9 @@ type StateCache struct {
 	// Record the tree states that should be updated.
 	dirtyAccountsAndAssetsMap map[int64]map[int64]bool
 	dirtyNftMap               map[int64]bool
+
+	// Record the state that is not covered by the saved checkpoints.
+	checkpoint checkpointMark
 }
 
 func NewStateCache(stateRoot string) *StateCache {
@@ -49,6 +52,8 @@ func NewStateCache(stateRoot string) *StateCache {
 
 		dirtyAccountsAndAssetsMap: make(map[int64]map[int64]bool, 0),
 		dirtyNftMap:               make(map[int64]bool, 0),
+
+		checkpoint: newCheckpointMark(),
 	}
 }
 
@@ -62,6 +67,7 @@ func (c *StateCache) MarkAccountAssetsDirty(accountIndex int64, assets []int64)
 		return
 	}
 
+	c.checkpoint.accounts[accountIndex] = true
 	if _, ok := c.dirtyAccountsAndAssetsMap[accountIndex]; !ok {
 		c.dirtyAccountsAndAssetsMap[accountIndex] = make(map[int64]bool, 0)
 	}
@@ -76,6 +82,7 @@ func (c *StateCache) MarkAccountAssetsDirty(accountIndex int64, assets []int64)
 }
 
 func (c *StateCache) MarkNftDirty(nftIndex int64) {
+	c.checkpoint.nfts[nftIndex] = true
 	c.dirtyNftMap[nftIndex] = true
 }
 
@@ -96,10 +103,12 @@ func (c *StateCache) GetPendingNft(nftIndex int64) (*nft.L2Nft, bool) {
 }
 
 func (c *StateCache) SetPendingAccount(accountIndex int64, account *types.AccountInfo) {
+	c.checkpoint.accounts[accountIndex] = true
 	c.PendingAccountMap[accountIndex] = account
 }
 
 func (c *StateCache) SetPendingNft(nftIndex int64, nft *nft.L2Nft) {
+	c.checkpoint.nfts[nftIndex] = true
 	c.PendingNftMap[nftIndex] = nft
 }
 
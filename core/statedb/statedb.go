@@ -272,7 +272,7 @@ func (s *StateDB) MarkGasAccountAsPending() error {
 		return err
 	}
 	if err == nil {
-		s.PendingAccountMap[types.GasAccount] = gasAccount
+		s.SetPendingAccount(types.GasAccount, gasAccount)
 	}
 	return nil
 }
@@ -42,8 +42,11 @@ type (
 		GetAccountByName(name string) (account *Account, err error)
 		GetAccountByNameHash(nameHash string) (account *Account, err error)
 		GetAccounts(limit int, offset int64) (accounts []*Account, err error)
+		GetAccountsBeforeIndex(accountIndex int64, limit int) (accounts []*Account, err error)
 		GetAccountsTotalCount() (count int64, err error)
+		CreateAccountsInTransact(tx *gorm.DB, accounts []*Account) error
 		UpdateAccountsInTransact(tx *gorm.DB, accounts []*Account) error
+		DeleteAccountsInTransact(tx *gorm.DB, accountIndexes []int64) error
 	}
 
 	defaultAccountModel struct {
@@ -140,6 +143,19 @@ func (m *defaultAccountModel) GetAccounts(limit int, offset int64) (accounts []*
 	return accounts, nil
 }
 
+// GetAccountsBeforeIndex returns the accounts with a smaller index by index desc,
+// which is the next page of GetAccounts without an offset.
+func (m *defaultAccountModel) GetAccountsBeforeIndex(accountIndex int64, limit int) (accounts []*Account, err error) {
+	dbTx := m.DB.Table(m.table).Where("account_index < ?", accountIndex).
+		Limit(limit).Order("account_index desc").Find(&accounts)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return accounts, nil
+}
+
 func (m *defaultAccountModel) GetAccountsTotalCount() (count int64, err error) {
 	dbTx := m.DB.Table(m.table).Where("deleted_at is NULL").Count(&count)
 	if dbTx.Error != nil {
@@ -160,6 +176,17 @@ func (m *defaultAccountModel) GetConfirmedAccountByIndex(accountIndex int64) (ac
 	return account, nil
 }
 
+func (m *defaultAccountModel) CreateAccountsInTransact(tx *gorm.DB, accounts []*Account) error {
+	dbTx := tx.Table(m.table).CreateInBatches(accounts, len(accounts))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(accounts)) {
+		return types.DbErrFailToCreateAccount
+	}
+	return nil
+}
+
 func (m *defaultAccountModel) UpdateAccountsInTransact(tx *gorm.DB, accounts []*Account) error {
 	for _, account := range accounts {
 		dbTx := tx.Table(m.table).Where("account_index = ?", account.AccountIndex).
@@ -178,3 +205,11 @@ func (m *defaultAccountModel) UpdateAccountsInTransact(tx *gorm.DB, accounts []*
 	}
 	return nil
 }
+
+func (m *defaultAccountModel) DeleteAccountsInTransact(tx *gorm.DB, accountIndexes []int64) error {
+	if len(accountIndexes) == 0 {
+		return nil
+	}
+	dbTx := tx.Table(m.table).Unscoped().Where("account_index IN ?", accountIndexes).Delete(&Account{})
+	return dbTx.Error
+}
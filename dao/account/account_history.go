@@ -34,6 +34,8 @@ type (
 		GetValidAccountCount(height int64) (accounts int64, err error)
 		CreateAccountHistoriesInTransact(tx *gorm.DB, histories []*AccountHistory) error
 		GetLatestAccountHistory(accountIndex, height int64) (accountHistory *AccountHistory, err error)
+		GetAccountIndexesAfterHeight(height int64) (accountIndexes []int64, err error)
+		DeleteAccountHistoriesAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultAccountHistoryModel struct {
@@ -131,3 +133,17 @@ func (m *defaultAccountHistoryModel) GetLatestAccountHistory(accountIndex, heigh
 	}
 	return accountHistory, nil
 }
+
+func (m *defaultAccountHistoryModel) GetAccountIndexesAfterHeight(height int64) (accountIndexes []int64, err error) {
+	dbTx := m.DB.Table(m.table).Distinct("account_index").Where("l2_block_height > ?", height).
+		Order("account_index").Find(&accountIndexes)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return accountIndexes, nil
+}
+
+func (m *defaultAccountHistoryModel) DeleteAccountHistoriesAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("l2_block_height > ?", height).Delete(&AccountHistory{})
+	return dbTx.Error
+}
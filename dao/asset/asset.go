@@ -39,6 +39,7 @@ type (
 		CreateAssets(assets []*Asset) (rowsAffected int64, err error)
 		GetAssetsTotalCount() (count int64, err error)
 		GetAssets(limit int64, offset int64) (assets []*Asset, err error)
+		GetAssetsAfterId(id uint, limit int64) (assets []*Asset, err error)
 		GetAssetById(assetId int64) (asset *Asset, err error)
 		GetAssetBySymbol(symbol string) (asset *Asset, err error)
 		GetAssetByAddress(address string) (asset *Asset, err error)
@@ -105,6 +106,19 @@ func (m *defaultAssetModel) GetAssets(limit int64, offset int64) (res []*Asset,
 	return res, nil
 }
 
+// GetAssetsAfterId returns the assets with a greater id by id asc, which is the
+// next page of GetAssets without an offset.
+func (m *defaultAssetModel) GetAssetsAfterId(id uint, limit int64) (res []*Asset, err error) {
+	dbTx := m.DB.Table(m.table).Where("id > ?", id).Limit(int(limit)).Order("id asc").Find(&res)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return res, nil
+}
+
 func (m *defaultAssetModel) CreateAssets(l2Assets []*Asset) (rowsAffected int64, err error) {
 	dbTx := m.DB.Table(m.table).CreateInBatches(l2Assets, len(l2Assets))
 	if dbTx.Error != nil {
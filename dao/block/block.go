@@ -35,6 +35,8 @@ const (
 	StatusPending
 	StatusCommitted
 	StatusVerifiedAndExecuted
+	// StatusReverted marks committed blocks reverted by the rollup contract.
+	StatusReverted
 )
 
 const (
@@ -46,6 +48,7 @@ type (
 		CreateBlockTable() error
 		DropBlockTable() error
 		GetBlocks(limit int64, offset int64) (blocks []*Block, err error)
+		GetBlocksBeforeHeight(height int64, limit int64) (blocks []*Block, err error)
 		GetBlocksBetween(start int64, end int64) (blocks []*Block, err error)
 		GetBlockByHeight(blockHeight int64) (block *Block, err error)
 		GetBlockByHeightWithoutTx(blockHeight int64) (block *Block, err error)
@@ -60,6 +63,8 @@ type (
 		CreateBlockInTransact(tx *gorm.DB, oBlock *Block) error
 		UpdateBlocksWithoutTxsInTransact(tx *gorm.DB, blocks []*Block) (err error)
 		UpdateBlockInTransact(tx *gorm.DB, block *Block) (err error)
+		GetBlocksByStatus(status int64) (blocks []*Block, err error)
+		DeleteBlocksAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultBlockModel struct {
@@ -122,11 +127,21 @@ func (m *defaultBlockModel) DropBlockTable() error {
 }
 
 func (m *defaultBlockModel) GetBlocks(limit int64, offset int64) (blocks []*Block, err error) {
+	return m.getBlocksWithTxs(m.DB.Table(m.table).Limit(int(limit)).Offset(int(offset)))
+}
+
+// GetBlocksBeforeHeight returns the blocks lower than the height by height desc,
+// which is the next page of GetBlocks without an offset.
+func (m *defaultBlockModel) GetBlocksBeforeHeight(height int64, limit int64) (blocks []*Block, err error) {
+	return m.getBlocksWithTxs(m.DB.Table(m.table).Where("block_height < ?", height).Limit(int(limit)))
+}
+
+func (m *defaultBlockModel) getBlocksWithTxs(dbTx *gorm.DB) (blocks []*Block, err error) {
 	var (
 		txForeignKeyColumn = `Txs`
 	)
 
-	dbTx := m.DB.Table(m.table).Limit(int(limit)).Offset(int(offset)).Order("block_height desc").Find(&blocks)
+	dbTx = dbTx.Order("block_height desc").Find(&blocks)
 	if dbTx.Error != nil {
 		return nil, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -240,7 +255,8 @@ func (m *defaultBlockModel) GetBlockByHeightWithoutTx(blockHeight int64) (block
 }
 
 func (m *defaultBlockModel) GetCommittedBlocksCount() (count int64, err error) {
-	dbTx := m.DB.Table(m.table).Where("block_status >= ? and deleted_at is NULL", StatusCommitted).Count(&count)
+	dbTx := m.DB.Table(m.table).Where("block_status IN ? and deleted_at is NULL",
+		[]int64{StatusCommitted, StatusVerifiedAndExecuted}).Count(&count)
 	if dbTx.Error != nil {
 		if dbTx.Error == types.DbErrNotFound {
 			return 0, nil
@@ -371,3 +387,18 @@ func (m *defaultBlockModel) UpdateBlockInTransact(tx *gorm.DB, block *Block) (er
 	}
 	return nil
 }
+
+func (m *defaultBlockModel) GetBlocksByStatus(status int64) (blocks []*Block, err error) {
+	dbTx := m.DB.Table(m.table).Where("block_status = ?", status).Order("block_height").Find(&blocks)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return blocks, nil
+}
+
+func (m *defaultBlockModel) DeleteBlocksAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_height > ?", height).Delete(&Block{})
+	return dbTx.Error
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package blockcheckpoint
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	TableName = `block_checkpoint`
+)
+
+type (
+	BlockCheckpointModel interface {
+		CreateBlockCheckpointTable() error
+		DropBlockCheckpointTable() error
+		GetBlockCheckpoints(height int64) (checkpoints []*BlockCheckpoint, err error)
+		SaveBlockCheckpointInTransact(tx *gorm.DB, checkpoint *BlockCheckpoint) error
+		DeleteBlockCheckpointsInTransact(tx *gorm.DB, height int64) error
+		DeleteBlockCheckpointsAfterHeightInTransact(tx *gorm.DB, height int64) error
+	}
+
+	defaultBlockCheckpointModel struct {
+		table string
+		DB    *gorm.DB
+	}
+
+	// BlockCheckpoint is the execution state of a batch of txs of the
+	// proposing block, it is saved together with the executed txs in the pool
+	// so that the committer can resume the block without executing the txs
+	// again. The batch covers the txs from FromTxIndex up to TxCount.
+	BlockCheckpoint struct {
+		gorm.Model
+		BlockHeight int64 `gorm:"uniqueIndex:idx_block_checkpoint_height_from"`
+		FromTxIndex int   `gorm:"uniqueIndex:idx_block_checkpoint_height_from"`
+		TxCount     int
+		State       string
+	}
+)
+
+func NewBlockCheckpointModel(db *gorm.DB) BlockCheckpointModel {
+	return &defaultBlockCheckpointModel{
+		table: TableName,
+		DB:    db,
+	}
+}
+
+func (*BlockCheckpoint) TableName() string {
+	return TableName
+}
+
+func (m *defaultBlockCheckpointModel) CreateBlockCheckpointTable() error {
+	return m.DB.AutoMigrate(BlockCheckpoint{})
+}
+
+func (m *defaultBlockCheckpointModel) DropBlockCheckpointTable() error {
+	return m.DB.Migrator().DropTable(m.table)
+}
+
+// GetBlockCheckpoints returns the checkpoints of the block height in the order
+// of their txs.
+func (m *defaultBlockCheckpointModel) GetBlockCheckpoints(height int64) (checkpoints []*BlockCheckpoint, err error) {
+	dbTx := m.DB.Table(m.table).Where("block_height = ?", height).Order("from_tx_index asc").Find(&checkpoints)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return checkpoints, nil
+}
+
+// SaveBlockCheckpointInTransact adds the checkpoint of the block height, the
+// checkpoints of the same or later txs are replaced.
+func (m *defaultBlockCheckpointModel) SaveBlockCheckpointInTransact(tx *gorm.DB, checkpoint *BlockCheckpoint) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_height = ? AND from_tx_index >= ?", checkpoint.BlockHeight, checkpoint.FromTxIndex).Delete(&BlockCheckpoint{})
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	dbTx = tx.Table(m.table).Create(checkpoint)
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	return nil
+}
+
+// DeleteBlockCheckpointsInTransact deletes the checkpoints up to the height,
+// it is called once the block is committed.
+func (m *defaultBlockCheckpointModel) DeleteBlockCheckpointsInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_height <= ?", height).Delete(&BlockCheckpoint{})
+	return dbTx.Error
+}
+
+// DeleteBlockCheckpointsAfterHeightInTransact deletes the checkpoints of the
+// blocks after the height, it is called when the blocks are rolled back.
+func (m *defaultBlockCheckpointModel) DeleteBlockCheckpointsAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_height > ?", height).Delete(&BlockCheckpoint{})
+	return dbTx.Error
+}
@@ -23,12 +23,18 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
 const (
 	StatusPublished = iota
 	StatusReceived
+	// StatusFailed witnesses got an invalid proof, they are not rescheduled
+	// until the prover is restarted.
+	StatusFailed
+	// StatusPruned witnesses were deleted after their block was verified on l1.
+	StatusPruned
 )
 
 const (
@@ -42,8 +48,14 @@ type (
 		GetLatestBlockWitnessHeight() (height int64, err error)
 		GetBlockWitnessByHeight(height int64) (witness *BlockWitness, err error)
 		UpdateBlockWitnessStatus(witness *BlockWitness, status int64) error
-		GetLatestBlockWitness() (witness *BlockWitness, err error)
+		UpdateBlockWitnessFailed(witness *BlockWitness, reason string) error
+		RepublishFailedBlockWitnesses() (count int64, err error)
+		GetBlockWitnessesToPrune(height int64, limit int) (witnesses []*BlockWitness, err error)
+		UpdateBlockWitnessPruned(witness *BlockWitness) error
+		GetLatestBlockWitness(blockSizes []int) (witness *BlockWitness, err error)
 		CreateBlockWitness(witness *BlockWitness) error
+		CreateBlockWitnessInTransact(tx *gorm.DB, witness *BlockWitness) error
+		DeleteBlockWitnessesAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultBlockWitnessModel struct {
@@ -53,9 +65,14 @@ type (
 
 	BlockWitness struct {
 		gorm.Model
-		Height      int64 `gorm:"index:idx_height,unique"`
-		WitnessData string
-		Status      int64
+		Height        int64 `gorm:"index:idx_height,unique"`
+		BlockSize     uint16
+		WitnessData   string
+		Status        int64
+		FailureReason string
+		// WitnessRef points to the witness data in an external storage.
+		WitnessRef  string
+		WitnessHash string
 	}
 )
 
@@ -70,8 +87,19 @@ func (*BlockWitness) TableName() string {
 	return TableName
 }
 
+// CreateBlockWitnessTable creates or migrates the table, the block size of
+// the witnesses created before the column existed is backfilled from the
+// blocks.
 func (m *defaultBlockWitnessModel) CreateBlockWitnessTable() error {
-	return m.DB.AutoMigrate(BlockWitness{})
+	if err := m.DB.AutoMigrate(BlockWitness{}); err != nil {
+		return err
+	}
+	if !m.DB.Migrator().HasTable(block.BlockTableName) {
+		return nil
+	}
+	return m.DB.Exec(fmt.Sprintf(
+		"UPDATE %s SET block_size = %s.block_size FROM %s WHERE %s.block_height = %s.height AND %s.block_size = 0",
+		m.table, block.BlockTableName, block.BlockTableName, block.BlockTableName, m.table, m.table)).Error
 }
 
 func (m *defaultBlockWitnessModel) DropBlockWitnessTable() error {
@@ -89,8 +117,16 @@ func (m *defaultBlockWitnessModel) GetLatestBlockWitnessHeight() (blockNumber in
 	return row.Height, nil
 }
 
-func (m *defaultBlockWitnessModel) GetLatestBlockWitness() (witness *BlockWitness, err error) {
-	dbTx := m.DB.Table(m.table).Where("status = ?", StatusPublished).Order("height asc").Limit(1).Find(&witness)
+// GetLatestBlockWitness returns the lowest unproved witness of the block sizes,
+// or of any block size if blockSizes is empty.
+func (m *defaultBlockWitnessModel) GetLatestBlockWitness(blockSizes []int) (witness *BlockWitness, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ?", StatusPublished)
+	if len(blockSizes) > 0 {
+		// the witnesses which are not backfilled yet take the size of their block
+		dbTx = dbTx.Where("(block_size IN ? OR (block_size = 0 AND height IN (?)))", blockSizes,
+			m.DB.Table(block.BlockTableName).Select("block_height").Where("block_size IN ?", blockSizes))
+	}
+	dbTx = dbTx.Order("height asc").Limit(1).Find(&witness)
 	if dbTx.Error != nil {
 		return nil, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -124,6 +160,16 @@ func (m *defaultBlockWitnessModel) CreateBlockWitness(witness *BlockWitness) err
 	return nil
 }
 
+// CreateBlockWitnessInTransact creates the witness without checking the
+// previous one, it is used when the state is restored from a snapshot.
+func (m *defaultBlockWitnessModel) CreateBlockWitnessInTransact(tx *gorm.DB, witness *BlockWitness) error {
+	dbTx := tx.Table(m.table).Create(witness)
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	return nil
+}
+
 func (m *defaultBlockWitnessModel) UpdateBlockWitnessStatus(witness *BlockWitness, status int64) error {
 	witness.Status = status
 	witness.UpdatedAt = time.Now()
@@ -133,3 +179,55 @@ func (m *defaultBlockWitnessModel) UpdateBlockWitnessStatus(witness *BlockWitnes
 	}
 	return nil
 }
+
+func (m *defaultBlockWitnessModel) UpdateBlockWitnessFailed(witness *BlockWitness, reason string) error {
+	witness.FailureReason = reason
+	return m.UpdateBlockWitnessStatus(witness, StatusFailed)
+}
+
+func (m *defaultBlockWitnessModel) RepublishFailedBlockWitnesses() (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ?", StatusFailed).
+		Updates(map[string]interface{}{
+			"status":     StatusPublished,
+			"updated_at": time.Now(),
+		})
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return dbTx.RowsAffected, nil
+}
+
+// GetBlockWitnessesToPrune returns the witnesses up to height which are not
+// pruned yet, without their witness data.
+func (m *defaultBlockWitnessModel) GetBlockWitnessesToPrune(height int64, limit int) (witnesses []*BlockWitness, err error) {
+	dbTx := m.DB.Table(m.table).
+		Select("id", "created_at", "updated_at", "height", "block_size", "witness_ref", "witness_hash", "status").
+		Where("height <= ? AND status != ?", height, StatusPruned).
+		Order("height asc").Limit(limit).Find(&witnesses)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return witnesses, nil
+}
+
+func (m *defaultBlockWitnessModel) UpdateBlockWitnessPruned(witness *BlockWitness) error {
+	witness.Status = StatusPruned
+	witness.WitnessData = ""
+	witness.UpdatedAt = time.Now()
+	dbTx := m.DB.Table(m.table).Where("id = ?", witness.ID).Updates(map[string]interface{}{
+		"status":       witness.Status,
+		"witness_data": witness.WitnessData,
+		"updated_at":   witness.UpdatedAt,
+	})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
+
+func (m *defaultBlockWitnessModel) DeleteBlockWitnessesAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("height > ?", height).Delete(&BlockWitness{})
+	return dbTx.Error
+}
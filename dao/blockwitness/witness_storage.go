@@ -0,0 +1,90 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package blockwitness
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bnb-chain/zkbnb/common/storage"
+)
+
+var (
+	ErrWitnessPruned  = errors.New("block witness is pruned")
+	ErrStorageMissing = errors.New("block witness is in an external storage which is not configured")
+)
+
+// WitnessStorage keeps the witness data in the table, or in the blob storage
+// with only a pointer and the hash of the data in the table.
+type WitnessStorage struct {
+	blobs *storage.Blobs
+}
+
+func NewWitnessStorage(blobs *storage.Blobs) *WitnessStorage {
+	return &WitnessStorage{blobs: blobs}
+}
+
+func witnessKey(height int64) string {
+	return fmt.Sprintf("witness/%d.json.gz", height)
+}
+
+func (s *WitnessStorage) SetWitnessData(witness *BlockWitness, data []byte) error {
+	if s.blobs == nil {
+		witness.WitnessData = string(data)
+		witness.WitnessHash = storage.ContentHash(data)
+		return nil
+	}
+	key := witnessKey(witness.Height)
+	hash, err := s.blobs.Put(key, data)
+	if err != nil {
+		return fmt.Errorf("failed to store block witness, err: %v", err)
+	}
+	witness.WitnessRef = key
+	witness.WitnessHash = hash
+	return nil
+}
+
+func (s *WitnessStorage) GetWitnessData(witness *BlockWitness) ([]byte, error) {
+	if witness.Status == StatusPruned {
+		return nil, ErrWitnessPruned
+	}
+	if witness.WitnessRef == "" {
+		data := []byte(witness.WitnessData)
+		// witnesses stored before hashing was introduced have no hash
+		if witness.WitnessHash != "" && storage.ContentHash(data) != witness.WitnessHash {
+			return nil, storage.ErrHashMismatch
+		}
+		return data, nil
+	}
+	if s.blobs == nil {
+		return nil, ErrStorageMissing
+	}
+	return s.blobs.Get(witness.WitnessRef, witness.WitnessHash)
+}
+
+// DeleteWitnessData deletes the external witness data, the data in the table
+// is cleared when the witness is marked as pruned.
+func (s *WitnessStorage) DeleteWitnessData(witness *BlockWitness) error {
+	if witness.WitnessRef == "" {
+		return nil
+	}
+	if s.blobs == nil {
+		return ErrStorageMissing
+	}
+	return s.blobs.Delete(witness.WitnessRef)
+}
@@ -33,6 +33,7 @@ type (
 		DropCompressedBlockTable() error
 		GetCompressedBlocksBetween(start, end int64) (blocksForCommit []*CompressedBlock, err error)
 		CreateCompressedBlockInTransact(tx *gorm.DB, block *CompressedBlock) error
+		DeleteCompressedBlocksAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultCompressedBlockModel struct {
@@ -90,3 +91,8 @@ func (m *defaultCompressedBlockModel) CreateCompressedBlockInTransact(tx *gorm.D
 	}
 	return nil
 }
+
+func (m *defaultCompressedBlockModel) DeleteCompressedBlocksAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_height > ?", height).Delete(&CompressedBlock{})
+	return dbTx.Error
+}
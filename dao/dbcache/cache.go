@@ -2,7 +2,16 @@ package dbcache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+)
+
+var (
+	ErrUnsupportedDriver = errors.New("unsupported cache driver")
+	ErrNoRedis           = errors.New("no redis is configured")
 )
 
 type QueryFunc func() (interface{}, error)
@@ -15,6 +24,18 @@ type Cache interface {
 	Close() error
 }
 
+// Driver is the storage of the cache, redis is shared by all services while
+// memory is only visible to the process that writes it. Twotier keeps the
+// recently used values of redis in process and drops them once they are
+// changed by any service.
+type Driver string
+
+const (
+	RedisDriver   Driver = "redis"
+	MemoryDriver  Driver = "memory"
+	TwoTierDriver Driver = "twotier"
+)
+
 const (
 	AccountKeyPrefix = "cache:account_"
 	NftKeyPrefix     = "cache:nft_"
@@ -22,6 +43,25 @@ const (
 	GasConfigKey     = "cache:gasConfig"
 )
 
+// NewCache creates the cache of the driver, redis is used if no driver is set.
+func NewCache(driver Driver, redisConf cache.CacheConf, expiration time.Duration) (Cache, error) {
+	switch driver {
+	case "", RedisDriver:
+		if len(redisConf) == 0 {
+			return nil, ErrNoRedis
+		}
+		return NewRedisCache(redisConf[0].Host, redisConf[0].Pass, expiration), nil
+	case MemoryDriver:
+		return NewMemoryCache(DefaultMemoryCacheSize, expiration), nil
+	case TwoTierDriver:
+		if len(redisConf) == 0 {
+			return nil, ErrNoRedis
+		}
+		return NewTwoTierCache(redisConf[0].Host, redisConf[0].Pass, expiration)
+	}
+	return nil, ErrUnsupportedDriver
+}
+
 func AccountKeyByIndex(accountIndex int64) string {
 	return AccountKeyPrefix + fmt.Sprintf("%d", accountIndex)
 }
@@ -0,0 +1,96 @@
+package dbcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const DefaultMemoryCacheSize = 100000
+
+var (
+	memoryKeyNotExist = errors.New("memory cache: key does not exist")
+)
+
+type memoryEntry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// MemoryCache keeps the values in process, they are encoded like in redis so
+// that the callers can't change the cached values.
+type MemoryCache struct {
+	cache      *lru.Cache
+	expiration time.Duration
+}
+
+func NewMemoryCache(size int, expiration time.Duration) Cache {
+	return newMemoryCache(size, expiration)
+}
+
+func newMemoryCache(size int, expiration time.Duration) *MemoryCache {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic("create memory cache failed: " + err.Error())
+	}
+	return &MemoryCache{
+		cache:      cache,
+		expiration: expiration,
+	}
+}
+
+func (c *MemoryCache) GetWithSet(ctx context.Context, key string, valueStruct interface{}, query QueryFunc) (interface{}, error) {
+	value, err := c.Get(ctx, key, valueStruct)
+	if err == nil {
+		return value, nil
+	}
+	if err == memoryKeyNotExist {
+		value, err = query()
+		if err != nil {
+			return nil, err
+		}
+		return value, c.Set(ctx, key, value)
+	}
+	return nil, err
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string, value interface{}) (interface{}, error) {
+	cached, ok := c.cache.Get(key)
+	if !ok {
+		return nil, memoryKeyNotExist
+	}
+	entry := cached.(*memoryEntry)
+	if c.expiration > 0 && time.Now().After(entry.expireAt) {
+		c.cache.Remove(key)
+		return nil, memoryKeyNotExist
+	}
+	if err := json.Unmarshal(entry.value, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.cache.Add(key, &memoryEntry{
+		value:    bytes,
+		expireAt: time.Now().Add(c.expiration),
+	})
+	return nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.cache.Remove(key)
+	return nil
+}
+
+func (c *MemoryCache) Close() error {
+	c.cache.Purge()
+	return nil
+}
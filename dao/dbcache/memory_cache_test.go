@@ -0,0 +1,62 @@
+package dbcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	Index int64
+	Name  string
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache(MemoryDriver, nil, time.Minute)
+	require.NoError(t, err)
+
+	value := &testValue{Index: 1, Name: "a"}
+	require.NoError(t, c.Set(ctx, "key", value))
+	// later changes of the value are not cached
+	value.Name = "b"
+
+	cached := &testValue{}
+	_, err = c.Get(ctx, "key", cached)
+	require.NoError(t, err)
+	assert.Equal(t, "a", cached.Name)
+
+	require.NoError(t, c.Delete(ctx, "key"))
+	_, err = c.Get(ctx, "key", cached)
+	assert.Error(t, err)
+
+	queried, err := c.GetWithSet(ctx, "key", &testValue{}, func() (interface{}, error) {
+		return &testValue{Index: 2}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), queried.(*testValue).Index)
+	_, err = c.Get(ctx, "key", cached)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cached.Index)
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10, time.Millisecond)
+	require.NoError(t, c.Set(ctx, "key", int64(1)))
+	time.Sleep(5 * time.Millisecond)
+
+	var value int64
+	_, err := c.Get(ctx, "key", &value)
+	assert.Error(t, err)
+}
+
+func TestNewCache(t *testing.T) {
+	_, err := NewCache(RedisDriver, nil, time.Minute)
+	assert.Equal(t, ErrNoRedis, err)
+	_, err = NewCache("unknown", nil, time.Minute)
+	assert.Equal(t, ErrUnsupportedDriver, err)
+}
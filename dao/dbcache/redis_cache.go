@@ -23,7 +23,10 @@ type RedisCache struct {
 }
 
 func NewRedisCache(redisAdd, password string, expiration time.Duration) Cache {
-	client := redis.NewClient(&redis.Options{Addr: redisAdd, Password: password})
+	return newRedisCache(redis.NewClient(&redis.Options{Addr: redisAdd, Password: password}), expiration)
+}
+
+func newRedisCache(client *redis.Client, expiration time.Duration) *RedisCache {
 	redisInstance := store.NewRedis(client, &store.Options{Expiration: expiration})
 	redisCacheManager := cache.New(redisInstance)
 	promMetrics := metrics.NewPrometheus("zkbnb")
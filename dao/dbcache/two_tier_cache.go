@@ -0,0 +1,188 @@
+package dbcache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stringx"
+)
+
+const (
+	// InvalidationChannel is the redis channel of the changed keys.
+	InvalidationChannel = "cache:invalidation"
+
+	DefaultLocalCacheSize = 100000
+	// DefaultLocalExpiration bounds how long a local value can be stale if
+	// an invalidation is lost.
+	DefaultLocalExpiration = time.Minute
+)
+
+var (
+	cacheAccessMetrics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "two_tier_cache_access",
+		Help:      "Hits and misses of the tiers of the two-tier cache.",
+	}, []string{"tier", "result"})
+	localHitMetric   = cacheAccessMetrics.WithLabelValues("local", "hit")
+	localMissMetric  = cacheAccessMetrics.WithLabelValues("local", "miss")
+	remoteHitMetric  = cacheAccessMetrics.WithLabelValues("redis", "hit")
+	remoteMissMetric = cacheAccessMetrics.WithLabelValues("redis", "miss")
+)
+
+// TwoTierCache keeps the recently used values of redis in a bounded local
+// cache. Every change is published to InvalidationChannel, so that the other
+// processes drop the key from their local cache.
+type TwoTierCache struct {
+	id      string
+	local   *MemoryCache
+	remote  Cache
+	publish func(ctx context.Context, message string) error
+	pubsub  *redis.PubSub
+
+	// generation is increased by every invalidation, a value read from redis
+	// is only cached locally if no invalidation arrived in the meantime.
+	generation uint64
+}
+
+func NewTwoTierCache(redisAdd, password string, expiration time.Duration) (Cache, error) {
+	if err := prometheus.Register(cacheAccessMetrics); err != nil {
+		return nil, fmt.Errorf("prometheus.Register cacheAccessMetrics error: %v", err)
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: redisAdd, Password: password})
+	localExpiration := DefaultLocalExpiration
+	if expiration < localExpiration {
+		localExpiration = expiration
+	}
+	c := newTwoTierCache(newMemoryCache(DefaultLocalCacheSize, localExpiration), newRedisCache(client, expiration),
+		func(ctx context.Context, message string) error {
+			return client.Publish(ctx, InvalidationChannel, message).Err()
+		})
+	c.pubsub = client.Subscribe(context.Background(), InvalidationChannel)
+	go c.subscribe()
+	return c, nil
+}
+
+func newTwoTierCache(local *MemoryCache, remote Cache, publish func(ctx context.Context, message string) error) *TwoTierCache {
+	return &TwoTierCache{
+		id:      stringx.Randn(8),
+		local:   local,
+		remote:  remote,
+		publish: publish,
+	}
+}
+
+func (c *TwoTierCache) subscribe() {
+	for msg := range c.pubsub.ChannelWithSubscriptions(context.Background(), 1000) {
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			// The invalidations are lost while the connection is broken, it
+			// is subscribed again after the reconnection.
+			if m.Kind == "subscribe" {
+				c.invalidateAll()
+			}
+		case *redis.Message:
+			c.onInvalidation(m.Payload)
+		}
+	}
+}
+
+func (c *TwoTierCache) onInvalidation(message string) {
+	parts := strings.SplitN(message, " ", 2)
+	if len(parts) != 2 || parts[0] == c.id {
+		return
+	}
+	atomic.AddUint64(&c.generation, 1)
+	_ = c.local.Delete(context.Background(), parts[1])
+}
+
+func (c *TwoTierCache) invalidateAll() {
+	atomic.AddUint64(&c.generation, 1)
+	c.local.cache.Purge()
+}
+
+func (c *TwoTierCache) GetWithSet(ctx context.Context, key string, valueStruct interface{}, query QueryFunc) (interface{}, error) {
+	value, err := c.local.Get(ctx, key, valueStruct)
+	if err == nil {
+		localHitMetric.Inc()
+		return value, nil
+	}
+	localMissMetric.Inc()
+
+	generation := atomic.LoadUint64(&c.generation)
+	queried := false
+	value, err = c.remote.GetWithSet(ctx, key, valueStruct, func() (interface{}, error) {
+		queried = true
+		return query()
+	})
+	if queried || err != nil {
+		remoteMissMetric.Inc()
+	} else {
+		remoteHitMetric.Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.setLocal(ctx, key, value, generation)
+	return value, nil
+}
+
+func (c *TwoTierCache) Get(ctx context.Context, key string, value interface{}) (interface{}, error) {
+	object, err := c.local.Get(ctx, key, value)
+	if err == nil {
+		localHitMetric.Inc()
+		return object, nil
+	}
+	localMissMetric.Inc()
+
+	generation := atomic.LoadUint64(&c.generation)
+	object, err = c.remote.Get(ctx, key, value)
+	if err != nil {
+		remoteMissMetric.Inc()
+		return nil, err
+	}
+	remoteHitMetric.Inc()
+	c.setLocal(ctx, key, object, generation)
+	return object, nil
+}
+
+func (c *TwoTierCache) setLocal(ctx context.Context, key string, value interface{}, generation uint64) {
+	if atomic.LoadUint64(&c.generation) != generation {
+		return
+	}
+	if err := c.local.Set(ctx, key, value); err != nil {
+		logx.Errorf("set local cache of %s failed: %v", key, err)
+	}
+}
+
+func (c *TwoTierCache) Set(ctx context.Context, key string, value interface{}) error {
+	if err := c.remote.Set(ctx, key, value); err != nil {
+		return err
+	}
+	if err := c.publish(ctx, c.id+" "+key); err != nil {
+		return err
+	}
+	return c.local.Set(ctx, key, value)
+}
+
+func (c *TwoTierCache) Delete(ctx context.Context, key string) error {
+	_ = c.local.Delete(ctx, key)
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	return c.publish(ctx, c.id+" "+key)
+}
+
+func (c *TwoTierCache) Close() error {
+	if c.pubsub != nil {
+		_ = c.pubsub.Close()
+	}
+	_ = c.local.Close()
+	return c.remote.Close()
+}
@@ -0,0 +1,106 @@
+package dbcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTwoTierCaches returns the caches of two processes which share the
+// remote cache, the invalidations are delivered synchronously.
+func newTestTwoTierCaches() (*TwoTierCache, *TwoTierCache, Cache) {
+	remote := NewMemoryCache(10, time.Minute)
+	var caches []*TwoTierCache
+	publish := func(ctx context.Context, message string) error {
+		for _, c := range caches {
+			c.onInvalidation(message)
+		}
+		return nil
+	}
+	caches = append(caches,
+		newTwoTierCache(newMemoryCache(10, time.Minute), remote, publish),
+		newTwoTierCache(newMemoryCache(10, time.Minute), remote, publish))
+	return caches[0], caches[1], remote
+}
+
+func TestTwoTierCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	writer, reader, remote := newTestTwoTierCaches()
+
+	require.NoError(t, writer.Set(ctx, AccountKeyByIndex(1), &testValue{Index: 1, Name: "a"}))
+	value := &testValue{}
+	_, err := reader.Get(ctx, AccountKeyByIndex(1), value)
+	require.NoError(t, err)
+	assert.Equal(t, "a", value.Name)
+
+	// the reader uses its local value until the key is changed
+	require.NoError(t, remote.Set(ctx, AccountKeyByIndex(1), &testValue{Index: 1, Name: "stale"}))
+	_, err = reader.Get(ctx, AccountKeyByIndex(1), value)
+	require.NoError(t, err)
+	assert.Equal(t, "a", value.Name)
+
+	require.NoError(t, writer.Set(ctx, AccountKeyByIndex(1), &testValue{Index: 1, Name: "b"}))
+	_, err = reader.Get(ctx, AccountKeyByIndex(1), value)
+	require.NoError(t, err)
+	assert.Equal(t, "b", value.Name)
+
+	require.NoError(t, writer.Delete(ctx, AccountKeyByIndex(1)))
+	_, err = reader.Get(ctx, AccountKeyByIndex(1), value)
+	assert.Error(t, err)
+}
+
+func TestTwoTierCacheConcurrentInvalidation(t *testing.T) {
+	ctx := context.Background()
+	_, reader, remote := newTestTwoTierCaches()
+	require.NoError(t, remote.Set(ctx, NftKeyByIndex(1), &testValue{Name: "old"}))
+
+	// an invalidation arrives while the old value is read from redis
+	generation := reader.generation
+	reader.onInvalidation("other " + NftKeyByIndex(1))
+	reader.setLocal(ctx, NftKeyByIndex(1), &testValue{Name: "old"}, generation)
+
+	require.NoError(t, remote.Set(ctx, NftKeyByIndex(1), &testValue{Name: "new"}))
+	value := &testValue{}
+	_, err := reader.Get(ctx, NftKeyByIndex(1), value)
+	require.NoError(t, err)
+	assert.Equal(t, "new", value.Name)
+}
+
+func TestTwoTierCacheGetWithSet(t *testing.T) {
+	ctx := context.Background()
+	cache, _, remote := newTestTwoTierCaches()
+
+	queries := 0
+	query := func() (interface{}, error) {
+		queries++
+		return &testValue{Index: 3}, nil
+	}
+	remoteHits, remoteMisses := testutil.ToFloat64(remoteHitMetric), testutil.ToFloat64(remoteMissMetric)
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetWithSet(ctx, GasConfigKey, &testValue{}, query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), value.(*testValue).Index)
+	}
+	assert.Equal(t, 1, queries)
+	// the second get is served by the local cache
+	assert.Equal(t, remoteHits, testutil.ToFloat64(remoteHitMetric))
+	assert.Equal(t, remoteMisses+1, testutil.ToFloat64(remoteMissMetric))
+
+	// another process finds the value in redis
+	other := newTwoTierCache(newMemoryCache(10, time.Minute), remote, cache.publish)
+	value, err := other.GetWithSet(ctx, GasConfigKey, &testValue{}, query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value.(*testValue).Index)
+	assert.Equal(t, 1, queries)
+	assert.Equal(t, remoteHits+1, testutil.ToFloat64(remoteHitMetric))
+	assert.Equal(t, remoteMisses+1, testutil.ToFloat64(remoteMissMetric))
+
+	stored := &testValue{}
+	_, err = remote.Get(ctx, GasConfigKey, stored)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stored.Index)
+}
@@ -28,8 +28,9 @@ import (
 const (
 	TableName = "l1_rollup_tx"
 
-	StatusPending = 1
-	StatusHandled = 2
+	StatusPending  = 1
+	StatusHandled  = 2
+	StatusReplaced = 3
 
 	TxTypeCommit           = 1
 	TxTypeVerifyAndExecute = 2
@@ -43,9 +44,14 @@ type (
 		GetLatestHandledTx(txType int64) (tx *L1RollupTx, err error)
 		GetLatestPendingTx(txType int64) (tx *L1RollupTx, err error)
 		GetL1RollupTxsByStatus(txStatus int) (txs []*L1RollupTx, err error)
+		GetL1RollupTxsByTypeAndStatus(txType uint8, txStatus int) (txs []*L1RollupTx, err error)
 		GetL1RollupTxsByHash(hash string) (txs []*L1RollupTx, err error)
+		GetL1RollupTxsByNonce(txType uint8, nonce int64) (txs []*L1RollupTx, err error)
 		DeleteL1RollupTx(tx *L1RollupTx) error
+		ReplaceL1RollupTx(replaced *L1RollupTx, tx *L1RollupTx) error
 		UpdateL1RollupTxsInTransact(tx *gorm.DB, txs []*L1RollupTx) error
+		DeleteL1RollupTxsInTransact(tx *gorm.DB, txs []*L1RollupTx) error
+		TruncateL1RollupTxsInTransact(tx *gorm.DB, txType uint8, height int64) error
 	}
 
 	defaultL1RollupTxModel struct {
@@ -57,12 +63,23 @@ type (
 		gorm.Model
 		// txVerification hash
 		L1TxHash string
-		// txVerification status, 1 - pending, 2 - handled
+		// txVerification status, 1 - pending, 2 - handled, 3 - replaced
 		TxStatus int
 		// txVerification type: commit / verify
 		TxType uint8
 		// layer-2 block height
 		L2BlockHeight int64
+		// first layer-2 block height covered by the tx
+		L2StartBlockHeight int64
+		// layer-1 nonce, shared by a tx and all of its replacements
+		Nonce int64
+		// gas price in wei of legacy txs
+		GasPrice string
+		// fee cap and tip cap in wei of dynamic fee txs
+		GasFeeCap string
+		GasTipCap string
+		// hash of the stuck tx replaced by this one
+		ReplacedTxHash string
 	}
 )
 
@@ -105,6 +122,16 @@ func (m *defaultL1RollupTxModel) GetL1RollupTxsByStatus(txStatus int) (txs []*L1
 	return txs, nil
 }
 
+func (m *defaultL1RollupTxModel) GetL1RollupTxsByTypeAndStatus(txType uint8, txStatus int) (txs []*L1RollupTx, err error) {
+	dbTx := m.DB.Table(m.table).Where("tx_type = ? AND tx_status = ?", txType, txStatus).Order("l2_block_height").Find(&txs)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txs, nil
+}
+
 func (m *defaultL1RollupTxModel) GetL1RollupTxsByHash(hash string) (txs []*L1RollupTx, err error) {
 	dbTx := m.DB.Table(m.table).Where("l1_tx_hash = ?", hash).Find(&txs)
 	if dbTx.Error != nil {
@@ -115,6 +142,36 @@ func (m *defaultL1RollupTxModel) GetL1RollupTxsByHash(hash string) (txs []*L1Rol
 	return txs, nil
 }
 
+func (m *defaultL1RollupTxModel) GetL1RollupTxsByNonce(txType uint8, nonce int64) (txs []*L1RollupTx, err error) {
+	dbTx := m.DB.Table(m.table).Where("tx_type = ? AND nonce = ?", txType, nonce).Order("id").Find(&txs)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txs, nil
+}
+
+func (m *defaultL1RollupTxModel) ReplaceL1RollupTx(replaced *L1RollupTx, rollupTx *L1RollupTx) error {
+	return m.DB.Transaction(func(tx *gorm.DB) error {
+		dbTx := tx.Table(m.table).Where("id = ? AND tx_status = ?", replaced.ID, StatusPending).
+			Update("tx_status", StatusReplaced)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+		if dbTx.RowsAffected == 0 {
+			return fmt.Errorf("invalid rollup tx: %d", replaced.ID)
+		}
+		dbTx = tx.Table(m.table).Create(rollupTx)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		} else if dbTx.RowsAffected == 0 {
+			return types.DbErrFailToCreateL1RollupTx
+		}
+		return nil
+	})
+}
+
 func (m *defaultL1RollupTxModel) DeleteL1RollupTx(rollupTx *L1RollupTx) error {
 	return m.DB.Transaction(func(tx *gorm.DB) error {
 		dbTx := tx.Table(m.table).Where("id = ?", rollupTx.ID).Delete(&rollupTx)
@@ -143,7 +200,7 @@ func (m *defaultL1RollupTxModel) GetLatestHandledTx(txType int64) (tx *L1RollupT
 func (m *defaultL1RollupTxModel) GetLatestPendingTx(txType int64) (tx *L1RollupTx, err error) {
 	tx = &L1RollupTx{}
 
-	dbTx := m.DB.Table(m.table).Where("tx_type = ? AND tx_status = ?", txType, StatusPending).Find(&tx)
+	dbTx := m.DB.Table(m.table).Where("tx_type = ? AND tx_status = ?", txType, StatusPending).Order("l2_block_height desc").Find(&tx)
 	if dbTx.Error != nil {
 		return nil, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -166,3 +223,31 @@ func (m *defaultL1RollupTxModel) UpdateL1RollupTxsInTransact(tx *gorm.DB, txs []
 	}
 	return nil
 }
+
+func (m *defaultL1RollupTxModel) DeleteL1RollupTxsInTransact(tx *gorm.DB, txs []*L1RollupTx) error {
+	for _, pendingDeleteTx := range txs {
+		dbTx := tx.Table(m.table).Where("id = ?", pendingDeleteTx.ID).Delete(&pendingDeleteTx)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+		if dbTx.RowsAffected == 0 {
+			return types.DbErrFailToDeleteL1RollupTx
+		}
+	}
+	return nil
+}
+
+// TruncateL1RollupTxsInTransact drops the rollup txs of the type which only
+// cover blocks after height, and cuts the ones spanning height down to it.
+func (m *defaultL1RollupTxModel) TruncateL1RollupTxsInTransact(tx *gorm.DB, txType uint8, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().
+		Where("tx_type = ? AND l2_block_height > ? AND (l2_start_block_height > ? OR l2_start_block_height = 0)", txType, height, height).
+		Delete(&L1RollupTx{})
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	dbTx = tx.Table(m.table).Unscoped().
+		Where("tx_type = ? AND l2_block_height > ?", txType, height).
+		Update("l2_block_height", height)
+	return dbTx.Error
+}
@@ -34,8 +34,11 @@ type (
 		GetNft(nftIndex int64) (nftAsset *L2Nft, err error)
 		GetLatestNftIndex() (nftIndex int64, err error)
 		GetNftsByAccountIndex(accountIndex, limit, offset int64) (nfts []*L2Nft, err error)
+		GetNftsByAccountIndexBefore(accountIndex, nftIndex, limit int64) (nfts []*L2Nft, err error)
 		GetNftsCountByAccountIndex(accountIndex int64) (int64, error)
+		CreateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error
 		UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error
+		DeleteNftsInTransact(tx *gorm.DB, nftIndexes []int64) error
 	}
 	defaultL2NftModel struct {
 		table string
@@ -106,6 +109,19 @@ func (m *defaultL2NftModel) GetNftsByAccountIndex(accountIndex, limit, offset in
 	return nftList, nil
 }
 
+// GetNftsByAccountIndexBefore returns the nfts of the account with a smaller nft
+// index, which is the next page of GetNftsByAccountIndex without an offset.
+func (m *defaultL2NftModel) GetNftsByAccountIndexBefore(accountIndex, nftIndex, limit int64) (nftList []*L2Nft, err error) {
+	dbTx := m.DB.Table(m.table).Where("owner_account_index = ? and nft_index < ? and deleted_at is NULL", accountIndex, nftIndex).
+		Limit(int(limit)).Order("nft_index desc").Find(&nftList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nftList, nil
+}
+
 func (m *defaultL2NftModel) GetNftsCountByAccountIndex(accountIndex int64) (int64, error) {
 	var count int64
 	dbTx := m.DB.Table(m.table).Where("owner_account_index = ? and deleted_at is NULL", accountIndex).Count(&count)
@@ -117,6 +133,17 @@ func (m *defaultL2NftModel) GetNftsCountByAccountIndex(accountIndex int64) (int6
 	return count, nil
 }
 
+func (m *defaultL2NftModel) CreateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error {
+	dbTx := tx.Table(m.table).CreateInBatches(nfts, len(nfts))
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	if dbTx.RowsAffected != int64(len(nfts)) {
+		return types.DbErrFailToCreateNft
+	}
+	return nil
+}
+
 func (m *defaultL2NftModel) UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) error {
 	for _, pendingNft := range nfts {
 		dbTx := tx.Table(m.table).Where("nft_index = ?", pendingNft.NftIndex).
@@ -134,3 +161,11 @@ func (m *defaultL2NftModel) UpdateNftsInTransact(tx *gorm.DB, nfts []*L2Nft) err
 	}
 	return nil
 }
+
+func (m *defaultL2NftModel) DeleteNftsInTransact(tx *gorm.DB, nftIndexes []int64) error {
+	if len(nftIndexes) == 0 {
+		return nil
+	}
+	dbTx := tx.Table(m.table).Unscoped().Where("nft_index IN ?", nftIndexes).Delete(&L2Nft{})
+	return dbTx.Error
+}
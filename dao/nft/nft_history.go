@@ -36,6 +36,12 @@ type (
 			rowsAffected int64, nftAssets []*L2NftHistory, err error,
 		)
 		CreateNftHistoriesInTransact(tx *gorm.DB, histories []*L2NftHistory) error
+		GetLatestNftHistory(nftIndex, height int64) (nftHistory *L2NftHistory, err error)
+		GetLatestNftsCountByAccountIndex(accountIndex, height int64) (count int64, err error)
+		GetLatestNftsByAccountIndex(accountIndex, height, limit, offset int64) (nftList []*L2NftHistory, err error)
+		GetLatestNftsByAccountIndexBefore(accountIndex, height, nftIndex, limit int64) (nftList []*L2NftHistory, err error)
+		GetNftIndexesAfterHeight(height int64) (nftIndexes []int64, err error)
+		DeleteNftHistoriesAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 	defaultL2NftHistoryModel struct {
 		table string
@@ -119,3 +125,63 @@ func (m *defaultL2NftHistoryModel) CreateNftHistoriesInTransact(tx *gorm.DB, his
 	}
 	return nil
 }
+
+func (m *defaultL2NftHistoryModel) GetLatestNftHistory(nftIndex, height int64) (nftHistory *L2NftHistory, err error) {
+	dbTx := m.DB.Table(m.table).Where("nft_index = ? and l2_block_height < ?", nftIndex, height).Order("l2_block_height desc").Limit(1).Find(&nftHistory)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nftHistory, nil
+}
+
+// latestNftsOfAccount selects the nfts owned by the account at the height, the
+// withdrawn nfts are emptied and not owned by anyone.
+func (m *defaultL2NftHistoryModel) latestNftsOfAccount(accountIndex, height int64) *gorm.DB {
+	subQuery := m.DB.Table(m.table).Select("*").
+		Where("nft_index = a.nft_index AND l2_block_height <= ? AND l2_block_height > a.l2_block_height", height)
+
+	return m.DB.Table(m.table+" as a").
+		Where("NOT EXISTS (?) AND l2_block_height <= ? AND owner_account_index = ? AND nft_content_hash != ?",
+			subQuery, height, accountIndex, types.EmptyNftContentHash)
+}
+
+func (m *defaultL2NftHistoryModel) GetLatestNftsCountByAccountIndex(accountIndex, height int64) (count int64, err error) {
+	if m.latestNftsOfAccount(accountIndex, height).Count(&count).Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
+func (m *defaultL2NftHistoryModel) GetLatestNftsByAccountIndex(accountIndex, height, limit, offset int64) (nftList []*L2NftHistory, err error) {
+	dbTx := m.latestNftsOfAccount(accountIndex, height).Select("*").
+		Limit(int(limit)).Offset(int(offset)).Order("nft_index desc").Find(&nftList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return nftList, nil
+}
+
+func (m *defaultL2NftHistoryModel) GetLatestNftsByAccountIndexBefore(accountIndex, height, nftIndex, limit int64) (nftList []*L2NftHistory, err error) {
+	dbTx := m.latestNftsOfAccount(accountIndex, height).Select("*").Where("nft_index < ?", nftIndex).
+		Limit(int(limit)).Order("nft_index desc").Find(&nftList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return nftList, nil
+}
+
+func (m *defaultL2NftHistoryModel) GetNftIndexesAfterHeight(height int64) (nftIndexes []int64, err error) {
+	dbTx := m.DB.Table(m.table).Distinct("nft_index").Where("l2_block_height > ?", height).
+		Order("nft_index").Find(&nftIndexes)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return nftIndexes, nil
+}
+
+func (m *defaultL2NftHistoryModel) DeleteNftHistoriesAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("l2_block_height > ?", height).Delete(&L2NftHistory{})
+	return dbTx.Error
+}
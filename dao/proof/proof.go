@@ -44,6 +44,7 @@ type (
 		GetLatestConfirmedProof() (p *Proof, err error)
 		GetProofByBlockHeight(height int64) (p *Proof, err error)
 		UpdateProofsInTransact(tx *gorm.DB, m map[int64]int) error
+		DeleteProofsAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultProofModel struct {
@@ -164,3 +165,8 @@ func (m *defaultProofModel) UpdateProofsInTransact(tx *gorm.DB, proofs map[int64
 	}
 	return nil
 }
+
+func (m *defaultProofModel) DeleteProofsAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	dbTx := tx.Table(m.table).Unscoped().Where("block_number > ?", height).Delete(&Proof{})
+	return dbTx.Error
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package stats
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+)
+
+// storedGranularities are the granularities of the rollups kept in the tables.
+var storedGranularities = []string{GranularityHour, GranularityDay}
+
+type metricKey struct {
+	metric string
+	key    int64
+}
+
+// RollupTxs sums up the txs of a block into the hourly and daily rollups and
+// active accounts of the block time, multiplied by the sign.
+func RollupTxs(blockTime time.Time, txs []*tx.Tx, sign int64) ([]*Rollup, []*ActiveAccount) {
+	values := make(map[metricKey]*big.Int)
+	add := func(metric string, key int64, value *big.Int) {
+		k := metricKey{metric: metric, key: key}
+		if _, ok := values[k]; !ok {
+			values[k] = big.NewInt(0)
+		}
+		values[k].Add(values[k], value)
+	}
+	txCounts := make(map[int64]int64)
+	for _, t := range txs {
+		add(MetricTxCount, t.TxType, big.NewInt(1))
+		if amount, ok := positiveAmount(t.TxAmount); ok && t.AssetId >= 0 {
+			add(MetricVolume, t.AssetId, amount)
+		}
+		if gasFee, ok := positiveAmount(t.GasFee); ok && t.GasFeeAssetId >= 0 {
+			add(MetricGasFee, t.GasFeeAssetId, gasFee)
+		}
+		if t.AccountIndex >= 0 {
+			txCounts[t.AccountIndex]++
+		}
+	}
+
+	keys := make([]metricKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].metric != keys[j].metric {
+			return keys[i].metric < keys[j].metric
+		}
+		return keys[i].key < keys[j].key
+	})
+	accountIndexes := make([]int64, 0, len(txCounts))
+	for accountIndex := range txCounts {
+		accountIndexes = append(accountIndexes, accountIndex)
+	}
+	sort.Slice(accountIndexes, func(i, j int) bool {
+		return accountIndexes[i] < accountIndexes[j]
+	})
+
+	rollups := make([]*Rollup, 0, len(storedGranularities)*len(keys))
+	activeAccounts := make([]*ActiveAccount, 0, len(storedGranularities)*len(accountIndexes))
+	for _, granularity := range storedGranularities {
+		periodStart := PeriodStart(granularity, blockTime)
+		for _, k := range keys {
+			rollups = append(rollups, &Rollup{
+				Granularity: granularity,
+				PeriodStart: periodStart,
+				Metric:      k.metric,
+				Key:         k.key,
+				Value:       new(big.Int).Mul(values[k], big.NewInt(sign)).String(),
+			})
+		}
+		for _, accountIndex := range accountIndexes {
+			activeAccounts = append(activeAccounts, &ActiveAccount{
+				Granularity:  granularity,
+				PeriodStart:  periodStart,
+				AccountIndex: accountIndex,
+				TxCount:      txCounts[accountIndex] * sign,
+			})
+		}
+	}
+	return rollups, activeAccounts
+}
+
+// PeriodStart returns the start of the period of the granularity containing t
+// in UTC, weeks start on Monday.
+func PeriodStart(granularity string, t time.Time) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case GranularityHour:
+		return t.Truncate(time.Hour)
+	case GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPeriodStart returns the start of the period following the one starting
+// at periodStart.
+func NextPeriodStart(granularity string, periodStart time.Time) time.Time {
+	switch granularity {
+	case GranularityHour:
+		return periodStart.Add(time.Hour)
+	case GranularityWeek:
+		return periodStart.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return periodStart.AddDate(0, 1, 0)
+	default:
+		return periodStart.AddDate(0, 0, 1)
+	}
+}
+
+func positiveAmount(amount string) (*big.Int, bool) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, false
+	}
+	return value, true
+}
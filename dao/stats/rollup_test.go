@@ -0,0 +1,91 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestRollupTxs(t *testing.T) {
+	blockTime := time.Date(2022, 9, 7, 15, 30, 0, 0, time.UTC)
+	txs := []*tx.Tx{
+		{TxType: types.TxTypeTransfer, AccountIndex: 2, AssetId: 0, TxAmount: "100", GasFeeAssetId: 1, GasFee: "5"},
+		{TxType: types.TxTypeTransfer, AccountIndex: 3, AssetId: 0, TxAmount: "50", GasFeeAssetId: 1, GasFee: "5"},
+		{TxType: types.TxTypeMintNft, AccountIndex: 2, AssetId: types.NilAssetId, TxAmount: types.NilAssetAmount, GasFeeAssetId: 0, GasFee: "1"},
+		{TxType: types.TxTypeDeposit, AccountIndex: 4, AssetId: 1, TxAmount: "7", GasFeeAssetId: types.NilAssetId, GasFee: types.NilAssetAmount},
+	}
+
+	rollups, activeAccounts := RollupTxs(blockTime, txs, 1)
+
+	hour := time.Date(2022, 9, 7, 15, 0, 0, 0, time.UTC)
+	values := make(map[metricKey]string)
+	for _, rollup := range rollups {
+		if rollup.Granularity == GranularityHour {
+			assert.Equal(t, hour, rollup.PeriodStart)
+			values[metricKey{rollup.Metric, rollup.Key}] = rollup.Value
+		}
+	}
+	assert.Equal(t, map[metricKey]string{
+		{MetricTxCount, types.TxTypeTransfer}: "2",
+		{MetricTxCount, types.TxTypeMintNft}:  "1",
+		{MetricTxCount, types.TxTypeDeposit}:  "1",
+		{MetricVolume, 0}:                     "150",
+		{MetricVolume, 1}:                     "7",
+		{MetricGasFee, 0}:                     "1",
+		{MetricGasFee, 1}:                     "10",
+	}, values)
+	assert.Equal(t, 2*len(values), len(rollups))
+
+	// the daily active accounts follow the hourly ones
+	assert.Equal(t, 6, len(activeAccounts))
+	assert.Equal(t, int64(2), activeAccounts[0].AccountIndex)
+	assert.Equal(t, int64(2), activeAccounts[0].TxCount)
+	assert.Equal(t, time.Date(2022, 9, 7, 0, 0, 0, 0, time.UTC), activeAccounts[3].PeriodStart)
+
+	rollups, activeAccounts = RollupTxs(blockTime, txs, -1)
+	for _, rollup := range rollups {
+		assert.Equal(t, "-", rollup.Value[:1])
+	}
+	for _, activeAccount := range activeAccounts {
+		assert.True(t, activeAccount.TxCount < 0)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	// a Wednesday
+	at := time.Date(2022, 9, 7, 15, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+
+	assert.Equal(t, time.Date(2022, 9, 7, 7, 0, 0, 0, time.UTC), PeriodStart(GranularityHour, at))
+	assert.Equal(t, time.Date(2022, 9, 7, 0, 0, 0, 0, time.UTC), PeriodStart(GranularityDay, at))
+	assert.Equal(t, time.Date(2022, 9, 5, 0, 0, 0, 0, time.UTC), PeriodStart(GranularityWeek, at))
+	assert.Equal(t, time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), PeriodStart(GranularityMonth, at))
+
+	sunday := time.Date(2022, 9, 11, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 9, 5, 0, 0, 0, 0, time.UTC), PeriodStart(GranularityWeek, sunday))
+
+	assert.Equal(t, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		NextPeriodStart(GranularityMonth, PeriodStart(GranularityMonth, at)))
+	assert.Equal(t, time.Date(2022, 9, 12, 0, 0, 0, 0, time.UTC),
+		NextPeriodStart(GranularityWeek, PeriodStart(GranularityWeek, sunday)))
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	RollupTableName        = `stat_rollup`
+	ActiveAccountTableName = `stat_active_account`
+)
+
+// Granularities of the stats, the hourly and daily rollups are stored while
+// the weekly and monthly ones are summed up from the daily rollups.
+const (
+	GranularityHour  = "hour"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// Metrics of the rollups, the key is the tx type for the tx count and the
+// asset id for the others.
+const (
+	MetricTxCount = "tx_count"
+	MetricVolume  = "volume"
+	MetricGasFee  = "gas_fee"
+)
+
+type (
+	StatModel interface {
+		CreateStatTables() error
+		DropStatTables() error
+		AddTxsInTransact(tx *gorm.DB, blockTime time.Time, txs []*tx.Tx) error
+		SubtractTxsInTransact(tx *gorm.DB, blockTime time.Time, txs []*tx.Tx) error
+		GetRollups(granularity string, from, to time.Time) (rollups []*Rollup, err error)
+		GetActiveAccountCounts(granularity string, from, to time.Time) (counts []*ActiveAccountCount, err error)
+	}
+
+	defaultStatModel struct {
+		rollupTable        string
+		activeAccountTable string
+		DB                 *gorm.DB
+	}
+
+	// Rollup is the sum of a metric of the txs committed in a period, the
+	// value is a decimal integer.
+	Rollup struct {
+		gorm.Model
+		Granularity string    `gorm:"uniqueIndex:idx_stat_rollup"`
+		PeriodStart time.Time `gorm:"uniqueIndex:idx_stat_rollup"`
+		Metric      string    `gorm:"uniqueIndex:idx_stat_rollup"`
+		Key         int64     `gorm:"uniqueIndex:idx_stat_rollup"`
+		Value       string    `gorm:"type:numeric"`
+	}
+
+	// ActiveAccount is an account which sent txs committed in a period.
+	ActiveAccount struct {
+		gorm.Model
+		Granularity  string    `gorm:"uniqueIndex:idx_stat_active_account"`
+		PeriodStart  time.Time `gorm:"uniqueIndex:idx_stat_active_account"`
+		AccountIndex int64     `gorm:"uniqueIndex:idx_stat_active_account"`
+		TxCount      int64
+	}
+
+	ActiveAccountCount struct {
+		PeriodStart time.Time
+		Count       int64
+	}
+)
+
+func NewStatModel(db *gorm.DB) StatModel {
+	return &defaultStatModel{
+		rollupTable:        RollupTableName,
+		activeAccountTable: ActiveAccountTableName,
+		DB:                 db,
+	}
+}
+
+func (*Rollup) TableName() string {
+	return RollupTableName
+}
+
+func (*ActiveAccount) TableName() string {
+	return ActiveAccountTableName
+}
+
+func (m *defaultStatModel) CreateStatTables() error {
+	return m.DB.AutoMigrate(Rollup{}, ActiveAccount{})
+}
+
+func (m *defaultStatModel) DropStatTables() error {
+	return m.DB.Migrator().DropTable(m.rollupTable, m.activeAccountTable)
+}
+
+// AddTxsInTransact adds the txs of a block to the rollups of the periods of
+// the block time, it is called when the block is committed.
+func (m *defaultStatModel) AddTxsInTransact(tx *gorm.DB, blockTime time.Time, txs []*tx.Tx) error {
+	return m.applyTxsInTransact(tx, blockTime, txs, 1)
+}
+
+// SubtractTxsInTransact reverts AddTxsInTransact when the block is rolled back.
+func (m *defaultStatModel) SubtractTxsInTransact(tx *gorm.DB, blockTime time.Time, txs []*tx.Tx) error {
+	return m.applyTxsInTransact(tx, blockTime, txs, -1)
+}
+
+func (m *defaultStatModel) applyTxsInTransact(tx *gorm.DB, blockTime time.Time, txs []*tx.Tx, sign int64) error {
+	rollups, activeAccounts := RollupTxs(blockTime, txs, sign)
+	if len(rollups) > 0 {
+		dbTx := tx.Table(m.rollupTable).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "granularity"}, {Name: "period_start"}, {Name: "metric"}, {Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"value":      gorm.Expr(m.rollupTable + ".value + excluded.value"),
+				"updated_at": gorm.Expr("excluded.updated_at"),
+			}),
+		}).Create(&rollups)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+	}
+	if len(activeAccounts) > 0 {
+		dbTx := tx.Table(m.activeAccountTable).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "granularity"}, {Name: "period_start"}, {Name: "account_index"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"tx_count":   gorm.Expr(m.activeAccountTable + ".tx_count + excluded.tx_count"),
+				"updated_at": gorm.Expr("excluded.updated_at"),
+			}),
+		}).Create(&activeAccounts)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+	}
+	if sign < 0 {
+		dbTx := tx.Table(m.activeAccountTable).Unscoped().Where("tx_count <= 0").Delete(&ActiveAccount{})
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+	}
+	return nil
+}
+
+// GetRollups returns the rollups of the periods starting in [from, to), the
+// period start of the weekly and monthly rollups is truncated in UTC.
+func (m *defaultStatModel) GetRollups(granularity string, from, to time.Time) (rollups []*Rollup, err error) {
+	source, period, err := periodOf(granularity)
+	if err != nil {
+		return nil, err
+	}
+	dbTx := m.DB.Table(m.rollupTable).
+		Select(period+" AS period_start, metric, key, SUM(value)::text AS value").
+		Where("granularity = ? AND period_start >= ? AND period_start < ? AND deleted_at IS NULL", source, from, to).
+		Group(period + ", metric, key").Order("period_start, metric, key").Scan(&rollups)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return rollups, nil
+}
+
+// GetActiveAccountCounts returns the number of active accounts of the periods
+// starting in [from, to).
+func (m *defaultStatModel) GetActiveAccountCounts(granularity string, from, to time.Time) (counts []*ActiveAccountCount, err error) {
+	source, period, err := periodOf(granularity)
+	if err != nil {
+		return nil, err
+	}
+	dbTx := m.DB.Table(m.activeAccountTable).
+		Select(period+" AS period_start, COUNT(DISTINCT account_index) AS count").
+		Where("granularity = ? AND period_start >= ? AND period_start < ? AND deleted_at IS NULL", source, from, to).
+		Group(period).Order("period_start").Scan(&counts)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return counts, nil
+}
+
+// periodOf returns the stored granularity the stats are summed up from, and
+// the sql expression of the period start.
+func periodOf(granularity string) (source string, period string, err error) {
+	switch granularity {
+	case GranularityHour:
+		source = GranularityHour
+	case GranularityDay, GranularityWeek, GranularityMonth:
+		source = GranularityDay
+	default:
+		return "", "", fmt.Errorf("unknown granularity %s", granularity)
+	}
+	return source, fmt.Sprintf("date_trunc('%s', period_start AT TIME ZONE 'UTC')", granularity), nil
+}
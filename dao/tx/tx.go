@@ -36,6 +36,16 @@ const (
 	StatusPacked
 	StatusCommitted
 	StatusVerified
+	// StatusReverted marks txs of blocks reverted by the rollup contract.
+	StatusReverted
+)
+
+// Directions of the txs of an account, outgoing txs are sent by the account
+// while incoming txs are sent by other accounts and change the account.
+const (
+	DirectionOutgoing = "outgoing"
+	DirectionIncoming = "incoming"
+	DirectionAll      = "all"
 )
 
 type getTxOption struct {
@@ -43,6 +53,19 @@ type getTxOption struct {
 	Statuses    []int64
 	FromHash    string
 	WithDeleted bool
+
+	// The key of the last tx of the previous page.
+	BeforeCreatedAt time.Time
+	BeforeId        uint
+
+	// Filters of the txs of an account.
+	Direction    string
+	AssetId      *int64
+	Counterparty *int64
+	FromTime     time.Time
+	ToTime       time.Time
+	FromHeight   *int64
+	ToHeight     *int64
 }
 
 type GetTxOptionFunc func(*getTxOption)
@@ -71,6 +94,58 @@ func GetTxWithDeleted() GetTxOptionFunc {
 	}
 }
 
+// GetTxWithBefore returns the txs listed after the given one by created_at
+// desc and id desc, which are the next page of a cursor.
+func GetTxWithBefore(createdAt time.Time, id uint) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.BeforeCreatedAt = createdAt
+		o.BeforeId = id
+	}
+}
+
+func GetTxWithDirection(direction string) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.Direction = direction
+	}
+}
+
+// GetTxWithAssetId filters the txs which change the balance of the asset of
+// the account.
+func GetTxWithAssetId(assetId int64) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.AssetId = &assetId
+	}
+}
+
+// GetTxWithCounterparty filters the txs which are sent by or change the
+// counterparty account.
+func GetTxWithCounterparty(accountIndex int64) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.Counterparty = &accountIndex
+	}
+}
+
+// GetTxWithTimeRange filters the txs created between from and to, a zero
+// time leaves the range open.
+func GetTxWithTimeRange(from, to time.Time) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.FromTime = from
+		o.ToTime = to
+	}
+}
+
+func GetTxWithFromHeight(height int64) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.FromHeight = &height
+	}
+}
+
+func GetTxWithToHeight(height int64) GetTxOptionFunc {
+	return func(o *getTxOption) {
+		o.ToHeight = &height
+	}
+}
+
 type (
 	TxModel interface {
 		CreateTxTable() error
@@ -79,10 +154,12 @@ type (
 		GetTxs(limit int64, offset int64, options ...GetTxOptionFunc) (txList []*Tx, err error)
 		GetTxsByAccountIndex(accountIndex int64, limit int64, offset int64, options ...GetTxOptionFunc) (txList []*Tx, err error)
 		GetTxsCountByAccountIndex(accountIndex int64, options ...GetTxOptionFunc) (count int64, err error)
+		GetTxsWithDetailsByAccountIndex(accountIndex int64, fromId uint, limit int64, options ...GetTxOptionFunc) (txList []*Tx, err error)
 		GetTxByHash(txHash string) (tx *Tx, err error)
 		GetTxsTotalCountBetween(from, to time.Time) (count int64, err error)
 		GetDistinctAccountsCountBetween(from, to time.Time) (count int64, err error)
 		UpdateTxsStatusInTransact(tx *gorm.DB, blockTxStatus map[int64]int) error
+		DeleteTxsAfterHeightInTransact(tx *gorm.DB, height int64) error
 	}
 
 	defaultTxModel struct {
@@ -170,8 +247,12 @@ func (m *defaultTxModel) GetTxs(limit int64, offset int64, options ...GetTxOptio
 	if len(opt.Statuses) > 0 {
 		dbTx = dbTx.Where("tx_status IN ?", opt.Statuses)
 	}
+	if opt.BeforeId > 0 {
+		dbTx = dbTx.Where("(created_at < ? OR (created_at = ? AND id < ?))",
+			opt.BeforeCreatedAt, opt.BeforeCreatedAt, opt.BeforeId)
+	}
 
-	dbTx = dbTx.Limit(int(limit)).Offset(int(offset)).Order("created_at desc").Find(&txList)
+	dbTx = dbTx.Limit(int(limit)).Offset(int(offset)).Order("created_at desc, id desc").Find(&txList)
 	if dbTx.Error != nil {
 		return nil, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -180,38 +261,78 @@ func (m *defaultTxModel) GetTxs(limit int64, offset int64, options ...GetTxOptio
 	return txList, nil
 }
 
-func (m *defaultTxModel) GetTxsByAccountIndex(accountIndex int64, limit int64, offset int64, options ...GetTxOptionFunc) (txList []*Tx, err error) {
+// accountTxs selects the txs of the account, the txs sent by the account if
+// no direction is set.
+func (m *defaultTxModel) accountTxs(accountIndex int64, options []GetTxOptionFunc) *gorm.DB {
 	opt := &getTxOption{}
 	for _, f := range options {
 		f(opt)
 	}
 
-	dbTx := m.DB.Table(m.table).Where("account_index = ?", accountIndex)
-	if len(opt.Types) > 0 {
-		dbTx = dbTx.Where("tx_type IN ?", opt.Types)
+	detailsOf := func(accountIndex int64) *gorm.DB {
+		return m.DB.Table(TxDetailTableName).Select("1").
+			Where("tx_detail.tx_id = tx.id AND tx_detail.account_index = ? AND tx_detail.deleted_at IS NULL", accountIndex)
 	}
 
-	dbTx = dbTx.Limit(int(limit)).Offset(int(offset)).Order("created_at desc").Find(&txList)
-	if dbTx.Error != nil {
-		return nil, types.DbErrSqlOperation
-	} else if dbTx.RowsAffected == 0 {
-		return nil, types.DbErrNotFound
+	dbTx := m.DB.Table(m.table)
+	switch opt.Direction {
+	case DirectionIncoming:
+		dbTx = dbTx.Where("tx.account_index != ? AND EXISTS (?)", accountIndex, detailsOf(accountIndex))
+	case DirectionAll:
+		dbTx = dbTx.Where("(tx.account_index = ? OR EXISTS (?))", accountIndex, detailsOf(accountIndex))
+	default:
+		dbTx = dbTx.Where("tx.account_index = ?", accountIndex)
 	}
-	return txList, nil
+	if len(opt.Types) > 0 {
+		dbTx = dbTx.Where("tx.tx_type IN ?", opt.Types)
+	}
+	if len(opt.Statuses) > 0 {
+		dbTx = dbTx.Where("tx.tx_status IN ?", opt.Statuses)
+	}
+	if opt.AssetId != nil {
+		dbTx = dbTx.Where("EXISTS (?)", detailsOf(accountIndex).
+			Where("tx_detail.asset_type = ? AND tx_detail.asset_id = ?", types.FungibleAssetType, *opt.AssetId))
+	}
+	if opt.Counterparty != nil {
+		dbTx = dbTx.Where("(tx.account_index = ? OR EXISTS (?))", *opt.Counterparty, detailsOf(*opt.Counterparty))
+	}
+	if !opt.FromTime.IsZero() {
+		dbTx = dbTx.Where("tx.created_at >= ?", opt.FromTime)
+	}
+	if !opt.ToTime.IsZero() {
+		dbTx = dbTx.Where("tx.created_at <= ?", opt.ToTime)
+	}
+	if opt.FromHeight != nil {
+		dbTx = dbTx.Where("tx.block_height >= ?", *opt.FromHeight)
+	}
+	if opt.ToHeight != nil {
+		dbTx = dbTx.Where("tx.block_height <= ?", *opt.ToHeight)
+	}
+	return dbTx
 }
 
-func (m *defaultTxModel) GetTxsCountByAccountIndex(accountIndex int64, options ...GetTxOptionFunc) (count int64, err error) {
+func (m *defaultTxModel) GetTxsByAccountIndex(accountIndex int64, limit int64, offset int64, options ...GetTxOptionFunc) (txList []*Tx, err error) {
 	opt := &getTxOption{}
 	for _, f := range options {
 		f(opt)
 	}
 
-	dbTx := m.DB.Table(m.table).Where("account_index = ?", accountIndex)
-	if len(opt.Types) > 0 {
-		dbTx = dbTx.Where("tx_type IN ?", opt.Types)
+	dbTx := m.accountTxs(accountIndex, options)
+	if opt.BeforeId > 0 {
+		dbTx = dbTx.Where("(tx.created_at < ? OR (tx.created_at = ? AND tx.id < ?))",
+			opt.BeforeCreatedAt, opt.BeforeCreatedAt, opt.BeforeId)
 	}
+	dbTx = dbTx.Limit(int(limit)).Offset(int(offset)).Order("tx.created_at desc, tx.id desc").Find(&txList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txList, nil
+}
 
-	dbTx = dbTx.Count(&count)
+func (m *defaultTxModel) GetTxsCountByAccountIndex(accountIndex int64, options ...GetTxOptionFunc) (count int64, err error) {
+	dbTx := m.accountTxs(accountIndex, options).Count(&count)
 	if dbTx.Error != nil {
 		return 0, types.DbErrSqlOperation
 	} else if dbTx.RowsAffected == 0 {
@@ -220,6 +341,20 @@ func (m *defaultTxModel) GetTxsCountByAccountIndex(accountIndex int64, options .
 	return count, nil
 }
 
+// GetTxsWithDetailsByAccountIndex returns the txs of the account after the id in
+// ascending order, with the tx details of the account.
+func (m *defaultTxModel) GetTxsWithDetailsByAccountIndex(accountIndex int64, fromId uint, limit int64, options ...GetTxOptionFunc) (txList []*Tx, err error) {
+	dbTx := m.accountTxs(accountIndex, options).Where("tx.id > ?", fromId).
+		Preload("TxDetails", "account_index = ?", accountIndex).
+		Limit(int(limit)).Order("tx.id").Find(&txList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txList, nil
+}
+
 func (m *defaultTxModel) GetTxByHash(txHash string) (tx *Tx, err error) {
 	dbTx := m.DB.Table(m.table).Where("tx_hash = ?", txHash).Find(&tx)
 	if dbTx.Error != nil {
@@ -263,3 +398,15 @@ func (m *defaultTxModel) UpdateTxsStatusInTransact(tx *gorm.DB, blockTxStatus ma
 	}
 	return nil
 }
+
+// DeleteTxsAfterHeightInTransact deletes the txs of the blocks after height
+// along with their details.
+func (m *defaultTxModel) DeleteTxsAfterHeightInTransact(tx *gorm.DB, height int64) error {
+	txIds := tx.Table(m.table).Unscoped().Select("id").Where("block_height > ?", height)
+	dbTx := tx.Table(TxDetailTableName).Unscoped().Where("tx_id IN (?)", txIds).Delete(&TxDetail{})
+	if dbTx.Error != nil {
+		return dbTx.Error
+	}
+	dbTx = tx.Table(m.table).Unscoped().Where("block_height > ?", height).Delete(&Tx{})
+	return dbTx.Error
+}
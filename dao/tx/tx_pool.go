@@ -42,6 +42,7 @@ type (
 		UpdateTxsInTransact(tx *gorm.DB, txs []*Tx) error
 		DeleteTxsInTransact(tx *gorm.DB, txs []*Tx) error
 		GetLatestTx(txTypes []int64, statuses []int) (tx *Tx, err error)
+		RequeueTxsAfterHeightInTransact(tx *gorm.DB, height int64) (count int64, err error)
 	}
 
 	defaultTxPoolModel struct {
@@ -93,6 +94,10 @@ func (m *defaultTxPoolModel) GetTxs(limit int64, offset int64, options ...GetTxO
 		subTx = subTx.Select("id").Where("tx_hash = ?", opt.FromHash).Limit(1)
 		dbTx = dbTx.Where("id > (?)", subTx)
 	}
+	if opt.BeforeId > 0 {
+		dbTx = dbTx.Where("(created_at < ? OR (created_at = ? AND id < ?))",
+			opt.BeforeCreatedAt, opt.BeforeCreatedAt, opt.BeforeId)
+	}
 
 	dbTx = dbTx.Limit(int(limit)).Offset(int(offset)).Order("created_at desc, id desc").Find(&txs)
 	if dbTx.Error != nil {
@@ -246,3 +251,22 @@ func (m *defaultTxPoolModel) GetLatestTx(txTypes []int64, statuses []int) (tx *T
 
 	return tx, nil
 }
+
+// RequeueTxsAfterHeightInTransact puts the txs executed in the blocks after
+// height back to the pool as pending txs, so that they are executed again
+// in their original order.
+func (m *defaultTxPoolModel) RequeueTxsAfterHeightInTransact(tx *gorm.DB, height int64) (count int64, err error) {
+	dbTx := tx.Table(m.table).Unscoped().
+		Where("block_height > ? AND tx_status != ?", height, StatusFailed).
+		Updates(map[string]interface{}{
+			"deleted_at":   nil,
+			"tx_status":    StatusPending,
+			"block_height": 0,
+			"block_id":     0,
+			"tx_index":     0,
+		})
+	if dbTx.Error != nil {
+		return 0, dbTx.Error
+	}
+	return dbTx.RowsAffected, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// newTestTxs returns txs of account 1 whose creation order differs from the
+// order of their ids, two of them are created at the same time.
+func newTestTxs() []*Tx {
+	base := time.Date(2022, 9, 7, 15, 30, 0, 0, time.UTC)
+	createdAt := []time.Duration{3, 1, 5, 1, 4, 2, 5}
+	txs := make([]*Tx, 0, len(createdAt))
+	for i, d := range createdAt {
+		tx := &Tx{TxHash: string(rune('a' + i)), AccountIndex: 1, TxStatus: StatusPending}
+		tx.ID = uint(i + 1)
+		tx.CreatedAt = base.Add(d * time.Second)
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// pageTxs lists all the txs by pages of the given size, the pages after the
+// first one start after the last tx of the previous page.
+func pageTxs(t *testing.T, getTxs func(options ...GetTxOptionFunc) ([]*Tx, error)) []string {
+	hashes := make([]string, 0)
+	options := make([]GetTxOptionFunc, 0)
+	for {
+		txs, err := getTxs(options...)
+		if err == types.DbErrNotFound || len(txs) == 0 {
+			return hashes
+		}
+		require.NoError(t, err)
+		for _, tx := range txs {
+			hashes = append(hashes, tx.TxHash)
+		}
+		lastTx := txs[len(txs)-1]
+		options = []GetTxOptionFunc{GetTxWithBefore(lastTx.CreatedAt, lastTx.ID)}
+	}
+}
+
+func TestGetTxsByPages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	txModel := NewTxModel(db)
+	require.NoError(t, txModel.CreateTxTable())
+	require.NoError(t, NewTxDetailModel(db).CreateTxDetailTable())
+	txPoolModel := NewTxPoolModel(db)
+	require.NoError(t, txPoolModel.CreatePoolTxTable())
+
+	require.NoError(t, db.Table(TxTableName).Create(newTestTxs()).Error)
+	require.NoError(t, db.Table(PoolTxTableName).Create(newTestTxs()).Error)
+
+	// newest first, the ties by id desc
+	expected := []string{"g", "c", "e", "a", "f", "d", "b"}
+	all, err := txModel.GetTxs(10, 0)
+	require.NoError(t, err)
+	hashes := make([]string, 0, len(all))
+	for _, tx := range all {
+		hashes = append(hashes, tx.TxHash)
+	}
+	assert.Equal(t, expected, hashes)
+
+	for _, limit := range []int64{1, 2, 3} {
+		assert.Equal(t, expected, pageTxs(t, func(options ...GetTxOptionFunc) ([]*Tx, error) {
+			return txModel.GetTxs(limit, 0, options...)
+		}), "limit %d", limit)
+		assert.Equal(t, expected, pageTxs(t, func(options ...GetTxOptionFunc) ([]*Tx, error) {
+			return txModel.GetTxsByAccountIndex(1, limit, 0, options...)
+		}), "limit %d", limit)
+		assert.Equal(t, expected, pageTxs(t, func(options ...GetTxOptionFunc) ([]*Tx, error) {
+			return txPoolModel.GetTxs(limit, 0, options...)
+		}), "limit %d", limit)
+	}
+}
@@ -12,6 +12,8 @@ KEY_PATH=~/.zkbnb
 ZkBNB_REPO_PATH=$(cd `dirname $0`; pwd)
 CMC_TOKEN=cfce503f-fake-fake-fake-bbab5257dac8
 BSC_TESTNET_PRIVATE_KEY=acbaa26******************************a88367d9
+# Set to true to run the prover without keys, requires a test verifier contract accepting any proof
+MOCK_PROVER=${MOCK_PROVER:-false}
 
 export PATH=$PATH:/usr/local/go/bin:/usr/local/go/bin:/root/go/bin
 echo '0. stop old database/redis and docker run new database/redis'
@@ -110,6 +112,8 @@ KeyPath:
 BlockConfig:
   OptionalBlockSizes: [10]
 
+Mock: ${MOCK_PROVER}
+
 TreeDB:
   Driver: memorydb
   AssetTreeCacheSize: 512000
@@ -32,12 +32,21 @@ const (
 	AssetIdSymbolKeyPrefix     = "IS:" //key for cache: assetId -> assetName
 	AssetByIdKeyPrefix         = "I:"  //key for cache: assetId -> asset
 	AssetBySymbolKeyPrefix     = "S:"  //key for cache: assetSymbol -> asset
-	PriceKeyPrefix             = "p:"  //key for cache: symbol -> price
+	PriceKeyPrefix             = "p:"  //key for cache: symbol:currency -> price quote
 	SysConfigKeyPrefix         = "s:"  //key for cache: configName -> sysconfig
 )
 
 type fallback func() (interface{}, error)
 
+// PriceQuote is the price of a symbol in a quote currency, an empty Source
+// means no source gives a usable price of the symbol.
+type PriceQuote struct {
+	Price     float64
+	Currency  string
+	Source    string
+	UpdatedAt time.Time
+}
+
 type MemCache struct {
 	goCache           *ristretto.Cache
 	accountModel      accdao.AccountModel
@@ -299,18 +308,18 @@ func (m *MemCache) GetAssetSymbolById(assetId int64) (string, error) {
 	return asset.AssetSymbol, nil
 }
 
-func (m *MemCache) GetPriceWithFallback(symbol string, f fallback) (float64, error) {
-	key := fmt.Sprintf("%s%s", PriceKeyPrefix, symbol)
-	price, err := m.getWithSet(key, m.priceExpiration, f)
+func (m *MemCache) GetPriceQuoteWithFallback(symbol, currency string, f fallback) (*PriceQuote, error) {
+	key := fmt.Sprintf("%s%s:%s", PriceKeyPrefix, symbol, currency)
+	quote, err := m.getWithSet(key, m.priceExpiration, f)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return price.(float64), nil
+	return quote.(*PriceQuote), nil
 }
 
-func (m *MemCache) SetPrice(symbol string, price float64) {
-	key := fmt.Sprintf("%s%s", PriceKeyPrefix, symbol)
-	m.goCache.SetWithTTL(key, price, int64(len(key)), m.priceExpiration)
+func (m *MemCache) SetPriceQuote(symbol string, quote *PriceQuote) {
+	key := fmt.Sprintf("%s%s:%s", PriceKeyPrefix, symbol, quote.Currency)
+	m.goCache.SetWithTTL(key, quote, int64(len(key)), m.priceExpiration)
 }
 
 func (m *MemCache) GetSysConfigWithFallback(configName string, f fallback) (*sysconfig.SysConfig, error) {
@@ -4,6 +4,9 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/rest"
+
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/price"
 )
 
 type Config struct {
@@ -16,12 +19,38 @@ type Config struct {
 	TxPool struct {
 		MaxPendingTxCount int
 	}
-	CacheRedis    cache.CacheConf
+	// TxExport.MaxTxs caps the txs a single export returns, 100000 if not set.
+	//nolint:staticcheck
+	TxExport struct {
+		MaxTxs int
+	} `json:",optional"`
+	// CacheDriver is redis by default. With memory the latest states executed
+	// by the committer are not shared, they are read from the database. With
+	// twotier the recently used states are kept in process until the committer
+	// changes them.
+	//nolint:staticcheck
+	CacheDriver dbcache.Driver `json:",optional"`
+	//nolint:staticcheck
+	CacheRedis    cache.CacheConf `json:",optional"`
 	LogConf       logx.LogConf
 	CoinMarketCap struct {
 		Url   string
 		Token string
 	}
+	// PriceOracle configures the providers of the prices, CoinMarketCap with
+	// the url and token above if no provider is set.
+	//nolint:staticcheck
+	PriceOracle price.Config `json:",optional"`
+	// QuoteCurrencies are the fiat currencies the prices are fetched in, the
+	// first one is the default of the portfolio api, USD if not set.
+	//nolint:staticcheck
+	QuoteCurrencies []string `json:",optional"`
+	// Portfolio.MaxAccounts caps the accounts a single portfolio values, 20 if
+	// not set.
+	//nolint:staticcheck
+	Portfolio struct {
+		MaxAccounts int
+	} `json:",optional"`
 	MemCache struct {
 		AccountExpiration int
 		AssetExpiration   int
@@ -0,0 +1,78 @@
+package price
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
+)
+
+// sourceQuote is the price of a symbol given by a provider.
+type sourceQuote struct {
+	source    string
+	price     float64
+	updatedAt time.Time
+}
+
+// aggregate returns the median of the quotes updated within maxAge, after
+// dropping the ones deviating from the median by more than maxDeviation. The
+// source lists the providers of the prices used and the update time is the
+// oldest of them, a quote without source means no usable price.
+func aggregate(quotes []*sourceQuote, now time.Time, maxAge time.Duration, maxDeviation float64) *cache.PriceQuote {
+	fresh := make([]*sourceQuote, 0, len(quotes))
+	for _, q := range quotes {
+		if now.Sub(q.updatedAt) > maxAge || q.price <= 0 {
+			logx.Infof("ignore stale or invalid price %v of %s updated at %s", q.price, q.source, q.updatedAt)
+			continue
+		}
+		fresh = append(fresh, q)
+	}
+	if len(fresh) == 0 {
+		return &cache.PriceQuote{}
+	}
+
+	// with less than three prices the outliers cannot be told
+	if len(fresh) >= 3 {
+		m := median(fresh)
+		inliers := make([]*sourceQuote, 0, len(fresh))
+		for _, q := range fresh {
+			if math.Abs(q.price-m)/m > maxDeviation {
+				logx.Infof("ignore outlier price %v of %s, median %v", q.price, q.source, m)
+				continue
+			}
+			inliers = append(inliers, q)
+		}
+		if len(inliers) == 0 {
+			return &cache.PriceQuote{}
+		}
+		fresh = inliers
+	}
+
+	quote := &cache.PriceQuote{Price: median(fresh), UpdatedAt: now}
+	sources := make([]string, 0, len(fresh))
+	for _, q := range fresh {
+		sources = append(sources, q.source)
+		if q.updatedAt.Before(quote.UpdatedAt) {
+			quote.UpdatedAt = q.updatedAt
+		}
+	}
+	quote.Source = strings.Join(sources, ",")
+	return quote
+}
+
+func median(quotes []*sourceQuote) float64 {
+	prices := make([]float64, 0, len(quotes))
+	for _, q := range quotes {
+		prices = append(prices, q.price)
+	}
+	sort.Float64s(prices)
+	mid := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return (prices[mid-1] + prices[mid]) / 2
+	}
+	return prices[mid]
+}
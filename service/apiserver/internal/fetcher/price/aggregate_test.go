@@ -0,0 +1,49 @@
+package price
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	now := time.Date(2022, 9, 7, 12, 0, 0, 0, time.UTC)
+	quote := func(source string, price float64, age time.Duration) *sourceQuote {
+		return &sourceQuote{source: source, price: price, updatedAt: now.Add(-age)}
+	}
+
+	// the outlier is dropped
+	q := aggregate([]*sourceQuote{
+		quote(SourceCoinMarketCap, 270, time.Minute),
+		quote(SourceCoinGecko, 272, 2*time.Minute),
+		quote(SourceBinance, 271, 0),
+		quote(SourceStatic, 400, 0),
+	}, now, time.Hour, 0.1)
+	assert.Equal(t, 271.0, q.Price)
+	assert.Equal(t, "coinmarketcap,coingecko,binance", q.Source)
+	assert.Equal(t, now.Add(-2*time.Minute), q.UpdatedAt)
+
+	// the stale price is dropped, the median of two is their mean
+	q = aggregate([]*sourceQuote{
+		quote(SourceCoinMarketCap, 270, 2*time.Hour),
+		quote(SourceCoinGecko, 272, 0),
+		quote(SourceBinance, 274, 0),
+	}, now, time.Hour, 0.1)
+	assert.Equal(t, 273.0, q.Price)
+	assert.Equal(t, "coingecko,binance", q.Source)
+
+	// no consensus
+	q = aggregate([]*sourceQuote{
+		quote(SourceCoinMarketCap, 1, 0),
+		quote(SourceCoinGecko, 1, 0),
+		quote(SourceBinance, 100, 0),
+		quote(SourceStatic, 100, 0),
+	}, now, time.Hour, 0.1)
+	assert.Equal(t, 0.0, q.Price)
+	assert.Equal(t, "", q.Source)
+
+	q = aggregate(nil, now, time.Hour, 0.1)
+	assert.Equal(t, 0.0, q.Price)
+	assert.Equal(t, "", q.Source)
+}
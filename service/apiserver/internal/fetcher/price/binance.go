@@ -0,0 +1,62 @@
+package price
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const binanceUrl = "https://api.binance.com/api/v3/ticker/24hr"
+
+// binance gives the last prices of the 24hr tickers of the pairs of the
+// symbols and the quote assets of the currencies.
+type binance struct {
+	url        string
+	currencies map[string]string
+}
+
+func newBinance(c ProviderConfig) *binance {
+	p := &binance{url: c.Url, currencies: c.Currencies}
+	if p.url == "" {
+		p.url = binanceUrl
+	}
+	if len(p.currencies) == 0 {
+		p.currencies = map[string]string{DefaultCurrency: "USDT"}
+	}
+	return p
+}
+
+func (p *binance) Name() string {
+	return SourceBinance
+}
+
+func (p *binance) GetPrice(ctx context.Context, symbol, currency string) (float64, time.Time, error) {
+	quoteAsset, ok := p.currencies[currency]
+	if !ok {
+		quoteAsset = currency
+	}
+	query := url.Values{}
+	query.Set("symbol", strings.ToUpper(symbol+quoteAsset))
+
+	ticker := &binanceTicker{}
+	statusCode, err := getJson(ctx, p.url+"?"+query.Encode(), nil, ticker)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if statusCode == http.StatusBadRequest { //invalid symbol
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	if statusCode != http.StatusOK {
+		return 0, time.Time{}, types.HttpErrClientDo
+	}
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil {
+		return 0, time.Time{}, types.JsonErrUnmarshal
+	}
+	return price, time.UnixMilli(ticker.CloseTime), nil
+}
@@ -0,0 +1,59 @@
+package price
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const coinGeckoUrl = "https://api.coingecko.com/api/v3/simple/price"
+
+// coinGecko queries the prices by the coin ids, the symbols of the assets
+// should be mapped to them.
+type coinGecko struct {
+	url   string
+	token string
+}
+
+func newCoinGecko(c ProviderConfig) *coinGecko {
+	p := &coinGecko{url: c.Url, token: c.Token}
+	if p.url == "" {
+		p.url = coinGeckoUrl
+	}
+	return p
+}
+
+func (p *coinGecko) Name() string {
+	return SourceCoinGecko
+}
+
+func (p *coinGecko) GetPrice(ctx context.Context, symbol, currency string) (float64, time.Time, error) {
+	currency = strings.ToLower(currency)
+	query := url.Values{}
+	query.Set("ids", symbol)
+	query.Set("vs_currencies", currency)
+	query.Set("include_last_updated_at", "true")
+	header := make(map[string]string)
+	if p.token != "" {
+		header["x-cg-pro-api-key"] = p.token
+	}
+
+	// e.g. {"binancecoin":{"usd":271.35,"last_updated_at":1662540000}}
+	prices := make(map[string]map[string]float64)
+	statusCode, err := getJson(ctx, p.url+"?"+query.Encode(), header, &prices)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if statusCode != http.StatusOK {
+		return 0, time.Time{}, types.HttpErrClientDo
+	}
+	price, ok := prices[symbol][currency]
+	if !ok {
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	return price, time.Unix(int64(prices[symbol]["last_updated_at"]), 0), nil
+}
@@ -0,0 +1,90 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	SourceCoinMarketCap = "coinmarketcap"
+
+	coinMarketCapUrl = "https://pro-api.coinmarketcap.com/v1/cryptocurrency/quotes/latest"
+)
+
+type coinMarketCap struct {
+	url   string
+	token string
+}
+
+func newCoinMarketCap(c ProviderConfig) *coinMarketCap {
+	p := &coinMarketCap{url: c.Url, token: c.Token}
+	if p.url == "" {
+		p.url = coinMarketCapUrl
+	}
+	return p
+}
+
+func (p *coinMarketCap) Name() string {
+	return SourceCoinMarketCap
+}
+
+// GetPrice queries by the numeric id if the symbol is mapped to one, by the
+// symbol otherwise.
+func (p *coinMarketCap) GetPrice(ctx context.Context, symbol, currency string) (float64, time.Time, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return 0, time.Time{}, types.HttpErrFailToRequest
+	}
+	query := u.Query()
+	if _, err := strconv.ParseInt(symbol, 10, 64); err == nil {
+		query.Del("symbol")
+		query.Set("id", symbol)
+	} else {
+		query.Set("symbol", symbol)
+	}
+	query.Set("convert", currency)
+	u.RawQuery = query.Encode()
+
+	currencyPrice := &currencyPrice{}
+	statusCode, err := getJson(ctx, u.String(), map[string]string{"X-CMC_PRO_API_KEY": p.token}, currencyPrice)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if statusCode != http.StatusOK { //invalid symbols, or beyond the plan
+		logx.Infof("coinmarketcap responds %d to %s in %s", statusCode, symbol, currency)
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	dataMap, ok := currencyPrice.Data.(map[string]interface{})
+	if !ok { //the currency not listed on cmc
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	coinObj, ok := dataMap[symbol]
+	if !ok {
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	b, err := json.Marshal(coinObj)
+	if err != nil {
+		return 0, time.Time{}, types.JsonErrMarshal
+	}
+	quoteLatest := &QuoteLatest{}
+	if err = json.Unmarshal(b, quoteLatest); err != nil {
+		return 0, time.Time{}, types.JsonErrUnmarshal
+	}
+	q, ok := quoteLatest.Quote[currency]
+	if !ok {
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	updatedAt, err := time.Parse(time.RFC3339, q.LastUpdated)
+	if err != nil {
+		return 0, time.Time{}, types.JsonErrUnmarshal
+	}
+	return q.Price, updatedAt, nil
+}
@@ -2,10 +2,7 @@ package price
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
+	"sync"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -19,36 +16,72 @@ const (
 	fetchTimeout  = 3 * time.Second
 	fetchLimit    = 100
 	fetchInterval = 60 * time.Minute
+
+	defaultMaxAge       = 60 * time.Minute
+	defaultMaxDeviation = 0.1
+
+	DefaultCurrency = "USD"
 )
 
 type Fetcher interface {
 	GetCurrencyPrice(ctx context.Context, l2Symbol string) (price float64, err error)
+	// GetPriceQuote returns the price of the symbol in the quote currency, the
+	// price is 0 if the symbol is not listed.
+	GetPriceQuote(ctx context.Context, l2Symbol, currency string) (quote *cache.PriceQuote, err error)
 	Stop()
 }
 
-func NewFetcher(memCache *cache.MemCache, assetModel asset.AssetModel, cmcUrl, cmcToken string) Fetcher {
+// NewFetcher returns a fetcher refreshing the prices of the assets in the
+// quote currencies, USD if none is given. The prices are the medians of the
+// fresh prices of the providers, without the outliers.
+func NewFetcher(memCache *cache.MemCache, assetModel asset.AssetModel, c Config, currencies []string) (Fetcher, error) {
+	if len(currencies) == 0 {
+		currencies = []string{DefaultCurrency}
+	}
 	f := &fetcher{
-		memCache:   memCache,
-		assetModel: assetModel,
-		cmcUrl:     cmcUrl,
-		cmcToken:   cmcToken,
-		quitCh:     make(chan struct{}),
+		memCache:     memCache,
+		assetModel:   assetModel,
+		providers:    make([]Provider, 0, len(c.Providers)),
+		currencies:   currencies,
+		interval:     time.Duration(c.RefreshInterval) * time.Second,
+		maxAge:       time.Duration(c.MaxAge) * time.Second,
+		maxDeviation: c.MaxDeviation,
+		quitCh:       make(chan struct{}),
+	}
+	for _, providerConfig := range c.Providers {
+		provider, err := NewProvider(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		f.providers = append(f.providers, provider)
+	}
+	if f.interval <= 0 {
+		f.interval = fetchInterval
+	}
+	if f.maxAge <= 0 {
+		f.maxAge = defaultMaxAge
+	}
+	if f.maxDeviation <= 0 {
+		f.maxDeviation = defaultMaxDeviation
 	}
 	go f.loop()
-	return f
+	return f, nil
 }
 
 type fetcher struct {
-	memCache   *cache.MemCache
-	assetModel asset.AssetModel
-	cmcUrl     string
-	cmcToken   string
+	memCache     *cache.MemCache
+	assetModel   asset.AssetModel
+	providers    []Provider
+	currencies   []string
+	interval     time.Duration
+	maxAge       time.Duration
+	maxDeviation float64
 
 	quitCh chan struct{}
 }
 
 func (f *fetcher) loop() {
-	ticker := time.NewTicker(fetchInterval)
+	ticker := time.NewTicker(f.interval)
 	defer ticker.Stop()
 	for {
 		select {
@@ -66,16 +99,14 @@ func (f *fetcher) loop() {
 				}
 
 				for _, asset := range assets {
-					func() {
-						ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
-						defer cancel()
-						assetPrice, err := f.GetCurrencyPrice(ctx, asset.AssetSymbol)
+					for _, currency := range f.currencies {
+						quote, err := f.fetchPriceQuote(context.Background(), asset.AssetSymbol, currency)
 						if err != nil {
-							logx.Errorf("failed to get all assets, err: %v", err)
-							return
+							logx.Errorf("failed to fetch price of %s in %s, err: %v", asset.AssetSymbol, currency, err)
+							continue
 						}
-						f.memCache.SetPrice(asset.AssetSymbol, assetPrice)
-					}()
+						f.memCache.SetPriceQuote(asset.AssetSymbol, quote)
+					}
 				}
 			}
 		case <-f.quitCh:
@@ -88,62 +119,60 @@ func (f *fetcher) Stop() {
 	close(f.quitCh)
 }
 
-func (f *fetcher) GetCurrencyPrice(_ context.Context, symbol string) (float64, error) {
-	return f.memCache.GetPriceWithFallback(symbol, func() (interface{}, error) {
-		quoteMap, err := f.getLatestQuotes(symbol)
-		if err != nil {
-			if err == types.CmcNotListedErr {
-				return 0.0, nil
-			}
-			return 0.0, err
-		}
-		q, ok := quoteMap[symbol]
-		if !ok {
-			return 0.0, nil
-		}
-		price := q.Quote["USD"].Price
-		return price, nil
+func (f *fetcher) GetCurrencyPrice(ctx context.Context, symbol string) (float64, error) {
+	quote, err := f.GetPriceQuote(ctx, symbol, DefaultCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return quote.Price, nil
+}
+
+func (f *fetcher) GetPriceQuote(ctx context.Context, symbol, currency string) (*cache.PriceQuote, error) {
+	return f.memCache.GetPriceQuoteWithFallback(symbol, currency, func() (interface{}, error) {
+		return f.fetchPriceQuote(ctx, symbol, currency)
 	})
 }
 
-func (f *fetcher) getLatestQuotes(symbol string) (map[string]QuoteLatest, error) {
-	client := &http.Client{}
-	url := fmt.Sprintf("%s%s", f.cmcUrl, symbol)
-	request, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, types.HttpErrFailToRequest
-	}
-	request.Header.Add("X-CMC_PRO_API_KEY", f.cmcToken)
-	request.Header.Add("Accept", "application/json")
-	resp, err := client.Do(request)
-	if err != nil {
-		return nil, types.HttpErrClientDo
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, types.IoErrFailToRead
-	}
-	currencyPrice := &currencyPrice{}
-	if err = json.Unmarshal(body, &currencyPrice); err != nil {
-		return nil, types.JsonErrUnmarshal
-	}
-	dataMap, ok := currencyPrice.Data.(map[string]interface{})
-	if !ok { //the currency not listed on cmc
-		return nil, types.CmcNotListedErr
+// fetchPriceQuote asks the providers in parallel, it fails only if all of them
+// fail, the symbols not listed by any provider have a zero price.
+func (f *fetcher) fetchPriceQuote(ctx context.Context, symbol, currency string) (*cache.PriceQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	quotes := make([]*sourceQuote, len(f.providers))
+	errs := make([]error, len(f.providers))
+	var wg sync.WaitGroup
+	for i, provider := range f.providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			price, updatedAt, err := provider.GetPrice(ctx, symbol, currency)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			quotes[i] = &sourceQuote{source: provider.Name(), price: price, updatedAt: updatedAt}
+		}(i, provider)
 	}
-	quotesLatest := make(map[string]QuoteLatest, 0)
-	for _, coinObj := range dataMap {
-		b, err := json.Marshal(coinObj)
-		if err != nil {
-			return nil, types.JsonErrMarshal
-		}
-		quoteLatest := &QuoteLatest{}
-		err = json.Unmarshal(b, quoteLatest)
-		if err != nil {
-			return nil, types.JsonErrUnmarshal
+	wg.Wait()
+
+	listed := make([]*sourceQuote, 0, len(quotes))
+	failed := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			listed = append(listed, quotes[i])
+		case err == types.PriceNotListedErr:
+		default:
+			logx.Errorf("failed to get price of %s in %s from %s, err: %v", symbol, currency, f.providers[i].Name(), err)
+			failed++
 		}
-		quotesLatest[quoteLatest.Symbol] = *quoteLatest
 	}
-	return quotesLatest, nil
+	if failed > 0 && failed == len(f.providers) {
+		return nil, errs[0]
+	}
+
+	quote := aggregate(listed, time.Now(), f.maxAge, f.maxDeviation)
+	quote.Currency = currency
+	return quote, nil
 }
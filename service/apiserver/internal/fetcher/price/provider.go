@@ -0,0 +1,138 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	SourceCoinGecko = "coingecko"
+	SourceBinance   = "binance"
+	SourceStatic    = "static"
+)
+
+// Provider gives the prices of a source. The symbol is the one of the asset
+// mapped to the id of the source, types.PriceNotListedErr is returned if the
+// source does not list it in the currency.
+type Provider interface {
+	Name() string
+	GetPrice(ctx context.Context, symbol, currency string) (price float64, updatedAt time.Time, err error)
+}
+
+type Config struct {
+	// Providers are the sources the prices are the median of.
+	//nolint:staticcheck
+	Providers []ProviderConfig `json:",optional"`
+	// RefreshInterval is the seconds between the refreshes of the prices,
+	// 3600 if not set.
+	//nolint:staticcheck
+	RefreshInterval int `json:",optional"`
+	// MaxAge is the seconds after which the price of a source is stale and
+	// ignored, 3600 if not set.
+	//nolint:staticcheck
+	MaxAge int `json:",optional"`
+	// MaxDeviation is the ratio to the median beyond which the price of a
+	// source is an outlier and ignored, 0.1 if not set. Outliers are only
+	// detected with three or more fresh prices.
+	//nolint:staticcheck
+	MaxDeviation float64 `json:",optional"`
+}
+
+type ProviderConfig struct {
+	// Name is coinmarketcap, coingecko, binance or static.
+	Name string
+	// Url is the endpoint of the source, the public one if not set.
+	//nolint:staticcheck
+	Url string `json:",optional"`
+	//nolint:staticcheck
+	Token string `json:",optional"`
+	// Symbols maps the symbols of the assets to the ids of the source, e.g.
+	// BNB to binancecoin on coingecko, or to the numeric id on coinmarketcap
+	// when the symbol is shared with unrelated coins.
+	//nolint:staticcheck
+	Symbols map[string]string `json:",optional"`
+	// Currencies maps the quote currencies to the quote assets on binance,
+	// USD to USDT if not set.
+	//nolint:staticcheck
+	Currencies map[string]string `json:",optional"`
+	// Prices are the static prices by symbol and currency, File is a json
+	// file of the same which is read on every refresh.
+	//nolint:staticcheck
+	Prices map[string]map[string]float64 `json:",optional"`
+	//nolint:staticcheck
+	File string `json:",optional"`
+}
+
+func NewProvider(c ProviderConfig) (Provider, error) {
+	var provider Provider
+	switch c.Name {
+	case SourceCoinMarketCap:
+		provider = newCoinMarketCap(c)
+	case SourceCoinGecko:
+		provider = newCoinGecko(c)
+	case SourceBinance:
+		provider = newBinance(c)
+	case SourceStatic:
+		static, err := newStatic(c)
+		if err != nil {
+			return nil, err
+		}
+		provider = static
+	default:
+		return nil, fmt.Errorf("unknown price provider %s", c.Name)
+	}
+	if len(c.Symbols) > 0 {
+		provider = &mappedProvider{Provider: provider, symbols: c.Symbols}
+	}
+	return provider, nil
+}
+
+// mappedProvider looks up the prices of the symbols mapped to the ids of the
+// source.
+type mappedProvider struct {
+	Provider
+	symbols map[string]string
+}
+
+func (p *mappedProvider) GetPrice(ctx context.Context, symbol, currency string) (float64, time.Time, error) {
+	if id, ok := p.symbols[symbol]; ok {
+		symbol = id
+	}
+	return p.Provider.GetPrice(ctx, symbol, currency)
+}
+
+// getJson requests the url and decodes the json body into v if the status is
+// ok, the status code is returned either way.
+func getJson(ctx context.Context, url string, header map[string]string, v interface{}) (int, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, types.HttpErrFailToRequest
+	}
+	for k, value := range header {
+		request.Header.Add(k, value)
+	}
+	request.Header.Add("Accept", "application/json")
+	resp, err := client.Do(request)
+	if err != nil {
+		return 0, types.HttpErrClientDo
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, types.IoErrFailToRead
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, types.JsonErrUnmarshal
+	}
+	return resp.StatusCode, nil
+}
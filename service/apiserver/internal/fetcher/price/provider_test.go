@@ -0,0 +1,98 @@
+package price
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/cmc":
+			if query.Get("id") != "1839" || query.Get("convert") != "EUR" || r.Header.Get("X-CMC_PRO_API_KEY") != "token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":{},"data":{"1839":{"symbol":"BNB","quote":{"EUR":{"price":270.5,"last_updated":"2022-09-07T08:32:00.000Z"}}}}}`))
+		case "/coingecko":
+			_, _ = w.Write([]byte(`{"binancecoin":{"usd":271.35,"last_updated_at":1662540000}}`))
+		case "/binance":
+			if query.Get("symbol") != "BNBUSDT" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"symbol":"BNBUSDT","lastPrice":"271.10000000","closeTime":1662540000123}`))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	cmc, err := NewProvider(ProviderConfig{Name: SourceCoinMarketCap, Url: server.URL + "/cmc?symbol=", Token: "token",
+		Symbols: map[string]string{"BNB": "1839"}})
+	assert.NoError(t, err)
+	price, updatedAt, err := cmc.GetPrice(ctx, "BNB", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 270.5, price)
+	assert.Equal(t, time.Date(2022, 9, 7, 8, 32, 0, 0, time.UTC), updatedAt.UTC())
+	_, _, err = cmc.GetPrice(ctx, "LEG", "EUR")
+	assert.Equal(t, types.PriceNotListedErr, err)
+
+	coinGecko, err := NewProvider(ProviderConfig{Name: SourceCoinGecko, Url: server.URL + "/coingecko"})
+	assert.NoError(t, err)
+	price, updatedAt, err = coinGecko.GetPrice(ctx, "binancecoin", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 271.35, price)
+	assert.Equal(t, int64(1662540000), updatedAt.Unix())
+	_, _, err = coinGecko.GetPrice(ctx, "binancecoin", "EUR")
+	assert.Equal(t, types.PriceNotListedErr, err)
+
+	binance, err := NewProvider(ProviderConfig{Name: SourceBinance, Url: server.URL + "/binance"})
+	assert.NoError(t, err)
+	price, updatedAt, err = binance.GetPrice(ctx, "BNB", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 271.1, price)
+	assert.Equal(t, int64(1662540000123), updatedAt.UnixMilli())
+	_, _, err = binance.GetPrice(ctx, "BNB", "EUR")
+	assert.Equal(t, types.PriceNotListedErr, err)
+
+	_, err = NewProvider(ProviderConfig{Name: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestStaticProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prices.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"BNB": {"USD": 271.35}}`), 0600))
+
+	static, err := NewProvider(ProviderConfig{Name: SourceStatic, File: file})
+	assert.NoError(t, err)
+	price, _, err := static.GetPrice(context.Background(), "BNB", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 271.35, price)
+
+	// the file is read again on every query
+	assert.NoError(t, os.WriteFile(file, []byte(`{"BNB": {"USD": 280}}`), 0600))
+	price, _, err = static.GetPrice(context.Background(), "BNB", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 280.0, price)
+	_, _, err = static.GetPrice(context.Background(), "ETH", "USD")
+	assert.Equal(t, types.PriceNotListedErr, err)
+
+	_, err = NewProvider(ProviderConfig{Name: SourceStatic, File: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+
+	static, err = NewProvider(ProviderConfig{Name: SourceStatic, Prices: map[string]map[string]float64{"BNB": {"EUR": 270}}})
+	assert.NoError(t, err)
+	price, _, err = static.GetPrice(context.Background(), "BNB", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 270.0, price)
+}
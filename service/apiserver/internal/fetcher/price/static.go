@@ -0,0 +1,60 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// static gives the configured prices, or the ones of a json file which can be
+// changed while running, e.g. {"BNB": {"USD": 271.35}}. It works offline and
+// its prices are always fresh.
+type static struct {
+	prices map[string]map[string]float64
+	file   string
+}
+
+func newStatic(c ProviderConfig) (*static, error) {
+	p := &static{prices: c.Prices, file: c.File}
+	if p.file != "" {
+		if _, err := p.load(); err != nil {
+			return nil, fmt.Errorf("failed to load static prices from %s, err: %v", p.file, err)
+		}
+	}
+	return p, nil
+}
+
+func (p *static) Name() string {
+	return SourceStatic
+}
+
+func (p *static) GetPrice(_ context.Context, symbol, currency string) (float64, time.Time, error) {
+	prices := p.prices
+	if p.file != "" {
+		var err error
+		if prices, err = p.load(); err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+	price, ok := prices[symbol][currency]
+	if !ok {
+		return 0, time.Time{}, types.PriceNotListedErr
+	}
+	return price, time.Now(), nil
+}
+
+func (p *static) load() (map[string]map[string]float64, error) {
+	data, err := os.ReadFile(p.file)
+	if err != nil {
+		return nil, types.IoErrFailToRead
+	}
+	prices := make(map[string]map[string]float64)
+	if err = json.Unmarshal(data, &prices); err != nil {
+		return nil, types.JsonErrUnmarshal
+	}
+	return prices, nil
+}
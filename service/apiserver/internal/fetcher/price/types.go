@@ -37,3 +37,9 @@ type currencyPrice struct {
 	Status status      `json:"status"`
 	Data   interface{} `json:"data"`
 }
+
+type binanceTicker struct {
+	Symbol    string `json:"symbol"`
+	LastPrice string `json:"lastPrice"`
+	CloseTime int64  `json:"closeTime"`
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bnb-chain/zkbnb/common/chain"
 	accdao "github.com/bnb-chain/zkbnb/dao/account"
+	blockdao "github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/dbcache"
 	nftdao "github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/types"
@@ -12,27 +13,45 @@ import (
 
 //go:generate mockgen -source api.go -destination api_mock.go -package state
 
+// LatestHeight is the default height of the requests, it means the latest states.
+const LatestHeight = -1
+
 // Fetcher will fetch the latest states (account,nft) from redis, which is written by committer;
 // and if the required data cannot be found then database will be used.
+// The states at a block height are read from the histories in database.
 type Fetcher interface {
 	GetLatestAccount(accountIndex int64) (accountInfo *types.AccountInfo, err error)
 	GetLatestNft(nftIndex int64) (*types.NftInfo, error)
+	GetAccountAtHeight(accountIndex, height int64) (*types.AccountInfo, error)
+	GetNftAtHeight(nftIndex, height int64) (*types.NftInfo, error)
+	// CheckHeight returns types.AppErrInvalidBlockHeight if the states of the
+	// height are not committed yet.
+	CheckHeight(height int64) error
 }
 
 func NewFetcher(redisCache dbcache.Cache,
 	accountModel accdao.AccountModel,
-	nftModel nftdao.L2NftModel) Fetcher {
+	accountHistoryModel accdao.AccountHistoryModel,
+	nftModel nftdao.L2NftModel,
+	nftHistoryModel nftdao.L2NftHistoryModel,
+	blockModel blockdao.BlockModel) Fetcher {
 	return &fetcher{
-		redisCache:   redisCache,
-		accountModel: accountModel,
-		nftModel:     nftModel,
+		redisCache:          redisCache,
+		accountModel:        accountModel,
+		accountHistoryModel: accountHistoryModel,
+		nftModel:            nftModel,
+		nftHistoryModel:     nftHistoryModel,
+		blockModel:          blockModel,
 	}
 }
 
 type fetcher struct {
-	redisCache   dbcache.Cache
-	accountModel accdao.AccountModel
-	nftModel     nftdao.L2NftModel
+	redisCache          dbcache.Cache
+	accountModel        accdao.AccountModel
+	accountHistoryModel accdao.AccountHistoryModel
+	nftModel            nftdao.L2NftModel
+	nftHistoryModel     nftdao.L2NftHistoryModel
+	blockModel          blockdao.BlockModel
 }
 
 func (f *fetcher) GetLatestAccount(accountIndex int64) (*types.AccountInfo, error) {
@@ -79,3 +98,68 @@ func (f *fetcher) GetLatestNft(nftIndex int64) (*types.NftInfo, error) {
 		n.CreatorTreasuryRate,
 		n.CollectionId), nil
 }
+
+func (f *fetcher) CheckHeight(height int64) error {
+	if height < 0 {
+		return types.AppErrInvalidBlockHeight
+	}
+	curHeight, err := f.blockModel.GetCurrentBlockHeight()
+	if err != nil {
+		return types.AppErrInternal
+	}
+	if height < curHeight {
+		return nil
+	}
+	if height > curHeight {
+		return types.AppErrInvalidBlockHeight
+	}
+	curBlock, err := f.blockModel.GetBlockByHeightWithoutTx(curHeight)
+	if err != nil {
+		return types.AppErrInternal
+	}
+	// the histories of the proposing block are written when it is committed
+	if curBlock.BlockStatus == blockdao.StatusProposing {
+		return types.AppErrInvalidBlockHeight
+	}
+	return nil
+}
+
+func (f *fetcher) GetAccountAtHeight(accountIndex, height int64) (*types.AccountInfo, error) {
+	// the name and public key of an account never change
+	account, err := f.accountModel.GetAccountByIndex(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	history, err := f.accountHistoryModel.GetLatestAccountHistory(accountIndex, height+1)
+	if err != nil {
+		return nil, err
+	}
+	return chain.ToFormatAccountInfo(&accdao.Account{
+		Model:           account.Model,
+		AccountIndex:    account.AccountIndex,
+		AccountName:     account.AccountName,
+		PublicKey:       account.PublicKey,
+		AccountNameHash: account.AccountNameHash,
+		L1Address:       account.L1Address,
+		Nonce:           history.Nonce,
+		CollectionNonce: history.CollectionNonce,
+		AssetInfo:       history.AssetInfo,
+		AssetRoot:       history.AssetRoot,
+		Status:          accdao.AccountStatusConfirmed,
+	})
+}
+
+func (f *fetcher) GetNftAtHeight(nftIndex, height int64) (*types.NftInfo, error) {
+	n, err := f.nftHistoryModel.GetLatestNftHistory(nftIndex, height+1)
+	if err != nil {
+		return nil, err
+	}
+	return types.ConstructNftInfo(nftIndex,
+		n.CreatorAccountIndex,
+		n.OwnerAccountIndex,
+		n.NftContentHash,
+		n.NftL1TokenId,
+		n.NftL1Address,
+		n.CreatorTreasuryRate,
+		n.CollectionId), nil
+}
@@ -3,6 +3,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	account "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/account"
 	asset "github.com/bnb-chain/zkbnb/service/apiserver/internal/handler/asset"
@@ -39,6 +40,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/account",
 				Handler: account.GetAccountHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/portfolio",
+				Handler: account.GetPortfolioHandler(serverCtx),
+			},
 		},
 	)
 
@@ -104,6 +110,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/search",
 				Handler: info.SearchHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/stats",
+				Handler: info.GetStatsHandler(serverCtx),
+			},
 		},
 	)
 
@@ -157,6 +168,17 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 		},
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/accountTxsExport",
+				Handler: transaction.ExportAccountTxsHandler(serverCtx),
+			},
+		},
+		rest.WithTimeout(120000*time.Millisecond),
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -169,6 +191,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/accountNfts",
 				Handler: nft.GetAccountNftsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/nft",
+				Handler: nft.GetNftHandler(serverCtx),
+			},
 		},
 	)
 }
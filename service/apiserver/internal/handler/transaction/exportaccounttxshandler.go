@@ -0,0 +1,28 @@
+package transaction
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/transaction"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func ExportAccountTxsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ReqExportAccountTxs
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		// the txs are written to w by the logic
+		l := transaction.NewExportAccountTxsLogic(r.Context(), svcCtx)
+		err := l.ExportAccountTxs(&req, w)
+		if err != nil {
+			httpx.Error(w, err)
+		}
+	}
+}
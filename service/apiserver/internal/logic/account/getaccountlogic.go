@@ -8,6 +8,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/state"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
 	types2 "github.com/bnb-chain/zkbnb/types"
@@ -19,6 +21,8 @@ const (
 	queryByPk    = "pk"
 )
 
+var accountQuery = utils.AccountQuery{ByIndex: queryByIndex, ByName: queryByName, ByPk: queryByPk}
+
 type GetAccountLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -34,29 +38,20 @@ func NewGetAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAcc
 }
 
 func (l *GetAccountLogic) GetAccount(req *types.ReqGetAccount) (resp *types.Account, err error) {
-	index := int64(0)
-	switch req.By {
-	case queryByIndex:
-		index, err = strconv.ParseInt(req.Value, 10, 64)
-		if err != nil || index < 0 {
-			return nil, types2.AppErrInvalidAccountIndex
-		}
-	case queryByName:
-		index, err = l.svcCtx.MemCache.GetAccountIndexByName(req.Value)
-	case queryByPk:
-		index, err = l.svcCtx.MemCache.GetAccountIndexByPk(req.Value)
-	default:
-		return nil, types2.AppErrInvalidParam.RefineError("param by should be index|name|pk")
+	index, err := utils.GetAccountIndex(l.svcCtx.MemCache, accountQuery, req.By, req.Value)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		if err == types2.DbErrNotFound {
-			return nil, types2.AppErrAccountNotFound
+	var account *types2.AccountInfo
+	if req.Height == state.LatestHeight {
+		account, err = l.svcCtx.StateFetcher.GetLatestAccount(index)
+	} else {
+		if err = l.svcCtx.StateFetcher.CheckHeight(req.Height); err != nil {
+			return nil, err
 		}
-		return nil, types2.AppErrInternal
+		account, err = l.svcCtx.StateFetcher.GetAccountAtHeight(index, req.Height)
 	}
-
-	account, err := l.svcCtx.StateFetcher.GetLatestAccount(index)
 	if err != nil {
 		if err == types2.DbErrNotFound {
 			return nil, types2.AppErrAccountNotFound
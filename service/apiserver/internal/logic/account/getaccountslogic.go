@@ -5,6 +5,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
 	types2 "github.com/bnb-chain/zkbnb/types"
@@ -37,12 +39,25 @@ func (l *GetAccountsLogic) GetAccounts(req *types.ReqGetRange) (resp *types.Acco
 		Total:    uint32(total),
 	}
 
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	accounts, err := l.svcCtx.AccountModel.GetAccounts(int(req.Limit), int64(req.Offset))
+	var accounts []*account.Account
+	if req.Cursor != "" {
+		var beforeIndex int64
+		beforeIndex, err = utils.DecodeCursor(utils.CursorAccount, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		accounts, err = l.svcCtx.AccountModel.GetAccountsBeforeIndex(beforeIndex, int(req.Limit))
+	} else {
+		accounts, err = l.svcCtx.AccountModel.GetAccounts(int(req.Limit), int64(req.Offset))
+	}
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 	for _, a := range accounts {
@@ -52,5 +67,6 @@ func (l *GetAccountsLogic) GetAccounts(req *types.ReqGetRange) (resp *types.Acco
 			Pk:    a.PublicKey,
 		})
 	}
+	resp.NextCursor = utils.NextCursor(utils.CursorAccount, accounts[len(accounts)-1].AccountIndex, len(accounts), int(req.Limit))
 	return resp, nil
 }
@@ -0,0 +1,178 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/price"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	defaultPortfolioMaxAccounts = 20
+	// valueDecimals is the number of decimals the fiat values are rounded to.
+	valueDecimals = 8
+)
+
+type GetPortfolioLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetPortfolioLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPortfolioLogic {
+	return &GetPortfolioLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetPortfolio values the latest balances of the accounts in the quote
+// currency, the assets not listed by the price source have a zero price.
+func (l *GetPortfolioLogic) GetPortfolio(req *types.ReqGetPortfolio) (*types.Portfolio, error) {
+	currency, err := l.quoteCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	maxAccounts := l.svcCtx.Config.Portfolio.MaxAccounts
+	if maxAccounts <= 0 {
+		maxAccounts = defaultPortfolioMaxAccounts
+	}
+	if len(req.Values) == 0 || len(req.Values) > maxAccounts {
+		return nil, types2.AppErrInvalidParam.RefineError(fmt.Sprintf("param values should have 1 to %d accounts", maxAccounts))
+	}
+
+	maxAssetId, err := l.svcCtx.AssetModel.GetMaxAssetId()
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	resp := &types.Portfolio{
+		Currency: currency,
+		Accounts: make([]*types.AccountPortfolio, 0, len(req.Values)),
+	}
+	totalValue := new(big.Rat)
+	valued := make(map[int64]bool, len(req.Values))
+	for _, value := range req.Values {
+		index, err := utils.GetAccountIndex(l.svcCtx.MemCache, accountQuery, req.By, value)
+		if err != nil {
+			return nil, err
+		}
+		if valued[index] {
+			continue
+		}
+		valued[index] = true
+
+		accountPortfolio, accountValue, err := l.valueAccount(index, maxAssetId, currency)
+		if err != nil {
+			return nil, err
+		}
+		resp.Accounts = append(resp.Accounts, accountPortfolio)
+		totalValue.Add(totalValue, accountValue)
+	}
+	resp.TotalValue = formatDecimal(totalValue, valueDecimals)
+	return resp, nil
+}
+
+func (l *GetPortfolioLogic) quoteCurrency(currency string) (string, error) {
+	currencies := l.svcCtx.Config.QuoteCurrencies
+	if len(currencies) == 0 {
+		currencies = []string{price.DefaultCurrency}
+	}
+	if currency == "" {
+		return currencies[0], nil
+	}
+	for _, c := range currencies {
+		if strings.EqualFold(c, currency) {
+			return c, nil
+		}
+	}
+	return "", types2.AppErrInvalidParam.RefineError("param currency should be " + strings.Join(currencies, "|"))
+}
+
+func (l *GetPortfolioLogic) valueAccount(index, maxAssetId int64, currency string) (*types.AccountPortfolio, *big.Rat, error) {
+	account, err := l.svcCtx.StateFetcher.GetLatestAccount(index)
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, nil, types2.AppErrAccountNotFound
+		}
+		return nil, nil, types2.AppErrInternal
+	}
+
+	resp := &types.AccountPortfolio{
+		Index:  account.AccountIndex,
+		Name:   account.AccountName,
+		Assets: make([]*types.PortfolioAsset, 0, len(account.AssetInfo)),
+	}
+	totalValue := new(big.Rat)
+	for _, asset := range account.AssetInfo {
+		if asset.AssetId > maxAssetId {
+			continue //it is used for offer related, or empty balance; max ip id should be less than max asset id
+		}
+		if asset.Balance == nil || asset.Balance.Cmp(types2.ZeroBigInt) <= 0 {
+			continue
+		}
+		assetInfo, err := l.svcCtx.MemCache.GetAssetByIdWithFallback(asset.AssetId, func() (interface{}, error) {
+			return l.svcCtx.AssetModel.GetAssetById(asset.AssetId)
+		})
+		if err != nil {
+			return nil, nil, types2.AppErrInternal
+		}
+		quote, err := l.svcCtx.PriceFetcher.GetPriceQuote(l.ctx, assetInfo.AssetSymbol, currency)
+		if err != nil {
+			return nil, nil, types2.AppErrInternal
+		}
+
+		// BNB for example, the amount is the balance in BNB converted from
+		// wei, and the value is the amount * price per BNB.
+		unitConversion := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(assetInfo.Decimals)), nil)
+		amount := new(big.Rat).SetFrac(asset.Balance, unitConversion)
+		unitPrice := new(big.Rat)
+		if quote.Price > 0 {
+			unitPrice.SetFloat64(quote.Price)
+		}
+		value := new(big.Rat).Mul(amount, unitPrice)
+		totalValue.Add(totalValue, value)
+
+		portfolioAsset := &types.PortfolioAsset{
+			Id:          uint32(asset.AssetId),
+			Name:        assetInfo.AssetName,
+			Symbol:      assetInfo.AssetSymbol,
+			Decimals:    assetInfo.Decimals,
+			Balance:     asset.Balance.String(),
+			Amount:      formatDecimal(amount, int(assetInfo.Decimals)),
+			Price:       strconv.FormatFloat(quote.Price, 'f', -1, 64),
+			Value:       formatDecimal(value, valueDecimals),
+			PriceSource: quote.Source,
+		}
+		if !quote.UpdatedAt.IsZero() {
+			portfolioAsset.PriceUpdatedAt = quote.UpdatedAt.Unix()
+		}
+		resp.Assets = append(resp.Assets, portfolioAsset)
+	}
+	resp.TotalValue = formatDecimal(totalValue, valueDecimals)
+
+	sort.Slice(resp.Assets, func(i, j int) bool {
+		return resp.Assets[i].Id < resp.Assets[j].Id
+	})
+	return resp, totalValue, nil
+}
+
+// formatDecimal formats x rounded to the decimals without trailing zeros.
+func formatDecimal(x *big.Rat, decimals int) string {
+	s := x.FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/dao/asset"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
 	types2 "github.com/bnb-chain/zkbnb/types"
@@ -39,12 +41,25 @@ func (l *GetAssetsLogic) GetAssets(req *types.ReqGetRange) (resp *types.Assets,
 		Assets: make([]*types.Asset, 0, req.Limit),
 		Total:  uint32(total),
 	}
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	assets, err := l.svcCtx.AssetModel.GetAssets(int64(req.Limit), int64(req.Offset))
+	var assets []*asset.Asset
+	if req.Cursor != "" {
+		var afterId int64
+		afterId, err = utils.DecodeCursor(utils.CursorAsset, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		assets, err = l.svcCtx.AssetModel.GetAssetsAfterId(uint(afterId), int64(req.Limit))
+	} else {
+		assets, err = l.svcCtx.AssetModel.GetAssets(int64(req.Limit), int64(req.Offset))
+	}
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 
@@ -65,5 +80,6 @@ func (l *GetAssetsLogic) GetAssets(req *types.ReqGetRange) (resp *types.Assets,
 			Icon:       fmt.Sprintf(iconBaseUrl, strings.ToLower(asset.AssetSymbol), strings.ToLower(asset.AssetSymbol)),
 		})
 	}
+	resp.NextCursor = utils.NextCursor(utils.CursorAsset, int64(assets[len(assets)-1].ID), len(assets), int(req.Limit))
 	return resp, nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
@@ -41,12 +42,25 @@ func (l *GetBlocksLogic) GetBlocks(req *types.ReqGetRange) (*types.Blocks, error
 		Blocks: make([]*types.Block, 0, req.Limit),
 		Total:  uint32(total),
 	}
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	blocks, err := l.svcCtx.BlockModel.GetBlocks(int64(req.Limit), int64(req.Offset))
+	var blocks []*block.Block
+	if req.Cursor != "" {
+		var beforeHeight int64
+		beforeHeight, err = utils.DecodeCursor(utils.CursorBlock, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err = l.svcCtx.BlockModel.GetBlocksBeforeHeight(beforeHeight, int64(req.Limit))
+	} else {
+		blocks, err = l.svcCtx.BlockModel.GetBlocks(int64(req.Limit), int64(req.Offset))
+	}
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 	for _, b := range blocks {
@@ -71,5 +85,6 @@ func (l *GetBlocksLogic) GetBlocks(req *types.ReqGetRange) (*types.Blocks, error
 		}
 		resp.Blocks = append(resp.Blocks, block)
 	}
+	resp.NextCursor = utils.NextCursor(utils.CursorBlock, blocks[len(blocks)-1].BlockHeight, len(blocks), int(req.Limit))
 	return resp, nil
 }
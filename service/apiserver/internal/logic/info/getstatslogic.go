@@ -0,0 +1,115 @@
+package info
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/stats"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const maxStatsPoints = 1000
+
+type GetStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetStatsLogic {
+	return &GetStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetStats returns a point per period overlapping [from, to), periods without
+// committed txs have zero values.
+func (l *GetStatsLogic) GetStats(req *types.ReqGetStats) (*types.Stats, error) {
+	to := time.Now()
+	if req.To > 0 {
+		to = time.Unix(req.To, 0)
+	}
+	if req.From < 0 || !time.Unix(req.From, 0).Before(to) {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid time range")
+	}
+
+	resp := &types.Stats{
+		Granularity: req.Granularity,
+		Points:      make([]*types.StatsPoint, 0),
+	}
+	points := make(map[int64]*types.StatsPoint)
+	from := stats.PeriodStart(req.Granularity, time.Unix(req.From, 0))
+	end := from
+	for end.Before(to) {
+		if len(resp.Points) >= maxStatsPoints {
+			return nil, types2.AppErrInvalidParam.RefineError("too many points, use a shorter range or a coarser granularity")
+		}
+		point := &types.StatsPoint{
+			Time:          end.Unix(),
+			TxCountByType: make([]*types.StatsTxTypeCount, 0),
+			Volume:        make([]*types.StatsAssetAmount, 0),
+			GasFee:        make([]*types.StatsAssetAmount, 0),
+		}
+		resp.Points = append(resp.Points, point)
+		points[point.Time] = point
+		end = stats.NextPeriodStart(req.Granularity, end)
+	}
+
+	rollups, err := l.svcCtx.StatModel.GetRollups(req.Granularity, from, end)
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+	for _, rollup := range rollups {
+		point, ok := points[rollup.PeriodStart.Unix()]
+		if !ok {
+			continue
+		}
+		switch rollup.Metric {
+		case stats.MetricTxCount:
+			count, err := strconv.ParseInt(rollup.Value, 10, 64)
+			if err != nil {
+				logx.Errorf("parse tx count %s failed: %s", rollup.Value, err.Error())
+				return nil, types2.AppErrInternal
+			}
+			point.TxCount += count
+			point.TxCountByType = append(point.TxCountByType, &types.StatsTxTypeCount{TxType: rollup.Key, Count: count})
+			switch rollup.Key {
+			case types2.TxTypeMintNft:
+				point.NftMints += count
+			case types2.TxTypeAtomicMatch:
+				point.NftTrades += count
+			}
+		case stats.MetricVolume:
+			point.Volume = append(point.Volume, l.assetAmount(rollup))
+		case stats.MetricGasFee:
+			point.GasFee = append(point.GasFee, l.assetAmount(rollup))
+		}
+	}
+
+	activeAccounts, err := l.svcCtx.StatModel.GetActiveAccountCounts(req.Granularity, from, end)
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+	for _, activeAccount := range activeAccounts {
+		if point, ok := points[activeAccount.PeriodStart.Unix()]; ok {
+			point.ActiveAccounts = activeAccount.Count
+		}
+	}
+	return resp, nil
+}
+
+func (l *GetStatsLogic) assetAmount(rollup *stats.Rollup) *types.StatsAssetAmount {
+	assetName, _ := l.svcCtx.MemCache.GetAssetNameById(rollup.Key)
+	return &types.StatsAssetAmount{
+		AssetId:   rollup.Key,
+		AssetName: assetName,
+		Amount:    rollup.Value,
+	}
+}
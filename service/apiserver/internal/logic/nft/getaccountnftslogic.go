@@ -6,6 +6,9 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	nftdao "github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/state"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
 	types2 "github.com/bnb-chain/zkbnb/types"
@@ -58,7 +61,13 @@ func (l *GetAccountNftsLogic) GetAccountNfts(req *types.ReqGetAccountNfts) (resp
 		return nil, types2.AppErrInternal
 	}
 
-	total, err := l.svcCtx.NftModel.GetNftsCountByAccountIndex(accountIndex)
+	if req.Height != state.LatestHeight {
+		if err = l.svcCtx.StateFetcher.CheckHeight(req.Height); err != nil {
+			return nil, err
+		}
+	}
+
+	total, err := l.getNftsCount(accountIndex, req.Height)
 	if err != nil {
 		if err != types2.DbErrNotFound {
 			return nil, types2.AppErrInternal
@@ -66,12 +75,25 @@ func (l *GetAccountNftsLogic) GetAccountNfts(req *types.ReqGetAccountNfts) (resp
 	}
 
 	resp.Total = total
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	nfts, err := l.svcCtx.NftModel.GetNftsByAccountIndex(accountIndex, int64(req.Limit), int64(req.Offset))
+	var nfts []*nftdao.L2Nft
+	if req.Cursor != "" {
+		var beforeIndex int64
+		beforeIndex, err = utils.DecodeCursor(utils.CursorNft, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		nfts, err = l.getNftsBefore(accountIndex, req.Height, beforeIndex, int64(req.Limit))
+	} else {
+		nfts, err = l.getNfts(accountIndex, req.Height, int64(req.Limit), int64(req.Offset))
+	}
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 
@@ -91,5 +113,54 @@ func (l *GetAccountNftsLogic) GetAccountNfts(req *types.ReqGetAccountNfts) (resp
 			CollectionId:        nft.CollectionId,
 		})
 	}
+	if len(nfts) > 0 {
+		resp.NextCursor = utils.NextCursor(utils.CursorNft, nfts[len(nfts)-1].NftIndex, len(nfts), int(req.Limit))
+	}
 	return resp, nil
 }
+
+func (l *GetAccountNftsLogic) getNftsCount(accountIndex, height int64) (int64, error) {
+	if height == state.LatestHeight {
+		return l.svcCtx.NftModel.GetNftsCountByAccountIndex(accountIndex)
+	}
+	return l.svcCtx.NftHistoryModel.GetLatestNftsCountByAccountIndex(accountIndex, height)
+}
+
+func (l *GetAccountNftsLogic) getNfts(accountIndex, height, limit, offset int64) ([]*nftdao.L2Nft, error) {
+	if height == state.LatestHeight {
+		return l.svcCtx.NftModel.GetNftsByAccountIndex(accountIndex, limit, offset)
+	}
+	histories, err := l.svcCtx.NftHistoryModel.GetLatestNftsByAccountIndex(accountIndex, height, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return convertNftHistories(histories), nil
+}
+
+func (l *GetAccountNftsLogic) getNftsBefore(accountIndex, height, nftIndex, limit int64) ([]*nftdao.L2Nft, error) {
+	if height == state.LatestHeight {
+		return l.svcCtx.NftModel.GetNftsByAccountIndexBefore(accountIndex, nftIndex, limit)
+	}
+	histories, err := l.svcCtx.NftHistoryModel.GetLatestNftsByAccountIndexBefore(accountIndex, height, nftIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertNftHistories(histories), nil
+}
+
+func convertNftHistories(histories []*nftdao.L2NftHistory) []*nftdao.L2Nft {
+	nfts := make([]*nftdao.L2Nft, 0, len(histories))
+	for _, history := range histories {
+		nfts = append(nfts, &nftdao.L2Nft{
+			NftIndex:            history.NftIndex,
+			CreatorAccountIndex: history.CreatorAccountIndex,
+			OwnerAccountIndex:   history.OwnerAccountIndex,
+			NftContentHash:      history.NftContentHash,
+			NftL1Address:        history.NftL1Address,
+			NftL1TokenId:        history.NftL1TokenId,
+			CreatorTreasuryRate: history.CreatorTreasuryRate,
+			CollectionId:        history.CollectionId,
+		})
+	}
+	return nfts
+}
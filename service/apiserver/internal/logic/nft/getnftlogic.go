@@ -0,0 +1,67 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/fetcher/state"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type GetNftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetNftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetNftLogic {
+	return &GetNftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetNftLogic) GetNft(req *types.ReqGetNft) (resp *types.Nft, err error) {
+	if req.Index < 0 {
+		return nil, types2.AppErrInvalidNftIndex
+	}
+
+	var nft *types2.NftInfo
+	if req.Height == state.LatestHeight {
+		nft, err = l.svcCtx.StateFetcher.GetLatestNft(req.Index)
+	} else {
+		if err = l.svcCtx.StateFetcher.CheckHeight(req.Height); err != nil {
+			return nil, err
+		}
+		nft, err = l.svcCtx.StateFetcher.GetNftAtHeight(req.Index, req.Height)
+	}
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrNftNotFound
+		}
+		return nil, types2.AppErrInternal
+	}
+	// a withdrawn nft is emptied
+	if nft.NftContentHash == types2.EmptyNftContentHash {
+		return nil, types2.AppErrNftNotFound
+	}
+
+	creatorName, _ := l.svcCtx.MemCache.GetAccountNameByIndex(nft.CreatorAccountIndex)
+	ownerName, _ := l.svcCtx.MemCache.GetAccountNameByIndex(nft.OwnerAccountIndex)
+	return &types.Nft{
+		Index:               nft.NftIndex,
+		CreatorAccountIndex: nft.CreatorAccountIndex,
+		CreatorAccountName:  creatorName,
+		OwnerAccountIndex:   nft.OwnerAccountIndex,
+		OwnerAccountName:    ownerName,
+		ContentHash:         nft.NftContentHash,
+		L1Address:           nft.NftL1Address,
+		L1TokenId:           nft.NftL1TokenId,
+		CreatorTreasuryRate: nft.CreatorTreasuryRate,
+		CollectionId:        nft.CollectionId,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+// accountTxFilter holds the filters shared by the query and the export of the
+// txs of an account, negative ids, heights and zero times are not filtered.
+type accountTxFilter struct {
+	types        []int64
+	assetId      int64
+	direction    string
+	counterparty int64
+	statuses     []int64
+	fromTime     int64
+	toTime       int64
+	fromHeight   int64
+	toHeight     int64
+}
+
+func (f *accountTxFilter) options() ([]tx.GetTxOptionFunc, error) {
+	if f.fromTime < 0 || f.toTime < 0 || (f.toTime > 0 && f.toTime < f.fromTime) {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid time range")
+	}
+	if f.toHeight >= 0 && f.toHeight < f.fromHeight {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid block height range")
+	}
+
+	options := []tx.GetTxOptionFunc{tx.GetTxWithDirection(f.direction)}
+	if len(f.types) > 0 {
+		options = append(options, tx.GetTxWithTypes(f.types))
+	}
+	if len(f.statuses) > 0 {
+		options = append(options, tx.GetTxWithStatuses(f.statuses))
+	}
+	if f.assetId >= 0 {
+		options = append(options, tx.GetTxWithAssetId(f.assetId))
+	}
+	if f.counterparty >= 0 {
+		options = append(options, tx.GetTxWithCounterparty(f.counterparty))
+	}
+	if f.fromTime > 0 || f.toTime > 0 {
+		var from, to time.Time
+		if f.fromTime > 0 {
+			from = time.Unix(f.fromTime, 0)
+		}
+		if f.toTime > 0 {
+			to = time.Unix(f.toTime, 0)
+		}
+		options = append(options, tx.GetTxWithTimeRange(from, to))
+	}
+	if f.fromHeight > 0 {
+		options = append(options, tx.GetTxWithFromHeight(f.fromHeight))
+	}
+	if f.toHeight >= 0 {
+		options = append(options, tx.GetTxWithToHeight(f.toHeight))
+	}
+	return options, nil
+}
@@ -0,0 +1,141 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+// newTestTxModel returns the tx model of an in-memory database with the txs:
+// 1 sends asset 0 to 2 at height 1, 2 withdraws asset 1 at height 2 and 3
+// sends asset 1 to 1 at height 3.
+func newTestTxModel(t *testing.T) tx.TxModel {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	txModel := tx.NewTxModel(db)
+	require.NoError(t, txModel.CreateTxTable())
+	require.NoError(t, tx.NewTxDetailModel(db).CreateTxDetailTable())
+
+	fungible := func(accountIndex, assetId int64) *tx.TxDetail {
+		return &tx.TxDetail{AccountIndex: accountIndex, AssetId: assetId, AssetType: types2.FungibleAssetType}
+	}
+	txs := []*tx.Tx{
+		{TxHash: "a", TxType: types2.TxTypeTransfer, AccountIndex: 1, BlockHeight: 1, TxStatus: tx.StatusVerified,
+			TxDetails: []*tx.TxDetail{fungible(1, 0), fungible(2, 0)}},
+		{TxHash: "b", TxType: types2.TxTypeWithdraw, AccountIndex: 2, BlockHeight: 2, TxStatus: tx.StatusVerified,
+			TxDetails: []*tx.TxDetail{fungible(2, 1)}},
+		{TxHash: "c", TxType: types2.TxTypeTransfer, AccountIndex: 3, BlockHeight: 3, TxStatus: tx.StatusCommitted,
+			TxDetails: []*tx.TxDetail{fungible(3, 1), fungible(1, 1)}},
+	}
+	for i, dbTx := range txs {
+		dbTx.CreatedAt = time.Unix(int64(i+1)*100, 0)
+	}
+	require.NoError(t, db.Create(txs).Error)
+	return txModel
+}
+
+func TestAccountTxFilterOptions(t *testing.T) {
+	txModel := newTestTxModel(t)
+
+	// the zero filter of the query, which does not filter anything but the
+	// direction
+	filter := func(f func(*accountTxFilter)) *accountTxFilter {
+		filter := &accountTxFilter{assetId: -1, counterparty: -1, toHeight: -1}
+		f(filter)
+		return filter
+	}
+	tests := []struct {
+		name   string
+		filter *accountTxFilter
+		hashes []string
+	}{
+		{"outgoing", filter(func(f *accountTxFilter) {}), []string{"a"}},
+		{"incoming", filter(func(f *accountTxFilter) { f.direction = tx.DirectionIncoming }), []string{"c"}},
+		{"all", filter(func(f *accountTxFilter) { f.direction = tx.DirectionAll }), []string{"a", "c"}},
+		{"asset", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.assetId = 1
+		}), []string{"c"}},
+		{"counterparty", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.counterparty = 2
+		}), []string{"a"}},
+		{"statuses", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.statuses = []int64{tx.StatusCommitted}
+		}), []string{"c"}},
+		{"types", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.types = []int64{types2.TxTypeWithdraw}
+		}), nil},
+		{"time range", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.fromTime = 150
+			f.toTime = 300
+		}), []string{"c"}},
+		{"height range", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.fromHeight = 1
+			f.toHeight = 2
+		}), []string{"a"}},
+		{"to height 0", filter(func(f *accountTxFilter) {
+			f.direction = tx.DirectionAll
+			f.toHeight = 0
+		}), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := tt.filter.options()
+			require.NoError(t, err)
+
+			count, err := txModel.GetTxsCountByAccountIndex(1, options...)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.hashes)), count)
+
+			txs, err := txModel.GetTxsWithDetailsByAccountIndex(1, 0, 10, options...)
+			if len(tt.hashes) == 0 {
+				assert.Equal(t, types2.DbErrNotFound, err)
+				return
+			}
+			require.NoError(t, err)
+			hashes := make([]string, 0, len(txs))
+			for _, dbTx := range txs {
+				hashes = append(hashes, dbTx.TxHash)
+				// only the details of the account are loaded
+				for _, detail := range dbTx.TxDetails {
+					assert.Equal(t, int64(1), detail.AccountIndex)
+				}
+			}
+			assert.Equal(t, tt.hashes, hashes)
+		})
+	}
+}
+
+func TestAccountTxFilterInvalidRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *accountTxFilter
+	}{
+		{"negative time", &accountTxFilter{fromTime: -1, toHeight: -1}},
+		{"reversed time", &accountTxFilter{fromTime: 20, toTime: 10, toHeight: -1}},
+		{"reversed height", &accountTxFilter{fromHeight: 20, toHeight: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.filter.options()
+			require.Error(t, err)
+			assert.Equal(t, types2.AppErrInvalidParam.Code(), err.(types2.Error).Code())
+		})
+	}
+
+	// an open time range
+	_, err := (&accountTxFilter{fromTime: 20, toHeight: -1}).options()
+	assert.NoError(t, err)
+}
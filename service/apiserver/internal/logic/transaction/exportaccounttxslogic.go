@@ -0,0 +1,292 @@
+package transaction
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	exportFormatCsv    = "csv"
+	exportFormatNdjson = "ndjson"
+
+	defaultExportMaxTxs = 100000
+	exportBatchSize     = 500
+
+	// exportErrorRecord is the first field of the trailing csv record of a
+	// failed export.
+	exportErrorRecord = "error"
+)
+
+var exportCsvHeader = []string{
+	"hash", "type", "status", "block_height", "created_at", "account_index", "to_account_index",
+	"asset_id", "amount", "gas_fee_asset_id", "gas_fee", "nft_index",
+	"delta_asset_id", "delta", "balance",
+}
+
+type ExportAccountTxsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportAccountTxsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportAccountTxsLogic {
+	return &ExportAccountTxsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type exportBalanceDelta struct {
+	AssetId int64  `json:"asset_id"`
+	Delta   string `json:"delta"`
+	Balance string `json:"balance"`
+}
+
+// exportError is the trailing record of an export which failed after the
+// response is started.
+type exportError struct {
+	Error string `json:"error"`
+}
+
+type exportTx struct {
+	Hash           string                `json:"hash"`
+	Type           int64                 `json:"type"`
+	Status         int64                 `json:"status"`
+	BlockHeight    int64                 `json:"block_height"`
+	CreatedAt      int64                 `json:"created_at"`
+	AccountIndex   int64                 `json:"account_index"`
+	ToAccountIndex int64                 `json:"to_account_index"`
+	AssetId        int64                 `json:"asset_id"`
+	Amount         string                `json:"amount"`
+	GasFeeAssetId  int64                 `json:"gas_fee_asset_id"`
+	GasFee         string                `json:"gas_fee"`
+	NftIndex       int64                 `json:"nft_index"`
+	BalanceDeltas  []*exportBalanceDelta `json:"balance_deltas"`
+}
+
+// ExportAccountTxs writes the txs of an account to w in ascending order. Errors
+// returned before anything is written are reported to the client, a later
+// failure to get the txs is written as a trailing error record.
+func (l *ExportAccountTxsLogic) ExportAccountTxs(req *types.ReqExportAccountTxs, w http.ResponseWriter) error {
+	accountIndex, err := utils.GetAccountIndex(l.svcCtx.MemCache, accountQuery, req.By, req.Value)
+	if err != nil {
+		return err
+	}
+	if req.By == queryByAccountIndex {
+		// only the names and pks are looked up by GetAccountIndex
+		_, err = l.svcCtx.MemCache.GetAccountNameByIndex(accountIndex)
+		if err != nil {
+			if err == types2.DbErrNotFound {
+				return types2.AppErrAccountNotFound
+			}
+			return types2.AppErrInternal
+		}
+	}
+
+	filter := &accountTxFilter{
+		types:        req.Types,
+		assetId:      req.AssetId,
+		direction:    req.Direction,
+		counterparty: req.Counterparty,
+		statuses:     req.Statuses,
+		fromTime:     req.FromTime,
+		toTime:       req.ToTime,
+		fromHeight:   req.FromHeight,
+		toHeight:     req.ToHeight,
+	}
+	options, err := filter.options()
+	if err != nil {
+		return err
+	}
+
+	total, err := l.svcCtx.TxModel.GetTxsCountByAccountIndex(accountIndex, options...)
+	if err != nil {
+		return types2.AppErrInternal
+	}
+	maxTxs := int64(l.svcCtx.Config.TxExport.MaxTxs)
+	if maxTxs <= 0 {
+		maxTxs = defaultExportMaxTxs
+	}
+	if total > maxTxs {
+		return types2.AppErrInvalidParam.RefineError(
+			fmt.Sprintf("too many txs to export: %d, narrow the filters to at most %d", total, maxTxs))
+	}
+
+	// The first page is got before the response is started, so that its
+	// failure is still reported with an error status.
+	txs, err := l.getTxsPage(accountIndex, 0, options)
+	if err != nil {
+		return types2.AppErrInternal
+	}
+
+	filename := fmt.Sprintf("account_%d_txs.%s", accountIndex, req.Format)
+	if req.Format == exportFormatNdjson {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	var write func(*exportTx) error
+	var writeError func(message string) error
+	var flush func() error
+	if req.Format == exportFormatNdjson {
+		encoder := json.NewEncoder(w)
+		write = func(t *exportTx) error { return encoder.Encode(t) }
+		writeError = func(message string) error { return encoder.Encode(&exportError{Error: message}) }
+		flush = func() error { return nil }
+	} else {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportCsvHeader); err != nil {
+			l.Errorf("write export header failed: %s", err.Error())
+			return nil
+		}
+		write = func(t *exportTx) error { return writer.WriteAll(csvRecords(t)) }
+		writeError = func(message string) error { return writer.Write([]string{exportErrorRecord, message}) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	for {
+		for _, dbTx := range txs {
+			if err := write(convertExportTx(dbTx)); err != nil {
+				l.Errorf("write export tx failed: %s", err.Error())
+				return nil
+			}
+		}
+		if err := flush(); err != nil {
+			l.Errorf("flush export txs failed: %s", err.Error())
+			return nil
+		}
+		if len(txs) < exportBatchSize {
+			return nil
+		}
+
+		txs, err = l.getTxsPage(accountIndex, txs[len(txs)-1].ID, options)
+		if err != nil {
+			l.Errorf("get txs of account %d failed: %s", accountIndex, err.Error())
+			if err := writeError(types2.AppErrInternal.Error()); err != nil {
+				l.Errorf("write export error failed: %s", err.Error())
+				return nil
+			}
+			if err := flush(); err != nil {
+				l.Errorf("flush export error failed: %s", err.Error())
+			}
+			return nil
+		}
+	}
+}
+
+// getTxsPage returns the txs with details after the id, no txs are not an
+// error.
+func (l *ExportAccountTxsLogic) getTxsPage(accountIndex int64, fromId uint, options []tx.GetTxOptionFunc) ([]*tx.Tx, error) {
+	txs, err := l.svcCtx.TxModel.GetTxsWithDetailsByAccountIndex(accountIndex, fromId, exportBatchSize, options...)
+	if err == types2.DbErrNotFound {
+		return nil, nil
+	}
+	return txs, err
+}
+
+func convertExportTx(dbTx *tx.Tx) *exportTx {
+	t := utils.ConvertTx(dbTx)
+	return &exportTx{
+		Hash:           t.Hash,
+		Type:           t.Type,
+		Status:         t.Status,
+		BlockHeight:    t.BlockHeight,
+		CreatedAt:      t.CreatedAt,
+		AccountIndex:   t.AccountIndex,
+		ToAccountIndex: t.ToAccountIndex,
+		AssetId:        t.AssetId,
+		Amount:         t.Amount,
+		GasFeeAssetId:  t.GasFeeAssetId,
+		GasFee:         t.GasFee,
+		NftIndex:       t.NftIndex,
+		BalanceDeltas:  balanceDeltas(dbTx.TxDetails),
+	}
+}
+
+// balanceDeltas sums the fungible details of the account per asset, the balance
+// is the one after the last detail of the asset is applied.
+func balanceDeltas(details []*tx.TxDetail) []*exportBalanceDelta {
+	sort.SliceStable(details, func(i, j int) bool {
+		return details[i].Order < details[j].Order
+	})
+
+	deltas := make([]*exportBalanceDelta, 0)
+	sums := make(map[int64]*big.Int)
+	balances := make(map[int64]*big.Int)
+	for _, detail := range details {
+		if detail.AssetType != types2.FungibleAssetType {
+			continue
+		}
+		delta, err := types2.ParseAccountAsset(detail.BalanceDelta)
+		if err != nil {
+			logx.Errorf("parse balance delta of tx detail %d failed: %s", detail.ID, err.Error())
+			continue
+		}
+		balance, err := types2.ParseAccountAsset(detail.Balance)
+		if err != nil {
+			logx.Errorf("parse balance of tx detail %d failed: %s", detail.ID, err.Error())
+			continue
+		}
+		if _, ok := sums[detail.AssetId]; !ok {
+			sums[detail.AssetId] = big.NewInt(0)
+			deltas = append(deltas, &exportBalanceDelta{AssetId: detail.AssetId})
+		}
+		sums[detail.AssetId].Add(sums[detail.AssetId], delta.Balance)
+		balances[detail.AssetId] = new(big.Int).Add(balance.Balance, delta.Balance)
+	}
+	for _, d := range deltas {
+		d.Delta = sums[d.AssetId].String()
+		d.Balance = balances[d.AssetId].String()
+	}
+	return deltas
+}
+
+// csvRecords returns a row per balance delta, or a single row without delta if
+// the tx doesn't change the balances of the account.
+func csvRecords(t *exportTx) [][]string {
+	row := []string{
+		t.Hash,
+		strconv.FormatInt(t.Type, 10),
+		strconv.FormatInt(t.Status, 10),
+		strconv.FormatInt(t.BlockHeight, 10),
+		strconv.FormatInt(t.CreatedAt, 10),
+		strconv.FormatInt(t.AccountIndex, 10),
+		strconv.FormatInt(t.ToAccountIndex, 10),
+		strconv.FormatInt(t.AssetId, 10),
+		t.Amount,
+		strconv.FormatInt(t.GasFeeAssetId, 10),
+		t.GasFee,
+		strconv.FormatInt(t.NftIndex, 10),
+	}
+	if len(t.BalanceDeltas) == 0 {
+		return [][]string{append(row, "", "", "")}
+	}
+	records := make([][]string, 0, len(t.BalanceDeltas))
+	for _, d := range t.BalanceDeltas {
+		record := make([]string, len(row), len(row)+3)
+		copy(record, row)
+		records = append(records, append(record, strconv.FormatInt(d.AssetId, 10), d.Delta, d.Balance))
+	}
+	return records
+}
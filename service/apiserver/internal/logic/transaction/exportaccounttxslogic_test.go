@@ -0,0 +1,167 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+type fakeAccountModel struct {
+	account.AccountModel
+}
+
+func (m *fakeAccountModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	if accountIndex != 1 {
+		return nil, types2.DbErrNotFound
+	}
+	return &account.Account{AccountIndex: 1, AccountName: "alice.legend"}, nil
+}
+
+// fakeTxModel has the given number of txs and fails to get the pages from
+// failingPage on.
+type fakeTxModel struct {
+	tx.TxModel
+	total       int
+	failingPage int
+}
+
+func (m *fakeTxModel) GetTxsCountByAccountIndex(accountIndex int64, options ...tx.GetTxOptionFunc) (int64, error) {
+	return int64(m.total), nil
+}
+
+func (m *fakeTxModel) GetTxsWithDetailsByAccountIndex(accountIndex int64, fromId uint, limit int64, options ...tx.GetTxOptionFunc) ([]*tx.Tx, error) {
+	if int(fromId)/exportBatchSize >= m.failingPage {
+		return nil, types2.DbErrSqlOperation
+	}
+	txs := make([]*tx.Tx, 0, limit)
+	for id := int(fromId) + 1; id <= m.total && len(txs) < int(limit); id++ {
+		dbTx := &tx.Tx{TxHash: fmt.Sprintf("%d", id), AccountIndex: accountIndex}
+		dbTx.ID = uint(id)
+		txs = append(txs, dbTx)
+	}
+	if len(txs) == 0 {
+		return nil, types2.DbErrNotFound
+	}
+	return txs, nil
+}
+
+func TestExportAccountTxs(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		format   string
+		txModel  *fakeTxModel
+		err      error
+		lines    int
+		lastLine string
+	}{
+		{"account not found", "2", exportFormatCsv, &fakeTxModel{}, types2.AppErrAccountNotFound, 0, ""},
+		{"first page fails", "1", exportFormatCsv, &fakeTxModel{total: 10}, types2.AppErrInternal, 0, ""},
+		{"no txs", "1", exportFormatCsv, &fakeTxModel{total: 0, failingPage: 1}, nil, 1, "hash,type,status"},
+		{"csv", "1", exportFormatCsv, &fakeTxModel{total: exportBatchSize + 1, failingPage: 2}, nil, exportBatchSize + 2, "501,"},
+		{"ndjson", "1", exportFormatNdjson, &fakeTxModel{total: exportBatchSize, failingPage: 2}, nil, exportBatchSize, `{"hash":"500",`},
+		// the failure of a later page is the last record
+		{"csv page fails", "1", exportFormatCsv, &fakeTxModel{total: exportBatchSize + 1, failingPage: 1}, nil,
+			exportBatchSize + 2, "error," + types2.AppErrInternal.Error()},
+		{"ndjson page fails", "1", exportFormatNdjson, &fakeTxModel{total: exportBatchSize + 1, failingPage: 1}, nil,
+			exportBatchSize + 1, `{"error":"` + types2.AppErrInternal.Error() + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcCtx := &svc.ServiceContext{
+				MemCache: cache.MustNewMemCache(&fakeAccountModel{}, nil, 10, 10, 10, 10, 10, 100, 100),
+				TxModel:  tt.txModel,
+			}
+			w := httptest.NewRecorder()
+			err := NewExportAccountTxsLogic(context.Background(), svcCtx).ExportAccountTxs(&types.ReqExportAccountTxs{
+				By:           queryByAccountIndex,
+				Value:        tt.value,
+				Format:       tt.format,
+				AssetId:      -1,
+				Counterparty: -1,
+				ToHeight:     -1,
+			}, w)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+				// the error is still reported with its status by the handler
+				assert.False(t, w.Flushed)
+				assert.Empty(t, w.Header().Get("Content-Disposition"))
+				assert.Zero(t, w.Body.Len())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+			assert.Len(t, lines, tt.lines)
+			assert.True(t, strings.HasPrefix(lines[len(lines)-1], tt.lastLine), lines[len(lines)-1])
+		})
+	}
+}
+
+func TestBalanceDeltas(t *testing.T) {
+	fungible := func(order, assetId, balance, delta int64) *tx.TxDetail {
+		return &tx.TxDetail{
+			AssetId:   assetId,
+			AssetType: types2.FungibleAssetType,
+			Balance: (&types2.AccountAsset{
+				AssetId: assetId, Balance: big.NewInt(balance), OfferCanceledOrFinalized: big.NewInt(0),
+			}).String(),
+			BalanceDelta: (&types2.AccountAsset{
+				AssetId: assetId, Balance: big.NewInt(delta), OfferCanceledOrFinalized: big.NewInt(0),
+			}).String(),
+			Order: order,
+		}
+	}
+
+	// the amount and the gas fee of asset 0 are paid, asset 1 is received,
+	// the details are not in the order they are applied
+	details := []*tx.TxDetail{
+		fungible(2, 0, 90, -1),
+		fungible(1, 1, 5, 5),
+		{AssetId: 3, AssetType: types2.NftAssetType, Order: 3},
+		fungible(0, 0, 100, -10),
+		{AssetId: 2, AssetType: types2.FungibleAssetType, Balance: "invalid", BalanceDelta: "invalid", Order: 4},
+	}
+	deltas := balanceDeltas(details)
+	assert.Equal(t, []*exportBalanceDelta{
+		{AssetId: 0, Delta: "-11", Balance: "89"},
+		{AssetId: 1, Delta: "5", Balance: "10"},
+	}, deltas)
+
+	assert.Empty(t, balanceDeltas(nil))
+}
+
+func TestCsvRecords(t *testing.T) {
+	exported := &exportTx{Hash: "a", Type: types2.TxTypeTransfer, Amount: "1", GasFee: "2", NftIndex: -1}
+	records := csvRecords(exported)
+	assert.Len(t, records, 1)
+	assert.Len(t, records[0], len(exportCsvHeader))
+	assert.Equal(t, []string{"", "", ""}, records[0][len(exportCsvHeader)-3:])
+
+	// a row per changed asset
+	exported.BalanceDeltas = []*exportBalanceDelta{
+		{AssetId: 0, Delta: "-3", Balance: "7"},
+		{AssetId: 1, Delta: "1", Balance: "1"},
+	}
+	records = csvRecords(exported)
+	assert.Len(t, records, 2)
+	for i, record := range records {
+		assert.Len(t, record, len(exportCsvHeader))
+		assert.Equal(t, "a", record[0])
+		assert.Equal(t, exported.BalanceDeltas[i].Delta, record[len(exportCsvHeader)-2])
+	}
+}
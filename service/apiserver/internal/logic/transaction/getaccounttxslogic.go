@@ -2,7 +2,6 @@ package transaction
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -19,6 +18,8 @@ const (
 	queryByAccountPk    = "account_pk"
 )
 
+var accountQuery = utils.AccountQuery{ByIndex: queryByAccountIndex, ByName: queryByAccountName, ByPk: queryByAccountPk}
+
 type GetAccountTxsLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -38,31 +39,28 @@ func (l *GetAccountTxsLogic) GetAccountTxs(req *types.ReqGetAccountTxs) (resp *t
 		Txs: make([]*types.Tx, 0, req.Limit),
 	}
 
-	accountIndex := int64(0)
-	switch req.By {
-	case queryByAccountIndex:
-		accountIndex, err = strconv.ParseInt(req.Value, 10, 64)
-		if err != nil || accountIndex < 0 {
-			return nil, types2.AppErrInvalidAccountIndex
-		}
-	case queryByAccountName:
-		accountIndex, err = l.svcCtx.MemCache.GetAccountIndexByName(req.Value)
-	case queryByAccountPk:
-		accountIndex, err = l.svcCtx.MemCache.GetAccountIndexByPk(req.Value)
-	default:
-		return nil, types2.AppErrInvalidParam.RefineError("param by should be account_index|account_name|account_pk")
-	}
-
+	accountIndex, err := utils.GetAccountIndex(l.svcCtx.MemCache, accountQuery, req.By, req.Value)
 	if err != nil {
-		if err == types2.DbErrNotFound {
+		if err == types2.AppErrAccountNotFound {
 			return resp, nil
 		}
-		return nil, types2.AppErrInternal
+		return nil, err
 	}
 
-	options := []tx.GetTxOptionFunc{}
-	if len(req.Types) > 0 {
-		options = append(options, tx.GetTxWithTypes(req.Types))
+	filter := &accountTxFilter{
+		types:        req.Types,
+		assetId:      req.AssetId,
+		direction:    req.Direction,
+		counterparty: req.Counterparty,
+		statuses:     req.Statuses,
+		fromTime:     req.FromTime,
+		toTime:       req.ToTime,
+		fromHeight:   req.FromHeight,
+		toHeight:     req.ToHeight,
+	}
+	options, err := filter.options()
+	if err != nil {
+		return nil, err
 	}
 
 	total, err := l.svcCtx.TxModel.GetTxsCountByAccountIndex(accountIndex, options...)
@@ -71,12 +69,25 @@ func (l *GetAccountTxsLogic) GetAccountTxs(req *types.ReqGetAccountTxs) (resp *t
 	}
 
 	resp.Total = uint32(total)
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	txs, err := l.svcCtx.TxModel.GetTxsByAccountIndex(accountIndex, int64(req.Limit), int64(req.Offset), options...)
+	offset := int64(req.Offset)
+	if req.Cursor != "" {
+		beforeCreatedAt, beforeId, err := utils.DecodeTxCursor(utils.CursorTx, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		options = append(options, tx.GetTxWithBefore(beforeCreatedAt, beforeId))
+	}
+
+	txs, err := l.svcCtx.TxModel.GetTxsByAccountIndex(accountIndex, int64(req.Limit), offset, options...)
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 
@@ -89,5 +100,9 @@ func (l *GetAccountTxsLogic) GetAccountTxs(req *types.ReqGetAccountTxs) (resp *t
 		}
 		resp.Txs = append(resp.Txs, tx)
 	}
+	// pages by the deprecated offset don't continue with a cursor
+	if req.Cursor != "" || req.Offset == 0 {
+		resp.NextCursor = utils.NextTxCursor(utils.CursorTx, txs, int(req.Limit))
+	}
 	return resp, nil
 }
@@ -51,7 +51,17 @@ func (l *GetExecutedTxsLogic) GetExecutedTxs(req *types.ReqGetRangeWithFromHash)
 		return resp, nil
 	}
 
-	pendingTxs, err := l.svcCtx.TxPoolModel.GetTxs(int64(req.Limit), int64(req.Offset), options...)
+	offset := int64(req.Offset)
+	if req.Cursor != "" {
+		beforeCreatedAt, beforeId, err := utils.DecodeTxCursor(utils.CursorPoolTx, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		options = append(options, tx.GetTxWithBefore(beforeCreatedAt, beforeId))
+	}
+
+	pendingTxs, err := l.svcCtx.TxPoolModel.GetTxs(int64(req.Limit), offset, options...)
 	if err != nil {
 		return nil, types2.AppErrInternal
 	}
@@ -64,5 +74,9 @@ func (l *GetExecutedTxsLogic) GetExecutedTxs(req *types.ReqGetRangeWithFromHash)
 		}
 		resp.Txs = append(resp.Txs, tx)
 	}
+	// pages by the deprecated offset don't continue with a cursor
+	if req.Cursor != "" || req.Offset == 0 {
+		resp.NextCursor = utils.NextTxCursor(utils.CursorPoolTx, pendingTxs, int(req.Limit))
+	}
 	return resp, nil
 }
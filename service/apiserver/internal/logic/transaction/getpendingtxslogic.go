@@ -28,9 +28,11 @@ func NewGetPendingTxsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Get
 
 func (l *GetPendingTxsLogic) GetPendingTxs(req *types.ReqGetRange) (*types.Txs, error) {
 
-	txStatuses := []int64{tx.StatusPending}
+	options := []tx.GetTxOptionFunc{
+		tx.GetTxWithStatuses([]int64{tx.StatusPending}),
+	}
 
-	total, err := l.svcCtx.TxPoolModel.GetTxsTotalCount(tx.GetTxWithStatuses(txStatuses))
+	total, err := l.svcCtx.TxPoolModel.GetTxsTotalCount(options...)
 	if err != nil {
 		if err != types2.DbErrNotFound {
 			return nil, types2.AppErrInternal
@@ -45,7 +47,17 @@ func (l *GetPendingTxsLogic) GetPendingTxs(req *types.ReqGetRange) (*types.Txs,
 		return resp, nil
 	}
 
-	pendingTxs, err := l.svcCtx.TxPoolModel.GetTxs(int64(req.Limit), int64(req.Offset), tx.GetTxWithStatuses(txStatuses))
+	offset := int64(req.Offset)
+	if req.Cursor != "" {
+		beforeCreatedAt, beforeId, err := utils.DecodeTxCursor(utils.CursorPoolTx, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		options = append(options, tx.GetTxWithBefore(beforeCreatedAt, beforeId))
+	}
+
+	pendingTxs, err := l.svcCtx.TxPoolModel.GetTxs(int64(req.Limit), offset, options...)
 	if err != nil {
 		return nil, types2.AppErrInternal
 	}
@@ -58,5 +70,9 @@ func (l *GetPendingTxsLogic) GetPendingTxs(req *types.ReqGetRange) (*types.Txs,
 		}
 		resp.Txs = append(resp.Txs, tx)
 	}
+	// pages by the deprecated offset don't continue with a cursor
+	if req.Cursor != "" || req.Offset == 0 {
+		resp.NextCursor = utils.NextTxCursor(utils.CursorPoolTx, pendingTxs, int(req.Limit))
+	}
 	return resp, nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/dao/tx"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/logic/utils"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/svc"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
@@ -37,12 +38,26 @@ func (l *GetTxsLogic) GetTxs(req *types.ReqGetRange) (resp *types.Txs, err error
 		Total: uint32(total),
 		Txs:   make([]*types.Tx, 0, req.Limit),
 	}
-	if total == 0 || total <= int64(req.Offset) {
+	if total == 0 || (req.Cursor == "" && total <= int64(req.Offset)) {
 		return resp, nil
 	}
 
-	txs, err := l.svcCtx.TxModel.GetTxs(int64(req.Limit), int64(req.Offset))
+	offset := int64(req.Offset)
+	options := make([]tx.GetTxOptionFunc, 0)
+	if req.Cursor != "" {
+		beforeCreatedAt, beforeId, err := utils.DecodeTxCursor(utils.CursorTx, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		options = append(options, tx.GetTxWithBefore(beforeCreatedAt, beforeId))
+	}
+
+	txs, err := l.svcCtx.TxModel.GetTxs(int64(req.Limit), offset, options...)
 	if err != nil {
+		if err == types2.DbErrNotFound {
+			return resp, nil
+		}
 		return nil, types2.AppErrInternal
 	}
 	for _, dbTx := range txs {
@@ -54,6 +69,10 @@ func (l *GetTxsLogic) GetTxs(req *types.ReqGetRange) (resp *types.Txs, err error
 		}
 		resp.Txs = append(resp.Txs, tx)
 	}
+	// pages by the deprecated offset don't continue with a cursor
+	if req.Cursor != "" || req.Offset == 0 {
+		resp.NextCursor = utils.NextTxCursor(utils.CursorTx, txs, int(req.Limit))
+	}
 
 	return resp, nil
 }
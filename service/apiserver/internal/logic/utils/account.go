@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+// AccountQuery are the values of the by param of an api which queries an
+// account by its index, name or pk.
+type AccountQuery struct {
+	ByIndex string
+	ByName  string
+	ByPk    string
+}
+
+// GetAccountIndex resolves the index of the account queried by value, it
+// returns types.AppErrAccountNotFound if there is no such account. The
+// existence of an account queried by its index is not checked.
+func GetAccountIndex(memCache *cache.MemCache, query AccountQuery, by, value string) (index int64, err error) {
+	switch by {
+	case query.ByIndex:
+		index, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || index < 0 {
+			return 0, types2.AppErrInvalidAccountIndex
+		}
+		return index, nil
+	case query.ByName:
+		index, err = memCache.GetAccountIndexByName(value)
+	case query.ByPk:
+		index, err = memCache.GetAccountIndexByPk(value)
+	default:
+		return 0, types2.AppErrInvalidParam.RefineError(
+			fmt.Sprintf("param by should be %s|%s|%s", query.ByIndex, query.ByName, query.ByPk))
+	}
+
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return 0, types2.AppErrAccountNotFound
+		}
+		return 0, types2.AppErrInternal
+	}
+	return index, nil
+}
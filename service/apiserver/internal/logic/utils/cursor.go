@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	types2 "github.com/bnb-chain/zkbnb/types"
+)
+
+// Kinds of cursors, a cursor is only accepted by the lists of its kind.
+const (
+	CursorTx      = "tx"
+	CursorPoolTx  = "pool_tx"
+	CursorBlock   = "block"
+	CursorAccount = "account"
+	CursorAsset   = "asset"
+	CursorNft     = "nft"
+)
+
+// EncodeCursor returns an opaque cursor holding the key of the last row of a
+// page, the next page starts right after it.
+func EncodeCursor(kind string, key int64) string {
+	return encodeCursor(kind, key)
+}
+
+// DecodeCursor returns the key held by the cursor.
+func DecodeCursor(kind string, cursor string) (int64, error) {
+	keys, err := decodeCursor(kind, cursor, 1)
+	if err != nil {
+		return 0, err
+	}
+	return keys[0], nil
+}
+
+// NextCursor returns the cursor of the next page, empty if the page isn't full
+// so there are no more rows.
+func NextCursor(kind string, lastKey int64, count, limit int) string {
+	if count == 0 || count < limit {
+		return ""
+	}
+	return EncodeCursor(kind, lastKey)
+}
+
+// DecodeTxCursor returns the creation time and the id of the last tx of the
+// previous page, txs are listed by both.
+func DecodeTxCursor(kind string, cursor string) (createdAt time.Time, id uint, err error) {
+	keys, err := decodeCursor(kind, cursor, 2)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.Unix(0, keys[0]).UTC(), uint(keys[1]), nil
+}
+
+// NextTxCursor returns the cursor of the next page of txs, empty if the page
+// isn't full so there are no more txs.
+func NextTxCursor(kind string, txs []*tx.Tx, limit int) string {
+	if len(txs) == 0 || len(txs) < limit {
+		return ""
+	}
+	lastTx := txs[len(txs)-1]
+	return encodeCursor(kind, lastTx.CreatedAt.UnixNano(), int64(lastTx.ID))
+}
+
+func encodeCursor(kind string, keys ...int64) string {
+	parts := make([]string, 0, len(keys)+1)
+	parts = append(parts, kind)
+	for _, key := range keys {
+		parts = append(parts, strconv.FormatInt(key, 10))
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, ":")))
+}
+
+func decodeCursor(kind string, cursor string, n int) ([]int64, error) {
+	invalidCursor := types2.AppErrInvalidParam.RefineError("invalid cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalidCursor
+	}
+	parts := strings.Split(string(data), ":")
+	if len(parts) != n+1 || parts[0] != kind {
+		return nil, invalidCursor
+	}
+	keys := make([]int64, 0, n)
+	for _, part := range parts[1:] {
+		key, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || key < 0 {
+			return nil, invalidCursor
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/dbcache"
 	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/stats"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
 	"github.com/bnb-chain/zkbnb/service/apiserver/internal/cache"
@@ -32,8 +33,10 @@ type ServiceContext struct {
 	TxModel             tx.TxModel
 	BlockModel          block.BlockModel
 	NftModel            nft.L2NftModel
+	NftHistoryModel     nft.L2NftHistoryModel
 	AssetModel          asset.AssetModel
 	SysConfigModel      sysconfig.SysConfigModel
+	StatModel           stats.StatModel
 
 	PriceFetcher price.Fetcher
 	StateFetcher state.Fetcher
@@ -52,14 +55,30 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	rawDB.SetMaxOpenConns(c.Postgres.MaxConn)
 	rawDB.SetMaxIdleConns(c.Postgres.MaxIdle)
 
-	redisCache := dbcache.NewRedisCache(c.CacheRedis[0].Host, c.CacheRedis[0].Pass, 15*time.Minute)
+	redisCache, err := dbcache.NewCache(c.CacheDriver, c.CacheRedis, 15*time.Minute)
+	if err != nil {
+		logx.Must(err)
+	}
 
 	txPoolModel := tx.NewTxPoolModel(db)
 	accountModel := account.NewAccountModel(db)
 	nftModel := nft.NewL2NftModel(db)
+	nftHistoryModel := nft.NewL2NftHistoryModel(db)
+	accountHistoryModel := account.NewAccountHistoryModel(db)
+	blockModel := block.NewBlockModel(db)
 	assetModel := asset.NewAssetModel(db)
 	memCache := cache.MustNewMemCache(accountModel, assetModel, c.MemCache.AccountExpiration, c.MemCache.BlockExpiration,
 		c.MemCache.TxExpiration, c.MemCache.AssetExpiration, c.MemCache.PriceExpiration, c.MemCache.MaxCounterNum, c.MemCache.MaxKeyNum)
+	priceOracle := c.PriceOracle
+	if len(priceOracle.Providers) == 0 {
+		priceOracle.Providers = []price.ProviderConfig{
+			{Name: price.SourceCoinMarketCap, Url: c.CoinMarketCap.Url, Token: c.CoinMarketCap.Token},
+		}
+	}
+	priceFetcher, err := price.NewFetcher(memCache, assetModel, priceOracle, c.QuoteCurrencies)
+	if err != nil {
+		logx.Must(err)
+	}
 	return &ServiceContext{
 		Config:              c,
 		RedisCache:          redisCache,
@@ -67,15 +86,17 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		DB:                  db,
 		TxPoolModel:         txPoolModel,
 		AccountModel:        accountModel,
-		AccountHistoryModel: account.NewAccountHistoryModel(db),
+		AccountHistoryModel: accountHistoryModel,
 		TxModel:             tx.NewTxModel(db),
-		BlockModel:          block.NewBlockModel(db),
+		BlockModel:          blockModel,
 		NftModel:            nftModel,
+		NftHistoryModel:     nftHistoryModel,
 		AssetModel:          assetModel,
 		SysConfigModel:      sysconfig.NewSysConfigModel(db),
+		StatModel:           stats.NewStatModel(db),
 
-		PriceFetcher: price.NewFetcher(memCache, assetModel, c.CoinMarketCap.Url, c.CoinMarketCap.Token),
-		StateFetcher: state.NewFetcher(redisCache, accountModel, nftModel),
+		PriceFetcher: priceFetcher,
+		StateFetcher: state.NewFetcher(redisCache, accountModel, accountHistoryModel, nftModel, nftHistoryModel, blockModel),
 	}
 }
 
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestCursorPagination() {
+	s.T().Run("blocks", func(t *testing.T) {
+		httpCode, first := GetBlocksByCursor(s, "", 2)
+		assert.Equal(t, http.StatusOK, httpCode)
+		if first.NextCursor == "" {
+			return
+		}
+		_, byOffset := GetBlocks(s, 2, 2)
+		httpCode, second := GetBlocksByCursor(s, first.NextCursor, 2)
+		assert.Equal(t, http.StatusOK, httpCode)
+		assert.True(t, len(second.Blocks) > 0)
+		assert.True(t, second.Blocks[0].Height < first.Blocks[len(first.Blocks)-1].Height)
+		assert.Equal(t, byOffset.Blocks[0].Height, second.Blocks[0].Height)
+	})
+
+	s.T().Run("txs", func(t *testing.T) {
+		seen := make(map[string]bool)
+		cursor := ""
+		for i := 0; i < 5; i++ {
+			httpCode, result := GetTxsByCursor(s, cursor, 2)
+			assert.Equal(t, http.StatusOK, httpCode)
+			for _, tx := range result.Txs {
+				assert.False(t, seen[tx.Hash])
+				seen[tx.Hash] = true
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+	})
+
+	s.T().Run("invalid cursor", func(t *testing.T) {
+		httpCode, _ := GetTxsByCursor(s, "invalid", 2)
+		assert.Equal(t, http.StatusBadRequest, httpCode)
+	})
+
+	s.T().Run("cursor of another list", func(t *testing.T) {
+		_, blocks := GetBlocksByCursor(s, "", 1)
+		if blocks.NextCursor == "" {
+			return
+		}
+		httpCode, _ := GetTxsByCursor(s, blocks.NextCursor, 2)
+		assert.Equal(t, http.StatusBadRequest, httpCode)
+	})
+}
+
+func GetBlocksByCursor(s *ApiServerSuite, cursor string, limit int) (int, *types.Blocks) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/blocks?cursor=%s&limit=%d", s.url, cursor, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Blocks{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
+
+func GetTxsByCursor(s *ApiServerSuite, cursor string, limit int) (int, *types.Txs) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/txs?cursor=%s&limit=%d", s.url, cursor, limit))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Txs{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *ApiServerSuite) TestExportAccountTxs() {
+	type args struct {
+		by     string
+		value  string
+		format string
+		query  string
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found by index", args{"account_index", "99999999", "csv", ""}, 400},
+		{"invalid by", args{"invalidby", "99999999", "csv", ""}, 400},
+		{"invalid format", args{"account_index", "0", "xml", ""}, 400},
+		{"invalid direction", args{"account_index", "0", "csv", "&direction=sideways"}, 400},
+		{"invalid time range", args{"account_index", "0", "csv", "&from_time=20&to_time=10"}, 400},
+		{"invalid height range", args{"account_index", "0", "csv", "&from_height=20&to_height=10"}, 400},
+	}
+
+	statusCode, txs := GetTxs(s, 0, 100)
+	if statusCode == http.StatusOK && len(txs.Txs) > 0 {
+		tx := txs.Txs[len(txs.Txs)-1]
+		index := strconv.Itoa(int(tx.AccountIndex))
+		tests = append(tests, []testcase{
+			{"csv", args{"account_index", index, "csv", ""}, 200},
+			{"ndjson", args{"account_index", index, "ndjson", ""}, 200},
+			{"all directions", args{"account_index", index, "csv", "&direction=all"}, 200},
+			{"by asset", args{"account_index", index, "ndjson", fmt.Sprintf("&asset_id=%d", tx.AssetId)}, 200},
+			{"by height", args{"account_index", index, "csv", fmt.Sprintf("&from_height=%d&to_height=%d", tx.BlockHeight, tx.BlockHeight)}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, contentType, body := ExportAccountTxs(s, tt.args.by, tt.args.value, tt.args.format, tt.args.query)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				lines := strings.Split(strings.TrimSpace(body), "\n")
+				if tt.args.format == "csv" {
+					assert.Equal(t, "text/csv", contentType)
+					assert.True(t, strings.HasPrefix(lines[0], "hash,type,status"))
+				} else {
+					assert.Equal(t, "application/x-ndjson", contentType)
+				}
+				fmt.Printf("result: %d lines \n", len(lines))
+			}
+		})
+	}
+
+}
+
+func ExportAccountTxs(s *ApiServerSuite, by, value, format, query string) (int, string, string) {
+	url := fmt.Sprintf("%s/api/v1/accountTxsExport?by=%s&value=%s&format=%s%s", s.url, by, value, format, query)
+	resp, err := http.Get(url)
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+}
@@ -57,8 +57,41 @@ func (s *ApiServerSuite) TestGetAccount() {
 
 }
 
+func (s *ApiServerSuite) TestGetAccountAtHeight() {
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode != http.StatusOK || len(accounts.Accounts) == 0 {
+		return
+	}
+	index := strconv.Itoa(int(accounts.Accounts[0].Index))
+
+	httpCode, _ := GetAccountAtHeight(s, "index", index, -2)
+	assert.Equal(s.T(), 400, httpCode)
+	httpCode, _ = GetAccountAtHeight(s, "index", index, 9999999999)
+	assert.Equal(s.T(), 400, httpCode)
+
+	_, height := GetCurrentHeight(s)
+	if height.Height < 1 {
+		return
+	}
+	// the current block may be still proposed
+	httpCode, result := GetAccountAtHeight(s, "index", index, height.Height-1)
+	if httpCode == http.StatusOK {
+		assert.Equal(s.T(), accounts.Accounts[0].Name, result.Name)
+		assert.True(s.T(), result.Nonce >= 0)
+		fmt.Printf("result: %+v \n", result)
+	}
+}
+
 func GetAccount(s *ApiServerSuite, by, value string) (int, *types.Account) {
-	resp, err := http.Get(fmt.Sprintf("%s/api/v1/account?by=%s&value=%s", s.url, by, value))
+	return getAccount(s, fmt.Sprintf("%s/api/v1/account?by=%s&value=%s", s.url, by, value))
+}
+
+func GetAccountAtHeight(s *ApiServerSuite, by, value string, height int64) (int, *types.Account) {
+	return getAccount(s, fmt.Sprintf("%s/api/v1/account?by=%s&value=%s&height=%d", s.url, by, value, height))
+}
+
+func getAccount(s *ApiServerSuite, url string) (int, *types.Account) {
+	resp, err := http.Get(url)
 	assert.NoError(s.T(), err)
 	defer resp.Body.Close()
 
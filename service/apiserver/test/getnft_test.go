@@ -0,0 +1,74 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetNft() {
+	type args struct {
+		index  int64
+		height int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"invalid index", args{-1, -1}, 400},
+		{"not found", args{9999999999, -1}, 400},
+		{"invalid height", args{0, -2}, 400},
+		{"future height", args{0, 9999999999}, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK {
+		for _, account := range accounts.Accounts {
+			statusCode, nfts := GetAccountNfts(s, "account_index", strconv.Itoa(int(account.Index)), 0, 1)
+			if statusCode == http.StatusOK && len(nfts.Nfts) > 0 {
+				tests = append(tests, testcase{"found", args{nfts.Nfts[0].Index, -1}, 200})
+				break
+			}
+		}
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetNft(s, tt.args.index, tt.args.height)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.index, result.Index)
+				assert.NotEmpty(t, result.ContentHash)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+}
+
+func GetNft(s *ApiServerSuite, index, height int64) (int, *types.Nft) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/nft?index=%d&height=%d", s.url, index, height))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Nft{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetPortfolio() {
+	type args struct {
+		by       string
+		values   []string
+		currency string
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found by index", args{"index", []string{"9999999999"}, ""}, 400},
+		{"not found by name", args{"name", []string{"not exist name"}, ""}, 400},
+		{"invalid by", args{"invalidby", []string{"1"}, ""}, 400},
+		{"no account", args{"index", []string{}, ""}, 400},
+		{"too many accounts", args{"index", make([]string, 21), ""}, 400},
+		{"invalid currency", args{"index", []string{"0"}, "XYZ"}, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 1 {
+		tests = append(tests, []testcase{
+			{"one account", args{"index", []string{strconv.Itoa(int(accounts.Accounts[0].Index))}, ""}, 200},
+			{"many accounts", args{"name", []string{accounts.Accounts[0].Name, accounts.Accounts[1].Name}, "usd"}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetPortfolio(s, tt.args.by, tt.args.values, tt.args.currency)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, "USD", result.Currency)
+				assert.Equal(t, len(tt.args.values), len(result.Accounts))
+				totalValue := new(big.Rat)
+				for _, account := range result.Accounts {
+					for _, asset := range account.Assets {
+						assert.NotEqual(t, "0", asset.Balance)
+						if asset.PriceSource == "" {
+							assert.Equal(t, "0", asset.Value)
+						}
+					}
+					accountValue, ok := new(big.Rat).SetString(account.TotalValue)
+					assert.True(t, ok)
+					totalValue.Add(totalValue, accountValue)
+				}
+				assert.True(t, totalValue.Sign() >= 0)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetPortfolio(s *ApiServerSuite, by string, values []string, currency string) (int, *types.Portfolio) {
+	data, _ := json.Marshal(values)
+	query := url.Values{}
+	query.Set("by", by)
+	query.Set("values", string(data))
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/portfolio?%s", s.url, query.Encode()))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Portfolio{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
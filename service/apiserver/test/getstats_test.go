@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetStats() {
+	type args struct {
+		from        int64
+		to          int64
+		granularity string
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+		points   int
+	}
+
+	day := time.Date(2022, 9, 5, 0, 0, 0, 0, time.UTC)
+	tests := []testcase{
+		{"hourly", args{day.Unix(), day.Add(24 * time.Hour).Unix(), "hour"}, 200, 24},
+		{"daily", args{day.Unix(), day.AddDate(0, 0, 7).Unix(), "day"}, 200, 7},
+		{"weekly", args{day.Unix(), day.AddDate(0, 0, 14).Unix(), "week"}, 200, 2},
+		{"monthly", args{day.Unix(), day.AddDate(0, 2, 0).Unix(), "month"}, 200, 3},
+		{"until now", args{time.Now().Add(-time.Hour).Unix(), 0, "hour"}, 200, 0},
+		{"invalid range", args{day.Unix(), day.Add(-time.Hour).Unix(), "day"}, 400, 0},
+		{"too many points", args{day.Unix(), day.AddDate(1, 0, 0).Unix(), "hour"}, 400, 0},
+		{"invalid granularity", args{day.Unix(), day.Add(time.Hour).Unix(), "minute"}, 400, 0},
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetStats(s, tt.args.from, tt.args.to, tt.args.granularity)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.granularity, result.Granularity)
+				if tt.points > 0 {
+					assert.Equal(t, tt.points, len(result.Points))
+				}
+				for _, point := range result.Points {
+					var count int64
+					for _, txTypeCount := range point.TxCountByType {
+						count += txTypeCount.Count
+					}
+					assert.Equal(t, point.TxCount, count)
+				}
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetStats(s *ApiServerSuite, from, to int64, granularity string) (int, *types.Stats) {
+	url := fmt.Sprintf("%s/api/v1/stats?from=%d&granularity=%s", s.url, from, granularity)
+	if to > 0 {
+		url += fmt.Sprintf("&to=%d", to)
+	}
+	resp, err := http.Get(url)
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.Stats{}
+	//nolint:errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -10,7 +10,9 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/zkbnb/core"
+	"github.com/bnb-chain/zkbnb/core/statedb"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockcheckpoint"
 	"github.com/bnb-chain/zkbnb/dao/tx"
 	"github.com/bnb-chain/zkbnb/types"
 )
@@ -224,7 +226,14 @@ func (c *Committer) Run() {
 			if err != nil {
 				return err
 			}
-			return c.bc.TxPoolModel.DeleteTxsInTransact(dbTx, pendingDeletePoolTxs)
+			err = c.bc.TxPoolModel.DeleteTxsInTransact(dbTx, pendingDeletePoolTxs)
+			if err != nil {
+				return err
+			}
+			if len(pendingUpdatePoolTxs) == 0 {
+				return nil
+			}
+			return c.saveCheckpointInTransact(dbTx, curBlock)
 		})
 		if err != nil {
 			panic("update tx pool failed: " + err.Error())
@@ -247,6 +256,7 @@ func (c *Committer) Run() {
 
 func (c *Committer) Shutdown() {
 	c.running = false
+	c.bc.TreePruner.Stop()
 	c.bc.Statedb.Close()
 	c.bc.ChainDB.Close()
 }
@@ -274,6 +284,14 @@ func (c *Committer) restoreExecutedTxs() (*block.Block, error) {
 		return curBlock, nil
 	}
 
+	restored, err := c.restoreCheckpoint(curBlock, executedTxs)
+	if err != nil {
+		return nil, err
+	}
+	if restored {
+		return curBlock, nil
+	}
+
 	if err := c.bc.StateDB().MarkGasAccountAsPending(); err != nil {
 		return nil, err
 	}
@@ -287,6 +305,51 @@ func (c *Committer) restoreExecutedTxs() (*block.Block, error) {
 	return curBlock, nil
 }
 
+// restoreCheckpoint restores the state of the proposing block from its
+// checkpoints, it returns false if the executed txs must be applied again.
+func (c *Committer) restoreCheckpoint(curBlock *block.Block, executedTxs []*tx.Tx) (bool, error) {
+	if len(executedTxs) == 0 {
+		return false, nil
+	}
+	checkpoints, err := c.bc.BlockCheckpointModel.GetBlockCheckpoints(curBlock.BlockHeight)
+	if err == types.DbErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	states := make([][]byte, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		states = append(states, []byte(checkpoint.State))
+	}
+	err = c.bc.StateDB().RestoreCheckpoints(states, executedTxs, curBlock.BlockHeight)
+	if err != nil {
+		if err != statedb.ErrCheckpointMismatch {
+			logx.Errorf("restore checkpoints of block %d failed: %v", curBlock.BlockHeight, err)
+		}
+		logx.Infof("checkpoints of block %d are not usable, apply %d executed txs again", curBlock.BlockHeight, len(executedTxs))
+		return false, nil
+	}
+	logx.Infof("restored %d executed txs of block %d from checkpoint", len(executedTxs), curBlock.BlockHeight)
+	return true, nil
+}
+
+// saveCheckpointInTransact saves the state changed by the txs executed since
+// the last checkpoint together with their pool updates. The committer stops
+// if the transaction fails, the state cache is then restored on restart.
+func (c *Committer) saveCheckpointInTransact(dbTx *gorm.DB, curBlock *block.Block) error {
+	fromTxIndex, state, err := c.bc.StateDB().MarshalCheckpoint()
+	if err != nil {
+		return err
+	}
+	return c.bc.BlockCheckpointModel.SaveBlockCheckpointInTransact(dbTx, &blockcheckpoint.BlockCheckpoint{
+		BlockHeight: curBlock.BlockHeight,
+		FromTxIndex: fromTxIndex,
+		TxCount:     len(c.bc.Statedb.Txs),
+		State:       string(state),
+	})
+}
+
 func (c *Committer) createNewBlock(curBlock *block.Block, poolTx *tx.Tx) error {
 	return c.bc.DB().DB.Transaction(func(dbTx *gorm.DB) error {
 		err := c.bc.TxPoolModel.UpdateTxsInTransact(dbTx, []*tx.Tx{poolTx})
@@ -294,7 +357,11 @@ func (c *Committer) createNewBlock(curBlock *block.Block, poolTx *tx.Tx) error {
 			return err
 		}
 
-		return c.bc.BlockModel.CreateBlockInTransact(dbTx, curBlock)
+		err = c.bc.BlockModel.CreateBlockInTransact(dbTx, curBlock)
+		if err != nil {
+			return err
+		}
+		return c.saveCheckpointInTransact(dbTx, curBlock)
 	})
 }
 
@@ -362,8 +429,18 @@ func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error)
 				return err
 			}
 		}
+		// add the txs to the analytics rollups
+		err := c.bc.DB().StatModel.AddTxsInTransact(tx, blockStates.Block.CreatedAt, blockStates.Block.Txs)
+		if err != nil {
+			return err
+		}
 		// delete txs from tx pool
-		err := c.bc.DB().TxPoolModel.DeleteTxsInTransact(tx, blockStates.Block.Txs)
+		err = c.bc.DB().TxPoolModel.DeleteTxsInTransact(tx, blockStates.Block.Txs)
+		if err != nil {
+			return err
+		}
+		// the block is not resumed anymore
+		err = c.bc.DB().BlockCheckpointModel.DeleteBlockCheckpointsInTransact(tx, blockStates.Block.BlockHeight)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,309 @@
+package committer
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/core"
+	"github.com/bnb-chain/zkbnb/core/statedb"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockcheckpoint"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// The fake models return what is left in the database when the committer is
+// killed, the pool updates and the checkpoint are always saved together.
+type fakeBlockModel struct {
+	block.BlockModel
+	current *block.Block
+}
+
+func (m *fakeBlockModel) GetCurrentBlockHeight() (int64, error) {
+	return m.current.BlockHeight, nil
+}
+
+func (m *fakeBlockModel) GetBlockByHeight(height int64) (*block.Block, error) {
+	return m.current, nil
+}
+
+type fakeTxPoolModel struct {
+	tx.TxPoolModel
+	executed []*tx.Tx
+}
+
+func (m *fakeTxPoolModel) GetTxsByStatus(status int) ([]*tx.Tx, error) {
+	return m.executed, nil
+}
+
+type fakeCheckpointModel struct {
+	blockcheckpoint.BlockCheckpointModel
+	checkpoint *blockcheckpoint.BlockCheckpoint
+}
+
+func (m *fakeCheckpointModel) GetBlockCheckpoints(height int64) ([]*blockcheckpoint.BlockCheckpoint, error) {
+	if m.checkpoint == nil || m.checkpoint.BlockHeight != height {
+		return nil, types.DbErrNotFound
+	}
+	return []*blockcheckpoint.BlockCheckpoint{m.checkpoint}, nil
+}
+
+func newTestStateDB() *statedb.StateDB {
+	return &statedb.StateDB{
+		StateCache: statedb.NewStateCache("01"),
+		AccountAssetTrees: tree.NewLazyTreeCache(16, 0, 9, func(index, block int64) bsmt.SparseMerkleTree {
+			return nil
+		}),
+	}
+}
+
+// executeTxs returns the state of the proposing block after executing n txs
+// and the pool txs as they are stored in the database.
+func executeTxs(t *testing.T, height int64, n int) (*blockcheckpoint.BlockCheckpoint, []*tx.Tx) {
+	s := newTestStateDB()
+	executed := make([]*tx.Tx, 0, n)
+	for i := 0; i < n; i++ {
+		executedTx := &tx.Tx{
+			TxIndex:     int64(i),
+			BlockHeight: height,
+			TxStatus:    tx.StatusExecuted,
+			TxDetails:   []*tx.TxDetail{{AccountIndex: 0, Order: int64(i)}},
+		}
+		executedTx.ID = uint(i + 1)
+		s.Txs = append(s.Txs, executedTx)
+		s.SetPendingGas(0, big.NewInt(1))
+		s.PubData = append(s.PubData, byte(i))
+
+		poolTx := *executedTx
+		poolTx.TxDetails = nil
+		executed = append(executed, &poolTx)
+	}
+	fromTxIndex, state, err := s.MarshalCheckpoint()
+	require.NoError(t, err)
+	return &blockcheckpoint.BlockCheckpoint{
+		BlockHeight: height,
+		FromTxIndex: fromTxIndex,
+		TxCount:     n,
+		State:       string(state),
+	}, executed
+}
+
+func newTestCommitter(current *block.Block, executed []*tx.Tx, checkpoint *blockcheckpoint.BlockCheckpoint) *Committer {
+	return &Committer{
+		bc: &core.BlockChain{
+			ChainDB: &statedb.ChainDB{
+				BlockModel:           &fakeBlockModel{current: current},
+				TxPoolModel:          &fakeTxPoolModel{executed: executed},
+				BlockCheckpointModel: &fakeCheckpointModel{checkpoint: checkpoint},
+			},
+			Statedb: newTestStateDB(),
+		},
+	}
+}
+
+func TestRestoreExecutedTxs(t *testing.T) {
+	committed := &block.Block{BlockHeight: 5, BlockStatus: block.StatusPending}
+	proposing := &block.Block{BlockHeight: 6, BlockStatus: block.StatusProposing}
+
+	checkpoint1, executed1 := executeTxs(t, 6, 1)
+	checkpoint3, executed3 := executeTxs(t, 6, 3)
+
+	tests := []struct {
+		name       string
+		current    *block.Block
+		executed   []*tx.Tx
+		checkpoint *blockcheckpoint.BlockCheckpoint
+		txs        int
+	}{
+		// killed before the first tx of a block is saved, or after the
+		// block is committed together with the deletion of its checkpoint
+		{"before first tx", committed, nil, nil, 0},
+		// killed after the proposing block is created with its first tx
+		{"after new block", proposing, executed1, checkpoint1, 1},
+		// killed after a batch of pool updates
+		{"after pool update", proposing, executed3, checkpoint3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCommitter(tt.current, tt.executed, tt.checkpoint)
+			curBlock, err := c.restoreExecutedTxs()
+			require.NoError(t, err)
+			assert.Equal(t, tt.current.BlockHeight, curBlock.BlockHeight)
+
+			s := c.bc.StateDB()
+			require.Len(t, s.Txs, tt.txs)
+			for i, restored := range s.Txs {
+				assert.Equal(t, int64(i), restored.TxIndex)
+				require.Len(t, restored.TxDetails, 1)
+				assert.Equal(t, int64(i), restored.TxDetails[0].Order)
+			}
+			if tt.txs > 0 {
+				assert.Equal(t, int64(tt.txs), s.GetPendingGas(0).Int64())
+				assert.Len(t, s.PubData, tt.txs)
+			}
+		})
+	}
+}
+
+func TestRestoreExecutedTxsWithoutProposingBlock(t *testing.T) {
+	_, executed := executeTxs(t, 6, 1)
+	c := newTestCommitter(&block.Block{BlockHeight: 5, BlockStatus: block.StatusPending}, executed, nil)
+	_, err := c.restoreExecutedTxs()
+	assert.Error(t, err)
+}
+
+func TestRestoreStaleCheckpoint(t *testing.T) {
+	proposing := &block.Block{BlockHeight: 6, BlockStatus: block.StatusProposing}
+	checkpoint, _ := executeTxs(t, 6, 2)
+	_, executed := executeTxs(t, 6, 3)
+
+	// the executed txs are applied again if the checkpoint does not cover them
+	c := newTestCommitter(proposing, executed, checkpoint)
+	restored, err := c.restoreCheckpoint(proposing, executed)
+	require.NoError(t, err)
+	assert.False(t, restored)
+	assert.Empty(t, c.bc.StateDB().Txs)
+
+	// or if it belongs to a previous block
+	checkpoint, _ = executeTxs(t, 5, 3)
+	c = newTestCommitter(proposing, executed, checkpoint)
+	restored, err = c.restoreCheckpoint(proposing, executed)
+	require.NoError(t, err)
+	assert.False(t, restored)
+}
+
+type fakeAccountHistoryModel struct {
+	account.AccountHistoryModel
+}
+
+func (m *fakeAccountHistoryModel) GetValidAccountCount(height int64) (int64, error) {
+	return 0, nil
+}
+
+type fakeNftHistoryModel struct {
+	nft.L2NftHistoryModel
+}
+
+func (m *fakeNftHistoryModel) GetLatestNftsCountByBlockHeight(height int64) (int64, error) {
+	return 0, nil
+}
+
+// newTreeStateDB returns a state db of empty in-memory trees.
+func newTreeStateDB(t *testing.T) *statedb.StateDB {
+	ctx, err := tree.NewContext("committer", tree.MemoryDB, false, 0, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(ctx))
+	accountTree, accountAssetTrees, err := tree.InitAccountTree(nil, &fakeAccountHistoryModel{}, 0, ctx, 16)
+	require.NoError(t, err)
+	nftTree, err := tree.InitNftTree(&fakeNftHistoryModel{}, 0, ctx)
+	require.NoError(t, err)
+	return &statedb.StateDB{
+		StateCache:        statedb.NewStateCache(""),
+		AccountTree:       accountTree,
+		NftTree:           nftTree,
+		AccountAssetTrees: accountAssetTrees,
+		TreeCtx:           ctx,
+	}
+}
+
+func TestRestoreSavedCheckpoints(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	checkpointModel := blockcheckpoint.NewBlockCheckpointModel(db)
+	require.NoError(t, checkpointModel.CreateBlockCheckpointTable())
+
+	sk, err := eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	proposing := &block.Block{BlockHeight: 6, BlockStatus: block.StatusProposing}
+	c := &Committer{
+		bc: &core.BlockChain{
+			ChainDB: &statedb.ChainDB{
+				DB:                   db,
+				BlockCheckpointModel: checkpointModel,
+			},
+			Statedb: newTreeStateDB(t),
+		},
+	}
+
+	// the txs are executed in batches, account 3 is changed by all of them
+	s := c.bc.StateDB()
+	executed := make([]*tx.Tx, 0)
+	for _, batch := range [][]int64{{2, 3}, {3}, {3, 4}} {
+		for _, accountIndex := range batch {
+			executedTx := &tx.Tx{
+				TxIndex:     int64(len(s.Txs)),
+				BlockHeight: proposing.BlockHeight,
+				TxStatus:    tx.StatusExecuted,
+				TxDetails:   []*tx.TxDetail{{AccountIndex: accountIndex, Order: int64(len(s.Txs))}},
+			}
+			executedTx.ID = uint(len(s.Txs) + 1)
+			s.Txs = append(s.Txs, executedTx)
+
+			balance := big.NewInt(100)
+			if account, ok := s.StateCache.GetPendingAccount(accountIndex); ok {
+				balance = new(big.Int).Add(account.AssetInfo[0].Balance, big.NewInt(1))
+			}
+			s.SetPendingAccount(accountIndex, &types.AccountInfo{
+				AccountIndex:    accountIndex,
+				AccountNameHash: "0x01",
+				PublicKey:       common.Bytes2Hex(sk.PublicKey.Bytes()),
+				Nonce:           executedTx.TxIndex,
+				AssetInfo: map[int64]*types.AccountAsset{
+					0: {AssetId: 0, Balance: balance, OfferCanceledOrFinalized: big.NewInt(0)},
+				},
+			})
+			s.MarkAccountAssetsDirty(accountIndex, []int64{0})
+			s.PubData = append(s.PubData, byte(executedTx.TxIndex))
+
+			poolTx := *executedTx
+			poolTx.TxDetails = nil
+			executed = append(executed, &poolTx)
+		}
+		err = db.Transaction(func(dbTx *gorm.DB) error {
+			return c.saveCheckpointInTransact(dbTx, proposing)
+		})
+		require.NoError(t, err)
+	}
+	checkpoints, err := checkpointModel.GetBlockCheckpoints(proposing.BlockHeight)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 3)
+	assert.Equal(t, 2, checkpoints[1].FromTxIndex)
+	assert.Equal(t, 5, checkpoints[2].TxCount)
+
+	require.NoError(t, s.IntermediateRoot(false))
+	stateRoot := s.StateRoot
+
+	// a new committer restores the state from the database
+	restarted := &Committer{
+		bc: &core.BlockChain{
+			ChainDB: &statedb.ChainDB{
+				DB:                   db,
+				BlockModel:           &fakeBlockModel{current: proposing},
+				TxPoolModel:          &fakeTxPoolModel{executed: executed},
+				BlockCheckpointModel: checkpointModel,
+			},
+			Statedb: newTreeStateDB(t),
+		},
+	}
+	curBlock, err := restarted.restoreExecutedTxs()
+	require.NoError(t, err)
+	assert.Equal(t, proposing.BlockHeight, curBlock.BlockHeight)
+
+	restored := restarted.bc.StateDB()
+	require.Len(t, restored.Txs, len(executed))
+	assert.Equal(t, s.PubData, restored.PubData)
+	require.NoError(t, restored.IntermediateRoot(false))
+	assert.Equal(t, stateRoot, restored.StateRoot)
+}
@@ -154,6 +154,7 @@ func (c *Fullnode) Run() {
 
 func (c *Fullnode) Shutdown() {
 	close(c.quitCh)
+	c.bc.TreePruner.Stop()
 	c.bc.Statedb.Close()
 	c.bc.ChainDB.Close()
 }
@@ -214,6 +215,11 @@ func (c *Fullnode) processNewBlock(curBlock *block.Block, blockSize int) (*block
 				return err
 			}
 		}
+		// add the txs to the analytics rollups
+		err = c.bc.DB().StatModel.AddTxsInTransact(tx, blockStates.Block.CreatedAt, blockStates.Block.Txs)
+		if err != nil {
+			return err
+		}
 
 		return c.bc.DB().BlockModel.CreateBlockInTransact(tx, blockStates.Block)
 	})
@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/common/l1client"
 )
 
 type Config struct {
@@ -14,6 +16,8 @@ type Config struct {
 		ConfirmBlocksCount      uint64
 		MaxHandledBlocksCount   int64
 		KeptHistoryBlocksCount  int64 // KeptHistoryBlocksCount define the count of blocks to keep in table, old blocks will be cleaned
+		//nolint:staticcheck
+		L1Client l1client.Config `json:",optional"`
 	}
 	LogConf logx.LogConf
 }
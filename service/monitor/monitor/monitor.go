@@ -24,8 +24,8 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
 	common2 "github.com/bnb-chain/zkbnb/common"
+	"github.com/bnb-chain/zkbnb/common/l1client"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
@@ -55,7 +55,7 @@ var (
 type Monitor struct {
 	Config config.Config
 
-	cli *rpc.ProviderClient
+	cli *l1client.Client
 
 	zkbnbContractAddress      string
 	governanceContractAddress string
@@ -113,7 +113,7 @@ func NewMonitor(c config.Config) *Monitor {
 	logx.Infof("ChainName: %s, zkbnbContractAddress: %s, networkRpc: %s",
 		c.ChainConfig.NetworkRPCSysConfigName, zkbnbAddressConfig.Value, networkRpc.Value)
 
-	bscRpcCli, err := rpc.NewClient(networkRpc.Value)
+	bscRpcCli, err := l1client.NewClient(l1client.ParseEndpoints(networkRpc.Value), c.ChainConfig.L1Client)
 	if err != nil {
 		panic(err)
 	}
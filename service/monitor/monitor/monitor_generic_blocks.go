@@ -17,20 +17,19 @@
 package monitor
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/gorm"
 
 	zkbnb "github.com/bnb-chain/zkbnb-eth-rpc/core"
-	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
+	"github.com/bnb-chain/zkbnb/common/l1client"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
 	"github.com/bnb-chain/zkbnb/dao/l1syncedblock"
@@ -135,6 +134,32 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 			relatedBlockTxStatus[blockHeight] = tx.StatusVerified
 		case zkbnbLogBlocksRevertSigHash.Hex():
 			l1EventInfo.EventType = EventTypeRevertedBlock
+
+			var event zkbnb.ZkBNBBlocksRevert
+			if err := ZkBNBContractAbi.UnpackIntoInterface(&event, EventNameBlocksRevert, vlog.Data); err != nil {
+				return fmt.Errorf("failed to unpack ZkBNBBlocksRevert err: %v", err)
+			}
+
+			// Blocks after the new total committed count are no longer committed on l1,
+			// they stay reverted until the operator rolls the l2 state back.
+			lastCommittedHeight := int64(event.TotalBlocksCommitted)
+			committedBlocks, err := m.BlockModel.GetCommittedBlocksBetween(lastCommittedHeight+1, math.MaxInt64)
+			if err != nil && err != types2.DbErrNotFound {
+				return fmt.Errorf("failed to get committed blocks, err: %v", err)
+			}
+			for _, committedBlock := range committedBlocks {
+				if relatedBlocks[committedBlock.BlockHeight] == nil {
+					relatedBlocks[committedBlock.BlockHeight] = committedBlock
+				}
+			}
+			for blockHeight, relatedBlock := range relatedBlocks {
+				if blockHeight > lastCommittedHeight && relatedBlock.BlockStatus == block.StatusCommitted {
+					relatedBlock.BlockStatus = block.StatusReverted
+					relatedBlockTxStatus[blockHeight] = tx.StatusReverted
+				}
+			}
+			logx.Severef("l1 reverted blocks after height %d, tx_hash=%s, total verified blocks: %d",
+				lastCommittedHeight, vlog.TxHash.Hex(), event.TotalBlocksVerified)
 		default:
 		}
 
@@ -160,7 +185,7 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 	pendingUpdateVerifiedBlocks := make(map[string]*block.Block, 0)
 	for _, pendingUpdateBlock := range relatedBlocks {
 		pendingUpdateBlocks = append(pendingUpdateBlocks, pendingUpdateBlock)
-		if pendingUpdateBlock.CommittedTxHash != "" {
+		if pendingUpdateBlock.CommittedTxHash != "" && pendingUpdateBlock.BlockStatus != block.StatusReverted {
 			b, exist := pendingUpdateCommittedBlocks[pendingUpdateBlock.CommittedTxHash]
 			if exist {
 				if b.BlockHeight < pendingUpdateBlock.BlockHeight {
@@ -260,34 +285,33 @@ func (m *Monitor) MonitorGenericBlocks() (err error) {
 	return nil
 }
 
-func getZkBNBContractLogs(cli *rpc.ProviderClient, zkbnbContract string, startHeight, endHeight uint64) ([]types.Log, error) {
+func getZkBNBContractLogs(cli *l1client.Client, zkbnbContract string, startHeight, endHeight uint64) ([]types.Log, error) {
 	query := ethereum.FilterQuery{
 		FromBlock: big.NewInt(int64(startHeight)),
 		ToBlock:   big.NewInt(int64(endHeight)),
 		Addresses: []common.Address{common.HexToAddress(zkbnbContract)},
 	}
-	logs, err := cli.FilterLogs(context.Background(), query)
+	logs, err := cli.FilterLogs(query)
 	if err != nil {
 		return nil, err
 	}
 	return logs, nil
 }
 
-func getPriorityRequestCount(cli *rpc.ProviderClient, zkbnbContract string, startHeight, endHeight uint64) (int, error) {
-	zkbnbInstance, err := zkbnb.LoadZkBNBInstance(cli, zkbnbContract)
-	if err != nil {
-		return 0, err
+// getPriorityRequestCount counts the NewPriorityRequest logs agreed by the
+// quorum, it is checked against the events of the contract logs.
+func getPriorityRequestCount(cli *l1client.Client, zkbnbContract string, startHeight, endHeight uint64) (int, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(int64(startHeight)),
+		ToBlock:   big.NewInt(int64(endHeight)),
+		Addresses: []common.Address{common.HexToAddress(zkbnbContract)},
+		Topics:    [][]common.Hash{{zkbnbLogNewPriorityRequestSigHash}},
 	}
-	priorityRequests, err := zkbnbInstance.ZkBNBFilterer.
-		FilterNewPriorityRequest(&bind.FilterOpts{Start: startHeight, End: &endHeight})
+	logs, err := cli.FilterLogs(query)
 	if err != nil {
 		return 0, err
 	}
-	priorityRequestCount := 0
-	for priorityRequests.Next() {
-		priorityRequestCount++
-	}
-	return priorityRequestCount, nil
+	return len(logs), nil
 }
 
 func convertLogToNewPriorityRequestEvent(log types.Log) (*priorityrequest.PriorityRequest, error) {
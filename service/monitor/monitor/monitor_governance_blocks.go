@@ -17,7 +17,6 @@
 package monitor
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -37,7 +36,7 @@ import (
 
 func (m *Monitor) getNewL2Asset(event zkbnb.GovernanceNewAsset) (*asset.Asset, error) {
 	// get asset info by contract address
-	erc20Instance, err := zkbnb.LoadERC20(m.cli, event.AssetAddress.Hex())
+	erc20Instance, err := zkbnb.LoadERC20(m.cli.Provider(), event.AssetAddress.Hex())
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +98,7 @@ func (m *Monitor) MonitorGovernanceBlocks() (err error) {
 		ToBlock:   big.NewInt(endHeight),
 		Addresses: []common.Address{contractAddress},
 	}
-	logs, err := m.cli.FilterLogs(context.Background(), query)
+	logs, err := m.cli.FilterLogs(query)
 	if err != nil {
 		return fmt.Errorf("failed to query logs through rpc client: %v", err)
 	}
@@ -31,6 +31,7 @@ const (
 	EventNameNewPriorityRequest = "NewPriorityRequest"
 	EventNameBlockCommit        = "BlockCommit"
 	EventNameBlockVerification  = "BlockVerification"
+	EventNameBlocksRevert       = "BlocksRevert"
 
 	EventTypeNewPriorityRequest = 0
 	EventTypeCommittedBlock     = 1
@@ -3,19 +3,47 @@ package config
 import (
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/cache"
+
+	"github.com/bnb-chain/zkbnb/common/storage"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
 )
 
 type Config struct {
 	Postgres struct {
 		DataSource string
 	}
-	CacheRedis cache.CacheConf
+	// CacheDriver is redis by default, memory locks the block witnesses in
+	// process so that no redis is needed when only one prover is running.
+	//nolint:staticcheck
+	CacheDriver dbcache.Driver `json:",optional"`
+	//nolint:staticcheck
+	CacheRedis cache.CacheConf `json:",optional"`
 	LogConf    logx.LogConf
 	KeyPath    struct {
 		ProvingKeyPath   []string
 		VerifyingKeyPath []string
+		// Hex sha256 checksums of the key files, verified when loading them.
+		//nolint:staticcheck
+		ProvingKeyChecksum []string `json:",optional"`
+		//nolint:staticcheck
+		VerifyingKeyChecksum []string `json:",optional"`
+		// Keys are loaded when a block of their size is proved, the least recently
+		// used keys are unloaded to keep the size of the loaded proving key files
+		// below MaxMemoryMB. 0 means no limit.
+		//nolint:staticcheck
+		MaxMemoryMB int64 `json:",optional"`
 	}
 	BlockConfig struct {
 		OptionalBlockSizes []int
+		// Block sizes proved by this instance, all optional block sizes by default.
+		//nolint:staticcheck
+		ProveBlockSizes []int `json:",optional"`
 	}
+	// Mock skips loading the circuits and keys and writes dummy proofs, which
+	// are only accepted by a test verifier contract.
+	//nolint:staticcheck
+	Mock bool `json:",optional"`
+	// Storage of the witness data, the same as the one of the witness service.
+	//nolint:staticcheck
+	WitnessStorage storage.Config `json:",optional"`
 }
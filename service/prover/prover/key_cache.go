@@ -0,0 +1,192 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prover
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/service/prover/config"
+)
+
+var (
+	loadedKeysMemoryMetric = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "zkbnb",
+		Name:      "prover_loaded_keys_memory",
+		Help:      "Estimated memory of the loaded proving keys in bytes.",
+	})
+	keyLoadMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "prover_key_load",
+		Help:      "Number of times the keys of a block size were loaded.",
+	}, []string{"block_size"})
+)
+
+// blockKeys are the circuit and the keys of a block size.
+type blockKeys struct {
+	keyIndex     int
+	r1cs         frontend.CompiledConstraintSystem
+	provingKey   groth16.ProvingKey
+	verifyingKey groth16.VerifyingKey
+	// memory is estimated by the size of the proving key file, which
+	// dominates the memory of the circuit and the verifying key.
+	memory int64
+}
+
+// keyCache loads the keys of a block size when a block of that size is proved
+// and unloads the least recently used keys to stay within the memory limit.
+// The keys of the block being proved are always kept, even if they exceed
+// the limit on their own.
+type keyCache struct {
+	mu sync.Mutex
+
+	config     config.Config
+	blockSizes []int
+	maxMemory  int64
+
+	memory  int64
+	lru     *list.List
+	entries map[int]*list.Element
+
+	// loadKeys reads the keys of a block size, it is load except in tests.
+	loadKeys func(keyIndex int) (*blockKeys, error)
+}
+
+func newKeyCache(c config.Config) *keyCache {
+	cache := &keyCache{
+		config:     c,
+		blockSizes: c.BlockConfig.OptionalBlockSizes,
+		maxMemory:  c.KeyPath.MaxMemoryMB * 1024 * 1024,
+		lru:        list.New(),
+		entries:    make(map[int]*list.Element),
+	}
+	cache.loadKeys = cache.load
+	return cache
+}
+
+func (c *keyCache) get(keyIndex int) (*blockKeys, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[keyIndex]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*blockKeys), nil
+	}
+
+	memory, err := fileSize(c.config.KeyPath.ProvingKeyPath[keyIndex])
+	if err != nil {
+		return nil, err
+	}
+	// Unload before loading, so that the limit is not exceeded in between.
+	for c.maxMemory > 0 && c.lru.Len() > 0 && c.memory+memory > c.maxMemory {
+		c.unload(c.lru.Back())
+	}
+	keys, err := c.loadKeys(keyIndex)
+	if err != nil {
+		return nil, err
+	}
+	keys.memory = memory
+	c.entries[keyIndex] = c.lru.PushFront(keys)
+	c.memory += memory
+	loadedKeysMemoryMetric.Set(float64(c.memory))
+	return keys, nil
+}
+
+func (c *keyCache) unload(elem *list.Element) {
+	keys := c.lru.Remove(elem).(*blockKeys)
+	delete(c.entries, keys.keyIndex)
+	c.memory -= keys.memory
+	loadedKeysMemoryMetric.Set(float64(c.memory))
+	logx.Infof("unloaded keys of block size %d", c.blockSizes[keys.keyIndex])
+}
+
+func (c *keyCache) load(keyIndex int) (*blockKeys, error) {
+	blockSize := c.blockSizes[keyIndex]
+	provingKeyPath := c.config.KeyPath.ProvingKeyPath[keyIndex]
+	verifyingKeyPath := c.config.KeyPath.VerifyingKeyPath[keyIndex]
+	if len(c.config.KeyPath.ProvingKeyChecksum) > 0 {
+		if err := verifyChecksum(provingKeyPath, c.config.KeyPath.ProvingKeyChecksum[keyIndex]); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.config.KeyPath.VerifyingKeyChecksum) > 0 {
+		if err := verifyChecksum(verifyingKeyPath, c.config.KeyPath.VerifyingKeyChecksum[keyIndex]); err != nil {
+			return nil, err
+		}
+	}
+
+	cs, err := prove.CompileBlockCircuit(blockSize)
+	if err != nil {
+		return nil, fmt.Errorf("r1cs init error: %v", err)
+	}
+
+	// read proving and verifying keys
+	provingKey, err := prove.LoadProvingKey(provingKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("provingKey loading error: %v", err)
+	}
+	verifyingKey, err := prove.LoadVerifyingKey(verifyingKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("verifyingKey loading error: %v", err)
+	}
+	keyLoadMetric.WithLabelValues(fmt.Sprint(blockSize)).Inc()
+	logx.Infof("loaded keys of block size %d", blockSize)
+	return &blockKeys{
+		keyIndex:     keyIndex,
+		r1cs:         cs,
+		provingKey:   provingKey,
+		verifyingKey: verifyingKey,
+	}, nil
+}
+
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("key file error: %v", err)
+	}
+	return info.Size(), nil
+}
+
+// verifyChecksum compares the sha256 of the file with the hex checksum.
+func verifyChecksum(path string, checksum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("key file error: %v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return fmt.Errorf("read key file error: %v", err)
+	}
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != strings.ToLower(strings.TrimPrefix(checksum, "0x")) {
+		return fmt.Errorf("checksum mismatch of key file %s, expected: %s, actual: %s", path, checksum, actual)
+	}
+	return nil
+}
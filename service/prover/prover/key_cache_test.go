@@ -0,0 +1,104 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prover
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb/service/prover/config"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "zkbnb1.vk")
+	assert.NoError(t, ioutil.WriteFile(keyFile, []byte("key"), 0600))
+
+	checksum := "2c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683"
+	assert.NoError(t, verifyChecksum(keyFile, checksum))
+	assert.NoError(t, verifyChecksum(keyFile, "0x"+checksum))
+	assert.Error(t, verifyChecksum(keyFile, "00"+checksum[2:]))
+	assert.Error(t, verifyChecksum(filepath.Join(t.TempDir(), "missing"), checksum))
+}
+
+func TestBlockSizeIndex(t *testing.T) {
+	assert.Equal(t, 1, blockSizeIndex([]int{1, 10}, 10))
+	assert.Equal(t, -1, blockSizeIndex([]int{1, 10}, 5))
+}
+
+func TestKeyCacheUnloadsLeastRecentlyUsed(t *testing.T) {
+	// the proving key files of block sizes 1, 10 and 100 take 4, 4 and 8
+	// bytes, at most 12 bytes of keys are loaded
+	c := config.Config{}
+	c.BlockConfig.OptionalBlockSizes = []int{1, 10, 100}
+	dir := t.TempDir()
+	for i, size := range []int{4, 4, 8} {
+		keyFile := filepath.Join(dir, fmt.Sprintf("zkbnb%d.pk", i))
+		require.NoError(t, ioutil.WriteFile(keyFile, make([]byte, size), 0600))
+		c.KeyPath.ProvingKeyPath = append(c.KeyPath.ProvingKeyPath, keyFile)
+	}
+	cache := newKeyCache(c)
+	cache.maxMemory = 12
+	loads := make([]int, 0)
+	cache.loadKeys = func(keyIndex int) (*blockKeys, error) {
+		loads = append(loads, keyIndex)
+		return &blockKeys{keyIndex: keyIndex}, nil
+	}
+	get := func(keyIndex int) {
+		keys, err := cache.get(keyIndex)
+		require.NoError(t, err)
+		assert.Equal(t, keyIndex, keys.keyIndex)
+	}
+	loaded := func() []int {
+		indexes := make([]int, 0, cache.lru.Len())
+		for elem := cache.lru.Front(); elem != nil; elem = elem.Next() {
+			indexes = append(indexes, elem.Value.(*blockKeys).keyIndex)
+		}
+		return indexes
+	}
+
+	get(0)
+	get(1)
+	get(0)
+	assert.Equal(t, []int{0, 1}, loaded())
+	assert.Equal(t, int64(8), cache.memory)
+
+	// the keys of block size 10 are the least recently used
+	get(2)
+	assert.Equal(t, []int{2, 0}, loaded())
+	assert.Equal(t, int64(12), cache.memory)
+
+	// and loaded again on demand
+	get(1)
+	assert.Equal(t, []int{1, 2}, loaded())
+	assert.Equal(t, int64(12), cache.memory)
+	assert.Equal(t, []int{0, 1, 2, 1}, loads)
+
+	// keys exceeding the bound on their own are still loaded
+	cache.maxMemory = 6
+	get(0)
+	assert.Equal(t, []int{0}, loaded())
+	assert.Equal(t, int64(4), cache.memory)
+	get(2)
+	assert.Equal(t, []int{2}, loaded())
+	assert.Equal(t, int64(8), cache.memory)
+	assert.Equal(t, []int{0, 1, 2, 1, 0, 2}, loads)
+}
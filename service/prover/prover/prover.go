@@ -2,12 +2,10 @@ package prover
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/redis"
 	"gorm.io/driver/postgres"
@@ -16,25 +14,46 @@ import (
 	"github.com/bnb-chain/zkbnb-crypto/circuit"
 	"github.com/bnb-chain/zkbnb/common/prove"
 	"github.com/bnb-chain/zkbnb/common/redislock"
+	"github.com/bnb-chain/zkbnb/common/storage"
 	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/service/prover/config"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
+var (
+	errInvalidProof = errors.New("generated proof does not verify")
+
+	proofVerificationFailureMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "prover_proof_verification_failure",
+		Help:      "Number of generated proofs which failed the verification.",
+	})
+)
+
 type Prover struct {
 	Config config.Config
 
+	// RedisConn is nil if the lock is kept in process.
 	RedisConn *redis.Redis
 
 	DB                *gorm.DB
 	ProofModel        proof.ProofModel
 	BlockWitnessModel blockwitness.BlockWitnessModel
+	WitnessStorage    *blockwitness.WitnessStorage
 
-	VerifyingKeys      []groth16.VerifyingKey
-	ProvingKeys        []groth16.ProvingKey
 	OptionalBlockSizes []int
-	R1cs               []frontend.CompiledConstraintSystem
+	// ProveBlockSizes restricts the blocks proved by this instance, empty
+	// means all optional block sizes.
+	ProveBlockSizes []int
+	keys            *keyCache
+	// generate returns the proof of a block, the mock proof in mock mode.
+	generate func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error)
+
+	// halted is set once a proof fails the verification, it is most likely
+	// caused by bad keys so no further proof is generated until restart.
+	halted error
 }
 
 func WithRedis(redisType string, redisPass string) redis.Option {
@@ -58,58 +77,98 @@ func NewProver(c config.Config) *Prover {
 	if err != nil {
 		logx.Errorf("gorm connect db error, err = %s", err.Error())
 	}
-	redisConn := redis.New(c.CacheRedis[0].Host, WithRedis(c.CacheRedis[0].Type, c.CacheRedis[0].Pass))
+	blobs, err := storage.New(c.WitnessStorage)
+	if err != nil {
+		panic("witness storage init error")
+	}
+	var redisConn *redis.Redis
+	switch c.CacheDriver {
+	case "", dbcache.RedisDriver, dbcache.TwoTierDriver:
+		if len(c.CacheRedis) == 0 {
+			panic("no redis is configured for the prover lock")
+		}
+		redisConn = redis.New(c.CacheRedis[0].Host, WithRedis(c.CacheRedis[0].Type, c.CacheRedis[0].Pass))
+	case dbcache.MemoryDriver:
+	default:
+		panic("invalid CacheDriver: " + string(c.CacheDriver))
+	}
 	prover := &Prover{
 		Config:            c,
 		RedisConn:         redisConn,
 		DB:                db,
 		BlockWitnessModel: blockwitness.NewBlockWitnessModel(db),
 		ProofModel:        proof.NewProofModel(db),
+		WitnessStorage:    blockwitness.NewWitnessStorage(blobs),
 	}
 
 	if !IsBlockSizesSorted(c.BlockConfig.OptionalBlockSizes) {
 		panic("invalid OptionalBlockSizes")
 	}
 
-	prover.OptionalBlockSizes = c.BlockConfig.OptionalBlockSizes
-	prover.ProvingKeys = make([]groth16.ProvingKey, len(prover.OptionalBlockSizes))
-	prover.VerifyingKeys = make([]groth16.VerifyingKey, len(prover.OptionalBlockSizes))
-	prover.R1cs = make([]frontend.CompiledConstraintSystem, len(prover.OptionalBlockSizes))
-	for i := 0; i < len(prover.OptionalBlockSizes); i++ {
-		var blockConstraints circuit.BlockConstraints
-		blockConstraints.TxsCount = prover.OptionalBlockSizes[i]
-		blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
-		for i := 0; i < blockConstraints.TxsCount; i++ {
-			blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
-		}
-		blockConstraints.GasAssetIds = types.GasAssets[:]
-		blockConstraints.GasAccountIndex = types.GasAccount
-		blockConstraints.Gas = circuit.GetZeroGasConstraints(types.GasAssets[:])
+	if err := prometheus.Register(proofVerificationFailureMetric); err != nil {
+		panic(fmt.Sprintf("prometheus.Register proofVerificationFailureMetric error: %v", err))
+	}
+	if err := prover.republishFailedBlockWitnesses(); err != nil {
+		panic("republish failed block witnesses error")
+	}
 
-		logx.Infof("start compile block size %d blockConstraints", blockConstraints.TxsCount)
-		prover.R1cs[i], err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
-		if err != nil {
-			panic("r1cs init error")
-		}
-		logx.Infof("blockConstraints constraints: %d", prover.R1cs[i].GetNbConstraints())
-		logx.Info("finish compile blockConstraints")
-		// read proving and verifying keys
-		prover.ProvingKeys[i], err = prove.LoadProvingKey(c.KeyPath.ProvingKeyPath[i])
-		if err != nil {
-			panic("provingKey loading error")
+	prover.OptionalBlockSizes = c.BlockConfig.OptionalBlockSizes
+	prover.ProveBlockSizes = c.BlockConfig.ProveBlockSizes
+	for _, blockSize := range prover.ProveBlockSizes {
+		if blockSizeIndex(prover.OptionalBlockSizes, blockSize) < 0 {
+			panic("invalid ProveBlockSizes")
 		}
-		prover.VerifyingKeys[i], err = prove.LoadVerifyingKey(c.KeyPath.VerifyingKeyPath[i])
-		if err != nil {
-			panic("verifyingKey loading error")
+	}
+	if c.Mock {
+		logx.Severe("prover is running in mock mode, proofs are only accepted by a test verifier")
+		prover.generate = func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error) {
+			formattedProof, err := prove.MockProof(cryptoBlock, prover.OptionalBlockSizes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to mock proof, err: %v", err)
+			}
+			return formattedProof, nil
 		}
+		return prover
+	}
+
+	keyCount := len(prover.OptionalBlockSizes)
+	if len(c.KeyPath.ProvingKeyPath) != keyCount || len(c.KeyPath.VerifyingKeyPath) != keyCount ||
+		(len(c.KeyPath.ProvingKeyChecksum) > 0 && len(c.KeyPath.ProvingKeyChecksum) != keyCount) ||
+		(len(c.KeyPath.VerifyingKeyChecksum) > 0 && len(c.KeyPath.VerifyingKeyChecksum) != keyCount) {
+		panic("invalid KeyPath, one key per optional block size is required")
+	}
+	if err := prometheus.Register(loadedKeysMemoryMetric); err != nil {
+		panic(fmt.Sprintf("prometheus.Register loadedKeysMemoryMetric error: %v", err))
 	}
+	if err := prometheus.Register(keyLoadMetric); err != nil {
+		panic(fmt.Sprintf("prometheus.Register keyLoadMetric error: %v", err))
+	}
+	prover.keys = newKeyCache(c)
+	prover.generate = prover.generateProof
 
 	return prover
 }
 
+// republishFailedBlockWitnesses reschedules the witnesses which got an invalid
+// proof before, they are proved again with the restarted prover.
+func (p *Prover) republishFailedBlockWitnesses() error {
+	republished, err := p.BlockWitnessModel.RepublishFailedBlockWitnesses()
+	if err != nil {
+		return err
+	}
+	if republished > 0 {
+		logx.Infof("republished %d failed block witnesses", republished)
+	}
+	return nil
+}
+
 func (p *Prover) ProveBlock() error {
+	if p.halted != nil {
+		return fmt.Errorf("prover is halted, fix the keys and restart it: %v", p.halted)
+	}
+
 	blockWitness, err := func() (*blockwitness.BlockWitness, error) {
-		lock := redislock.GetRedisLockByKey(p.RedisConn, RedisLockKey)
+		lock := redislock.GetLockByKey(p.RedisConn, RedisLockKey)
 		err := redislock.TryAcquireLock(lock)
 		if err != nil {
 			return nil, err
@@ -118,7 +177,7 @@ func (p *Prover) ProveBlock() error {
 		defer lock.Release()
 
 		// Fetch unproved block witness.
-		blockWitness, err := p.BlockWitnessModel.GetLatestBlockWitness()
+		blockWitness, err := p.BlockWitnessModel.GetLatestBlockWitness(p.ProveBlockSizes)
 		if err != nil {
 			return nil, err
 		}
@@ -136,7 +195,7 @@ func (p *Prover) ProveBlock() error {
 		return err
 	}
 	defer func() {
-		if err == nil {
+		if err == nil || blockWitness.Status == blockwitness.StatusFailed {
 			return
 		}
 
@@ -148,31 +207,22 @@ func (p *Prover) ProveBlock() error {
 	}()
 
 	// Parse crypto block.
-	var cryptoBlock *circuit.Block
-	err = json.Unmarshal([]byte(blockWitness.WitnessData), &cryptoBlock)
+	witnessData, err := p.WitnessStorage.GetWitnessData(blockWitness)
 	if err != nil {
 		return err
 	}
-
-	var keyIndex int
-	for ; keyIndex < len(p.OptionalBlockSizes); keyIndex++ {
-		if len(cryptoBlock.Txs) == p.OptionalBlockSizes[keyIndex] {
-			break
-		}
-	}
-	if keyIndex == len(p.OptionalBlockSizes) {
-		return fmt.Errorf("can't find correct vk/pk")
-	}
-
-	// Generate proof.
-	blockProof, err := prove.GenerateProof(p.R1cs[keyIndex], p.ProvingKeys[keyIndex], p.VerifyingKeys[keyIndex], cryptoBlock)
+	var cryptoBlock *circuit.Block
+	err = json.Unmarshal(witnessData, &cryptoBlock)
 	if err != nil {
-		return fmt.Errorf("failed to generateProof, err: %v", err)
+		return err
 	}
 
-	formattedProof, err := prove.FormatProof(blockProof, cryptoBlock.OldStateRoot, cryptoBlock.NewStateRoot, cryptoBlock.BlockCommitment)
+	formattedProof, err := p.generate(cryptoBlock)
+	if errors.Is(err, errInvalidProof) {
+		p.halt(blockWitness, err)
+	}
 	if err != nil {
-		return fmt.Errorf("unable to format blockProof: %v", err)
+		return err
 	}
 
 	// Marshal formatted proof.
@@ -197,6 +247,55 @@ func (p *Prover) ProveBlock() error {
 	return err
 }
 
+func (p *Prover) generateProof(cryptoBlock *circuit.Block) (*prove.FormattedProof, error) {
+	keyIndex := blockSizeIndex(p.OptionalBlockSizes, len(cryptoBlock.Txs))
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("can't find correct vk/pk")
+	}
+	keys, err := p.keys.get(keyIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate proof.
+	blockProof, err := prove.GenerateProof(keys.r1cs, keys.provingKey, cryptoBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generateProof, err: %v", err)
+	}
+
+	formattedProof, err := prove.FormatProof(blockProof, cryptoBlock.OldStateRoot, cryptoBlock.NewStateRoot, cryptoBlock.BlockCommitment)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format blockProof: %v", err)
+	}
+
+	// Never store a proof which would be rejected by the verifier contract.
+	err = prove.VerifyProof(formattedProof, keys.verifyingKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w, block size: %d, err: %v", errInvalidProof, p.OptionalBlockSizes[keyIndex], err)
+	}
+	return formattedProof, nil
+}
+
+// halt marks the block witness as failed so that it is not rescheduled and
+// the sender does not move past it, and stops the prover.
+func (p *Prover) halt(blockWitness *blockwitness.BlockWitness, err error) {
+	proofVerificationFailureMetric.Inc()
+	logx.Severef("invalid proof of block %d, prover is halted, err: %v", blockWitness.Height, err)
+	p.halted = fmt.Errorf("invalid proof of block %d", blockWitness.Height)
+	if res := p.BlockWitnessModel.UpdateBlockWitnessFailed(blockWitness, err.Error()); res != nil {
+		logx.Errorf("mark block witness failed error, err %v", res)
+	}
+}
+
+func blockSizeIndex(blockSizes []int, blockSize int) int {
+	for i := range blockSizes {
+		if blockSizes[i] == blockSize {
+			return i
+		}
+	}
+	return -1
+}
+
 func (p *Prover) Shutdown() {
 	sqlDB, err := p.DB.DB()
 	if err == nil && sqlDB != nil {
@@ -0,0 +1,140 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prover
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/proof"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+type fakeBlockWitnessModel struct {
+	blockwitness.BlockWitnessModel
+	witnesses []*blockwitness.BlockWitness
+}
+
+func (m *fakeBlockWitnessModel) GetLatestBlockWitness(blockSizes []int) (*blockwitness.BlockWitness, error) {
+	for _, witness := range m.witnesses {
+		if witness.Status == blockwitness.StatusPublished {
+			return witness, nil
+		}
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *fakeBlockWitnessModel) UpdateBlockWitnessStatus(witness *blockwitness.BlockWitness, status int64) error {
+	witness.Status = status
+	return nil
+}
+
+func (m *fakeBlockWitnessModel) UpdateBlockWitnessFailed(witness *blockwitness.BlockWitness, reason string) error {
+	witness.FailureReason = reason
+	return m.UpdateBlockWitnessStatus(witness, blockwitness.StatusFailed)
+}
+
+func (m *fakeBlockWitnessModel) RepublishFailedBlockWitnesses() (count int64, err error) {
+	for _, witness := range m.witnesses {
+		if witness.Status == blockwitness.StatusFailed {
+			witness.Status = blockwitness.StatusPublished
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeProofModel struct {
+	proof.ProofModel
+	proofs map[int64]*proof.Proof
+}
+
+func (m *fakeProofModel) GetProofByBlockHeight(height int64) (*proof.Proof, error) {
+	if p, ok := m.proofs[height]; ok {
+		return p, nil
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *fakeProofModel) CreateProof(row *proof.Proof) error {
+	m.proofs[row.BlockNumber] = row
+	return nil
+}
+
+func TestProveBlockHaltsOnInvalidProof(t *testing.T) {
+	witness := &blockwitness.BlockWitness{Height: 1, BlockSize: 1, WitnessData: "{}"}
+	witnessModel := &fakeBlockWitnessModel{witnesses: []*blockwitness.BlockWitness{witness}}
+	proofModel := &fakeProofModel{proofs: make(map[int64]*proof.Proof)}
+	newProver := func(generate func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error)) *Prover {
+		return &Prover{
+			BlockWitnessModel: witnessModel,
+			ProofModel:        proofModel,
+			WitnessStorage:    blockwitness.NewWitnessStorage(nil),
+			generate:          generate,
+		}
+	}
+
+	generated := 0
+	p := newProver(func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error) {
+		generated++
+		return nil, fmt.Errorf("%w, block size: 1, err: bad keys", errInvalidProof)
+	})
+	err := p.ProveBlock()
+	assert.ErrorIs(t, err, errInvalidProof)
+	assert.Equal(t, int64(blockwitness.StatusFailed), witness.Status)
+	assert.Contains(t, witness.FailureReason, "bad keys")
+	assert.Empty(t, proofModel.proofs)
+
+	// the halted prover does not take any witness
+	assert.Error(t, p.ProveBlock())
+	assert.Equal(t, 1, generated)
+	assert.Equal(t, int64(blockwitness.StatusFailed), witness.Status)
+
+	// the witness is proved again by the restarted prover
+	p = newProver(func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error) {
+		return &prove.FormattedProof{Inputs: [3]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}}, nil
+	})
+	require.NoError(t, p.republishFailedBlockWitnesses())
+	assert.Equal(t, int64(blockwitness.StatusPublished), witness.Status)
+	require.NoError(t, p.ProveBlock())
+	assert.Equal(t, int64(blockwitness.StatusReceived), witness.Status)
+	assert.Contains(t, proofModel.proofs, int64(1))
+}
+
+func TestProveBlockRecoversWitnessOnError(t *testing.T) {
+	witness := &blockwitness.BlockWitness{Height: 1, BlockSize: 1, WitnessData: "{}"}
+	p := &Prover{
+		BlockWitnessModel: &fakeBlockWitnessModel{witnesses: []*blockwitness.BlockWitness{witness}},
+		ProofModel:        &fakeProofModel{proofs: make(map[int64]*proof.Proof)},
+		WitnessStorage:    blockwitness.NewWitnessStorage(nil),
+		generate: func(cryptoBlock *circuit.Block) (*prove.FormattedProof, error) {
+			return nil, fmt.Errorf("failed to generateProof")
+		},
+	}
+
+	// other errors neither halt the prover nor fail the witness
+	assert.Error(t, p.ProveBlock())
+	assert.Nil(t, p.halted)
+	assert.Equal(t, int64(blockwitness.StatusPublished), witness.Status)
+}
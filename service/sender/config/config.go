@@ -2,6 +2,9 @@ package config
 
 import (
 	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/common/l1client"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
 )
 
 type Config struct {
@@ -13,9 +16,55 @@ type Config struct {
 		MaxWaitingTime          int64
 		MaxBlockCount           int
 		ConfirmBlocksCount      uint64
-		Sk                      string
-		GasLimit                uint64
-		GasPrice                uint64
+		//nolint:staticcheck
+		Sk       string `json:",optional"`
+		GasLimit uint64
+		GasPrice uint64
+		// GasBumpPercent is the gas price increase of each replacement of a
+		// stuck tx, MaxGasBumpPercent caps the total increase over the original tx.
+		//nolint:staticcheck
+		GasBumpPercent uint64 `json:",optional"`
+		//nolint:staticcheck
+		MaxGasBumpPercent uint64 `json:",optional"`
+		//nolint:staticcheck
+		L1Client l1client.Config `json:",optional"`
+		//nolint:staticcheck
+		DynamicFee DynamicFeeConfig `json:",optional"`
+		// Gas limits of rollup txs are estimated by simulation and padded by
+		// GasEstimateBufferPercent, GasLimit caps them.
+		//nolint:staticcheck
+		GasEstimateBufferPercent uint64 `json:",optional"`
+		// MaxPendingTxs is the number of commit and of verify txs in flight at
+		// the same time, each covering up to MaxBlockCount blocks.
+		//nolint:staticcheck
+		MaxPendingTxs int `json:",optional"`
+		// Signer of the rollup txs, Sk is used if it is not configured.
+		//nolint:staticcheck
+		Signer signer.Config `json:",optional"`
 	}
 	LogConf logx.LogConf
 }
+
+// DynamicFeeConfig configures EIP-1559 txs, the sender falls back to legacy
+// txs on chains without base fee.
+type DynamicFeeConfig struct {
+	//nolint:staticcheck
+	Enabled bool `json:",optional"`
+	// Number of recent blocks the fees are derived from.
+	//nolint:staticcheck
+	FeeHistoryBlocks uint64 `json:",optional"`
+	// Percentile of the priority fees paid in recent blocks used as tip.
+	//nolint:staticcheck
+	PriorityFeePercentile float64 `json:",optional"`
+	// Static tip in wei, overrides the percentile strategy if set.
+	//nolint:staticcheck
+	PriorityFee uint64 `json:",optional"`
+	// Fee cap is BaseFeeMultiplier times the highest recent base fee plus tip.
+	//nolint:staticcheck
+	BaseFeeMultiplier uint64 `json:",optional"`
+	// Upper bounds in wei of the fee cap and tip, zero means no bound.
+	//nolint:staticcheck
+	MaxFeePerGas uint64 `json:",optional"`
+	//nolint:staticcheck
+	MaxPriorityFeePerGas uint64 `json:",optional"`
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+// txFee is the fee of a rollup tx, either a legacy gas price or the fee cap
+// and tip cap of an EIP-1559 dynamic fee tx.
+type txFee struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+func (f *txFee) isDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+func (f *txFee) apply(opts *bind.TransactOpts) {
+	if f.isDynamic() {
+		opts.GasFeeCap = f.GasFeeCap
+		opts.GasTipCap = f.GasTipCap
+		return
+	}
+	opts.GasPrice = f.GasPrice
+}
+
+func (f *txFee) String() string {
+	if f.isDynamic() {
+		return fmt.Sprintf("fee_cap=%s, tip_cap=%s", f.GasFeeCap, f.GasTipCap)
+	}
+	return fmt.Sprintf("gas_price=%s", f.GasPrice)
+}
+
+// setTo records the fee on the rollup tx.
+func (f *txFee) setTo(rollupTx *l1rolluptx.L1RollupTx) {
+	if f.isDynamic() {
+		rollupTx.GasFeeCap = f.GasFeeCap.String()
+		rollupTx.GasTipCap = f.GasTipCap.String()
+		return
+	}
+	rollupTx.GasPrice = f.GasPrice.String()
+}
+
+// txFeeOf returns the fee recorded on the rollup tx, or nil if the tx was
+// sent before fees were recorded.
+func txFeeOf(rollupTx *l1rolluptx.L1RollupTx) (*txFee, error) {
+	parse := func(value string) (*big.Int, error) {
+		result, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid fee of rollup tx %s: %s", rollupTx.L1TxHash, value)
+		}
+		return result, nil
+	}
+	var err error
+	fee := &txFee{}
+	switch {
+	case rollupTx.GasFeeCap != "":
+		if fee.GasFeeCap, err = parse(rollupTx.GasFeeCap); err != nil {
+			return nil, err
+		}
+		if fee.GasTipCap, err = parse(rollupTx.GasTipCap); err != nil {
+			return nil, err
+		}
+	case rollupTx.GasPrice != "":
+		if fee.GasPrice, err = parse(rollupTx.GasPrice); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return fee, nil
+}
+
+// getTxFee returns the fee of a new rollup tx, dynamic fee txs are used if
+// they are enabled and supported by the chain, legacy txs otherwise.
+func (s *Sender) getTxFee() (*txFee, error) {
+	if s.config.ChainConfig.DynamicFee.Enabled {
+		fee, err := s.getDynamicFee()
+		if err != nil {
+			return nil, err
+		}
+		if fee != nil {
+			return fee, nil
+		}
+	}
+	gasPrice, err := s.getGasPrice()
+	if err != nil {
+		return nil, err
+	}
+	return &txFee{GasPrice: gasPrice}, nil
+}
+
+func (s *Sender) getGasPrice() (*big.Int, error) {
+	if s.config.ChainConfig.GasPrice > 0 {
+		return new(big.Int).SetUint64(s.config.ChainConfig.GasPrice), nil
+	}
+	gasPrice, err := s.cli.SuggestGasPrice(context.Background())
+	if err != nil {
+		logx.Errorf("failed to fetch gas price: %v", err)
+		return nil, err
+	}
+	return gasPrice, nil
+}
+
+// getDynamicFee derives the fee caps from the base fees and priority fees
+// of recent blocks, it returns nil if the chain does not support EIP-1559.
+func (s *Sender) getDynamicFee() (*txFee, error) {
+	config := s.config.ChainConfig.DynamicFee
+	header, err := s.cli.GetLatestBlockHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest l1 block header, err: %v", err)
+	}
+	if header.BaseFee == nil {
+		logx.Info("l1 does not support dynamic fee txs, fall back to legacy txs")
+		return nil, nil
+	}
+	feeHistory, err := s.cli.FeeHistory(config.FeeHistoryBlocks, []float64{config.PriorityFeePercentile})
+	if err != nil {
+		logx.Errorf("failed to get l1 fee history, fall back to legacy txs, err: %v", err)
+		return nil, nil
+	}
+
+	// The base fee list includes the base fee of the next block.
+	maxBaseFee := new(big.Int).Set(header.BaseFee)
+	for _, baseFee := range feeHistory.BaseFee {
+		if baseFee != nil && baseFee.Cmp(maxBaseFee) > 0 {
+			maxBaseFee = baseFee
+		}
+	}
+
+	var tip *big.Int
+	if config.PriorityFee > 0 {
+		tip = new(big.Int).SetUint64(config.PriorityFee)
+	} else {
+		var rewards []*big.Int
+		for _, reward := range feeHistory.Reward {
+			if len(reward) > 0 && reward[0] != nil {
+				rewards = append(rewards, reward[0])
+			}
+		}
+		if len(rewards) == 0 {
+			if tip, err = s.cli.SuggestGasTipCap(context.Background()); err != nil {
+				return nil, fmt.Errorf("failed to suggest gas tip cap, err: %v", err)
+			}
+		} else {
+			sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+			tip = rewards[len(rewards)/2]
+		}
+	}
+	if config.MaxPriorityFeePerGas > 0 && tip.Cmp(new(big.Int).SetUint64(config.MaxPriorityFeePerGas)) > 0 {
+		tip = new(big.Int).SetUint64(config.MaxPriorityFeePerGas)
+	}
+
+	feeCap := new(big.Int).Mul(maxBaseFee, new(big.Int).SetUint64(config.BaseFeeMultiplier))
+	feeCap.Add(feeCap, tip)
+	if config.MaxFeePerGas > 0 && feeCap.Cmp(new(big.Int).SetUint64(config.MaxFeePerGas)) > 0 {
+		feeCap = new(big.Int).SetUint64(config.MaxFeePerGas)
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = feeCap
+	}
+	return &txFee{GasFeeCap: feeCap, GasTipCap: tip}, nil
+}
+
+// bumpTxFee returns the fee of the replacement of a tx paying current, or nil
+// if the fee can't be bumped by GasBumpPercent without the total bump over the
+// original tx exceeding the cap. Nodes reject replacements raising the gas
+// price, or either the fee cap or the tip cap, by less than their price bump.
+func (s *Sender) bumpTxFee(current, original *txFee) *txFee {
+	bump := func(current, original *big.Int) *big.Int {
+		value := percentOf(current, 100+s.config.ChainConfig.GasBumpPercent)
+		if value.Cmp(current) <= 0 {
+			value = new(big.Int).Add(current, big.NewInt(1))
+		}
+		if value.Cmp(percentOf(original, 100+s.config.ChainConfig.MaxGasBumpPercent)) > 0 {
+			return nil
+		}
+		return value
+	}
+	if !current.isDynamic() {
+		gasPrice := bump(current.GasPrice, original.GasPrice)
+		if gasPrice == nil {
+			return nil
+		}
+		return &txFee{GasPrice: gasPrice}
+	}
+	feeCap := bump(current.GasFeeCap, original.GasFeeCap)
+	if feeCap == nil {
+		return nil
+	}
+	tip := bump(current.GasTipCap, original.GasTipCap)
+	if tip == nil {
+		return nil
+	}
+	// The tip is at most the fee cap, which is still bumped enough as the
+	// current tip is at most the current fee cap.
+	if tip.Cmp(feeCap) > 0 {
+		tip = feeCap
+	}
+	return &txFee{GasFeeCap: feeCap, GasTipCap: tip}
+}
+
+func percentOf(value *big.Int, percent uint64) *big.Int {
+	result := new(big.Int).Mul(value, new(big.Int).SetUint64(percent))
+	return result.Div(result, big.NewInt(100))
+}
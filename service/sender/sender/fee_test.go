@@ -0,0 +1,111 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	sconfig "github.com/bnb-chain/zkbnb/service/sender/config"
+)
+
+func newFeeTestSender(bumpPercent, maxBumpPercent uint64) *Sender {
+	c := sconfig.Config{}
+	c.ChainConfig.GasBumpPercent = bumpPercent
+	c.ChainConfig.MaxGasBumpPercent = maxBumpPercent
+	return &Sender{config: c}
+}
+
+func TestBumpLegacyTxFee(t *testing.T) {
+	tests := []struct {
+		name           string
+		bumpPercent    uint64
+		maxBumpPercent uint64
+		original       int64
+		gasPrices      []int64
+	}{
+		// the last bump is not clamped to 1500, which would be less than 20%
+		{"bumped", 20, 50, 1000, []int64{1200, 1440}},
+		// 1948 can't be bumped by 10% within 200% of the original gas price
+		{"defaults", defaultGasBumpPercent, defaultMaxGasBumpPercent, 1000,
+			[]int64{1100, 1210, 1331, 1464, 1610, 1771, 1948}},
+		// a wei can't be bumped within the cap
+		{"tiny gas price", 10, 50, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFeeTestSender(tt.bumpPercent, tt.maxBumpPercent)
+			original := &txFee{GasPrice: big.NewInt(tt.original)}
+			fee := original
+			for _, gasPrice := range tt.gasPrices {
+				fee = s.bumpTxFee(fee, original)
+				if assert.NotNil(t, fee) {
+					assert.Equal(t, big.NewInt(gasPrice), fee.GasPrice)
+				}
+			}
+			assert.Nil(t, s.bumpTxFee(fee, original))
+		})
+	}
+}
+
+func TestBumpDynamicTxFee(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *txFee
+		original *txFee
+		bumped   *txFee
+	}{
+		{"bumped", &txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(10)},
+			&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(10)},
+			&txFee{GasFeeCap: big.NewInt(1200), GasTipCap: big.NewInt(12)}},
+		{"tip at fee cap", &txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(1000)},
+			&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(1000)},
+			&txFee{GasFeeCap: big.NewInt(1200), GasTipCap: big.NewInt(1200)}},
+		// the fee cap can still be bumped, but replacing the tx with the same
+		// tip would be rejected
+		{"capped tip", &txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(14)},
+			&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(10)}, nil},
+		{"capped fee cap", &txFee{GasFeeCap: big.NewInt(1300), GasTipCap: big.NewInt(10)},
+			&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(10)}, nil},
+		{"tip bumped by a wei", &txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(2)},
+			&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(2)},
+			&txFee{GasFeeCap: big.NewInt(1200), GasTipCap: big.NewInt(3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFeeTestSender(20, 50)
+			assert.Equal(t, tt.bumped, s.bumpTxFee(tt.current, tt.original))
+		})
+	}
+}
+
+func TestTxFeeRoundTrip(t *testing.T) {
+	rollupTx := &l1rolluptx.L1RollupTx{}
+	fee, err := txFeeOf(rollupTx)
+	assert.NoError(t, err)
+	assert.Nil(t, fee)
+
+	(&txFee{GasFeeCap: big.NewInt(1000), GasTipCap: big.NewInt(10)}).setTo(rollupTx)
+	fee, err = txFeeOf(rollupTx)
+	assert.NoError(t, err)
+	assert.True(t, fee.isDynamic())
+	assert.Equal(t, big.NewInt(1000), fee.GasFeeCap)
+	assert.Equal(t, big.NewInt(10), fee.GasTipCap)
+}
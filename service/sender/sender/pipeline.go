@@ -0,0 +1,134 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// submitRollupTxs sends rollup txs of the type, each covering the blocks
+// following the previous one, until MaxPendingTxs of them are in flight.
+// The txs use consecutive nonces, so l1 mines them in order.
+func (s *Sender) submitRollupTxs(txType uint8, prepare prepareFunc) (sentTxs []*l1rolluptx.L1RollupTx, err error) {
+	pendingTxs, err := s.l1RollupTxModel.GetL1RollupTxsByTypeAndStatus(txType, l1rolluptx.StatusPending)
+	if err != nil && err != types.DbErrNotFound {
+		return nil, err
+	}
+	start := int64(1)
+	if len(pendingTxs) > 0 {
+		start = pendingTxs[len(pendingTxs)-1].L2BlockHeight + 1
+	} else {
+		lastHandledTx, err := s.l1RollupTxModel.GetLatestHandledTx(int64(txType))
+		if err != nil && err != types.DbErrNotFound {
+			return nil, err
+		}
+		if lastHandledTx != nil {
+			start = lastHandledTx.L2BlockHeight + 1
+		}
+	}
+
+	for inFlight := len(pendingTxs); inFlight < s.config.ChainConfig.MaxPendingTxs; inFlight++ {
+		nonce, err := s.nextNonce()
+		if err != nil {
+			return sentTxs, err
+		}
+		data, end, gasLimit, err := s.prepareSimulatedCall(txType, start, nonce, prepare)
+		if err != nil {
+			return sentTxs, err
+		}
+		if data == nil {
+			return sentTxs, nil
+		}
+		fee, err := s.getTxFee()
+		if err != nil {
+			return sentTxs, err
+		}
+
+		txHash, err := s.sendRollupTx(data, nonce, fee, gasLimit)
+		if err != nil {
+			return sentTxs, fmt.Errorf("failed to send %s tx, err: %v:%s", txTypeName(txType), err, txHash)
+		}
+		newRollupTx := &l1rolluptx.L1RollupTx{
+			L1TxHash:           txHash,
+			TxStatus:           l1rolluptx.StatusPending,
+			TxType:             txType,
+			L2BlockHeight:      end,
+			L2StartBlockHeight: start,
+			Nonce:              int64(nonce),
+		}
+		fee.setTo(newRollupTx)
+		err = s.l1RollupTxModel.CreateL1RollupTx(newRollupTx)
+		if err != nil {
+			return sentTxs, fmt.Errorf("failed to create rollup tx in db, err: %v", err)
+		}
+		sentTxs = append(sentTxs, newRollupTx)
+		start = end + 1
+	}
+	return sentTxs, nil
+}
+
+// nextNonce returns the nonce of the next rollup tx. An endpoint the client
+// failed over to may not have seen all in-flight txs, so the nonce is never
+// below the ones recorded for pending txs.
+func (s *Sender) nextNonce() (uint64, error) {
+	nonce, err := s.cli.GetPendingNonce(s.signer.Address())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending nonce, err: %v", err)
+	}
+	pendingTxs, err := s.l1RollupTxModel.GetL1RollupTxsByStatus(l1rolluptx.StatusPending)
+	if err != nil && err != types.DbErrNotFound {
+		return 0, fmt.Errorf("failed to get pending txs, err: %v", err)
+	}
+	for _, pendingTx := range pendingTxs {
+		if uint64(pendingTx.Nonce) >= nonce {
+			nonce = uint64(pendingTx.Nonce) + 1
+		}
+	}
+	return nonce, nil
+}
+
+// dropFailedTxs collects the txs to drop after rollup txs failed, the later
+// txs of the same type build on a failed one and revert as well, so the
+// blocks are resent from the first failed tx on. A failed tx may be one that
+// got replaced, it is dropped along with its replacement.
+func dropFailedTxs(pendingTxs []*l1rolluptx.L1RollupTx, failedTxs []*l1rolluptx.L1RollupTx) []*l1rolluptx.L1RollupTx {
+	var droppedTxs []*l1rolluptx.L1RollupTx
+	dropped := make(map[uint]bool)
+	firstFailedHeight := make(map[uint8]int64)
+	for _, failedTx := range failedTxs {
+		height, ok := firstFailedHeight[failedTx.TxType]
+		if !ok || failedTx.L2StartBlockHeight < height {
+			firstFailedHeight[failedTx.TxType] = failedTx.L2StartBlockHeight
+		}
+		droppedTxs = append(droppedTxs, failedTx)
+		dropped[failedTx.ID] = true
+	}
+	for _, pendingTx := range pendingTxs {
+		height, ok := firstFailedHeight[pendingTx.TxType]
+		if ok && !dropped[pendingTx.ID] && pendingTx.L2BlockHeight >= height {
+			logx.Infof("drop l1 rollup tx after failure, tx_hash=%s, blocks from %d to %d",
+				pendingTx.L1TxHash, pendingTx.L2StartBlockHeight, pendingTx.L2BlockHeight)
+			droppedTxs = append(droppedTxs, pendingTx)
+		}
+	}
+	return droppedTxs
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+func newPendingTx(id uint, txType uint8, start, end int64) *l1rolluptx.L1RollupTx {
+	return &l1rolluptx.L1RollupTx{
+		Model:              gorm.Model{ID: id},
+		TxStatus:           l1rolluptx.StatusPending,
+		TxType:             txType,
+		L2StartBlockHeight: start,
+		L2BlockHeight:      end,
+	}
+}
+
+func TestDropFailedTxs(t *testing.T) {
+	commit1 := newPendingTx(1, l1rolluptx.TxTypeCommit, 1, 3)
+	commit2 := newPendingTx(2, l1rolluptx.TxTypeCommit, 4, 6)
+	commit3 := newPendingTx(3, l1rolluptx.TxTypeCommit, 7, 9)
+	verify1 := newPendingTx(4, l1rolluptx.TxTypeVerifyAndExecute, 1, 3)
+	pendingTxs := []*l1rolluptx.L1RollupTx{commit1, verify1, commit2, commit3}
+
+	assert.Empty(t, dropFailedTxs(pendingTxs, nil))
+
+	// later commits are dropped, the verify tx is not affected
+	droppedTxs := dropFailedTxs(pendingTxs, []*l1rolluptx.L1RollupTx{commit2})
+	assert.Equal(t, []*l1rolluptx.L1RollupTx{commit2, commit3}, droppedTxs)
+
+	// a replaced tx got mined and failed, its replacement is dropped as well
+	replacedTx := newPendingTx(5, l1rolluptx.TxTypeCommit, 4, 6)
+	droppedTxs = dropFailedTxs(pendingTxs, []*l1rolluptx.L1RollupTx{replacedTx})
+	assert.Equal(t, []*l1rolluptx.L1RollupTx{replacedTx, commit2, commit3}, droppedTxs)
+}
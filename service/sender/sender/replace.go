@@ -0,0 +1,195 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// sendRollupTx signs and broadcasts the calldata to the rollup contract with
+// the given nonce, fee and gas limit, the contract is bound to the currently
+// preferred l1 endpoint so that submissions follow the client when it fails over.
+func (s *Sender) sendRollupTx(data []byte, nonce uint64, fee *txFee, gasLimit uint64) (txHash string, err error) {
+	cli := s.cli.Provider()
+	contract := bind.NewBoundContract(common.HexToAddress(s.rollupAddress), ZkBNBContractAbi, cli, cli, cli)
+	opts := s.newTransactOpts()
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	fee.apply(opts)
+	opts.GasLimit = gasLimit
+	tx, err := contract.RawTransact(opts, data)
+	if err != nil {
+		return "", err
+	}
+	return tx.Hash().String(), nil
+}
+
+func (s *Sender) newTransactOpts() *bind.TransactOpts {
+	from := s.signer.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.signer.SignTx(tx, s.chainId)
+		},
+		Value:   big.NewInt(0),
+		Context: context.Background(),
+	}
+}
+
+// getMinedReceipt returns the receipt of the pending tx, or of one of the txs
+// it replaced if that one got mined instead, a nil receipt means none of them
+// has been mined yet.
+func (s *Sender) getMinedReceipt(pendingTx *l1rolluptx.L1RollupTx) (*ethtypes.Receipt, *l1rolluptx.L1RollupTx, error) {
+	receipt, err := s.cli.GetTransactionReceipt(pendingTx.L1TxHash)
+	if err == nil {
+		return receipt, pendingTx, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return nil, nil, err
+	}
+	if pendingTx.ReplacedTxHash == "" {
+		return nil, nil, nil
+	}
+
+	replacedTxs, err := s.l1RollupTxModel.GetL1RollupTxsByNonce(pendingTx.TxType, pendingTx.Nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get replaced txs, err: %v", err)
+	}
+	for _, replacedTx := range replacedTxs {
+		if replacedTx.TxStatus != l1rolluptx.StatusReplaced {
+			continue
+		}
+		receipt, err = s.cli.GetTransactionReceipt(replacedTx.L1TxHash)
+		if err == nil {
+			return receipt, replacedTx, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, nil
+}
+
+// replaceStuckTx re-broadcasts the calldata of a tx that has not been mined
+// in time, using the same nonce and a bumped gas price.
+func (s *Sender) replaceStuckTx(pendingTx *l1rolluptx.L1RollupTx) error {
+	currentFee, err := txFeeOf(pendingTx)
+	if err != nil {
+		return err
+	}
+	if currentFee == nil {
+		// The tx was sent without nonce tracking, fall back to resending it
+		// in the next round.
+		logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", pendingTx.L1TxHash)
+		return s.l1RollupTxModel.DeleteL1RollupTx(pendingTx)
+	}
+
+	confirmedNonce, err := s.cli.GetConfirmedNonce(s.signer.Address())
+	if err != nil {
+		return fmt.Errorf("failed to get confirmed nonce, err: %v", err)
+	}
+	if confirmedNonce > uint64(pendingTx.Nonce) {
+		// The nonce has been used but the receipt is not visible yet, wait for
+		// it instead of replacing.
+		logx.Infof("nonce of l1 rollup tx has been used, tx_hash=%s, nonce=%d", pendingTx.L1TxHash, pendingTx.Nonce)
+		return nil
+	}
+
+	originalFee := currentFee
+	replacedTxs, err := s.l1RollupTxModel.GetL1RollupTxsByNonce(pendingTx.TxType, pendingTx.Nonce)
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get replaced txs, err: %v", err)
+	}
+	if len(replacedTxs) > 0 {
+		originalFee, err = txFeeOf(replacedTxs[0])
+		if err != nil {
+			return err
+		}
+		if originalFee == nil || originalFee.isDynamic() != currentFee.isDynamic() {
+			originalFee = currentFee
+		}
+	}
+	fee := s.bumpTxFee(currentFee, originalFee)
+	if fee == nil {
+		logx.Errorf("l1 rollup tx is stuck and fee reached the bump cap, tx_hash=%s, %s",
+			pendingTx.L1TxHash, currentFee)
+		return nil
+	}
+
+	var data []byte
+	switch pendingTx.TxType {
+	case l1rolluptx.TxTypeCommit:
+		data, _, err = s.prepareCommitCall(pendingTx.L2StartBlockHeight, pendingTx.L2BlockHeight)
+	case l1rolluptx.TxTypeVerifyAndExecute:
+		data, _, err = s.prepareVerifyAndExecuteCall(pendingTx.L2StartBlockHeight, pendingTx.L2BlockHeight)
+	default:
+		return fmt.Errorf("invalid rollup tx type: %d", pendingTx.TxType)
+	}
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("blocks of rollup tx not found, from %d to %d", pendingTx.L2StartBlockHeight, pendingTx.L2BlockHeight)
+	}
+	// The stuck tx is not mined yet, so the replacement still runs against
+	// the state it was sent for, unless it waits for earlier in-flight txs.
+	var gasLimit uint64
+	if confirmedNonce == uint64(pendingTx.Nonce) {
+		gasLimit, err = s.simulateRollupTx(pendingTx.TxType, data)
+	} else {
+		gasLimit, err = s.maxGasLimit()
+	}
+	if err != nil {
+		return err
+	}
+
+	txHash, err := s.sendRollupTx(data, uint64(pendingTx.Nonce), fee, gasLimit)
+	if err != nil {
+		return fmt.Errorf("failed to send replacement tx, err: %v", err)
+	}
+	replacementTx := &l1rolluptx.L1RollupTx{
+		L1TxHash:           txHash,
+		TxStatus:           l1rolluptx.StatusPending,
+		TxType:             pendingTx.TxType,
+		L2BlockHeight:      pendingTx.L2BlockHeight,
+		L2StartBlockHeight: pendingTx.L2StartBlockHeight,
+		Nonce:              pendingTx.Nonce,
+		ReplacedTxHash:     pendingTx.L1TxHash,
+	}
+	fee.setTo(replacementTx)
+	err = s.l1RollupTxModel.ReplaceL1RollupTx(pendingTx, replacementTx)
+	if err != nil {
+		return fmt.Errorf("failed to create replacement tx in db, err: %v", err)
+	}
+	logx.Infof("stuck l1 rollup tx has been replaced, tx_hash=%s, replacement=%s, nonce=%d, %s",
+		pendingTx.L1TxHash, txHash, pendingTx.Nonce, fee)
+	return nil
+}
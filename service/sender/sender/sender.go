@@ -24,13 +24,14 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	zkbnb "github.com/bnb-chain/zkbnb-eth-rpc/core"
-	"github.com/bnb-chain/zkbnb-eth-rpc/rpc"
 	"github.com/bnb-chain/zkbnb/common/chain"
+	"github.com/bnb-chain/zkbnb/common/l1client"
 	"github.com/bnb-chain/zkbnb/common/prove"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
@@ -38,6 +39,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/proof"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	sconfig "github.com/bnb-chain/zkbnb/service/sender/config"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
 	"github.com/bnb-chain/zkbnb/types"
 )
 
@@ -45,9 +47,10 @@ type Sender struct {
 	config sconfig.Config
 
 	// Client
-	cli           *rpc.ProviderClient
-	authCli       *rpc.AuthClient
-	zkbnbInstance *zkbnb.ZkBNB
+	cli           *l1client.Client
+	signer        signer.Signer
+	chainId       *big.Int
+	rollupAddress string
 
 	// Data access objects
 	db                   *gorm.DB
@@ -59,6 +62,35 @@ type Sender struct {
 }
 
 func NewSender(c sconfig.Config) *Sender {
+	if c.ChainConfig.GasBumpPercent < defaultGasBumpPercent {
+		c.ChainConfig.GasBumpPercent = defaultGasBumpPercent
+	}
+	if c.ChainConfig.MaxGasBumpPercent == 0 {
+		c.ChainConfig.MaxGasBumpPercent = defaultMaxGasBumpPercent
+	}
+	if c.ChainConfig.MaxPendingTxs <= 0 {
+		c.ChainConfig.MaxPendingTxs = defaultMaxPendingTxs
+	}
+	if c.ChainConfig.GasEstimateBufferPercent == 0 {
+		c.ChainConfig.GasEstimateBufferPercent = defaultGasEstimateBufferPercent
+	}
+	if c.ChainConfig.DynamicFee.FeeHistoryBlocks == 0 {
+		c.ChainConfig.DynamicFee.FeeHistoryBlocks = defaultFeeHistoryBlocks
+	}
+	if c.ChainConfig.DynamicFee.PriorityFeePercentile <= 0 || c.ChainConfig.DynamicFee.PriorityFeePercentile > 100 {
+		c.ChainConfig.DynamicFee.PriorityFeePercentile = defaultPriorityFeePercentile
+	}
+	if c.ChainConfig.DynamicFee.BaseFeeMultiplier == 0 {
+		c.ChainConfig.DynamicFee.BaseFeeMultiplier = defaultBaseFeeMultiplier
+	}
+	if err := prometheus.Register(l1TxSimulationFailureMetric); err != nil {
+		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
+		panic(err)
+	}
+	if err := prometheus.Register(l1TxEstimatedGasMetric); err != nil {
+		logx.Severef("fatal error, cannot register prometheus, err: %s", err.Error())
+		panic(err)
+	}
 	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
 	if err != nil {
 		logx.Errorf("gorm connect db error, err = %v", err)
@@ -86,7 +118,7 @@ func NewSender(c sconfig.Config) *Sender {
 		panic(err)
 	}
 
-	s.cli, err = rpc.NewClient(l1RPCEndpoint.Value)
+	s.cli, err = l1client.NewClient(l1client.ParseEndpoints(l1RPCEndpoint.Value), c.ChainConfig.L1Client)
 	if err != nil {
 		panic(err)
 	}
@@ -94,98 +126,73 @@ func NewSender(c sconfig.Config) *Sender {
 	if err != nil {
 		panic(err)
 	}
-	s.authCli, err = rpc.NewAuthClient(c.ChainConfig.Sk, chainId)
-	if err != nil {
-		panic(err)
-	}
-	s.zkbnbInstance, err = zkbnb.LoadZkBNBInstance(s.cli, rollupAddress.Value)
+	s.chainId = chainId
+	s.signer, err = signer.New(c.ChainConfig.Signer, c.ChainConfig.Sk)
 	if err != nil {
+		logx.Severef("fatal error, cannot create signer, err: %v", err)
 		panic(err)
 	}
+	logx.Infof("sender address: %s", s.signer.Address().Hex())
+	s.rollupAddress = rollupAddress.Value
 	return s
 }
 
 func (s *Sender) CommitBlocks() (err error) {
-	var (
-		cli           = s.cli
-		authCli       = s.authCli
-		zkbnbInstance = s.zkbnbInstance
-	)
-	pendingTx, err := s.l1RollupTxModel.GetLatestPendingTx(l1rolluptx.TxTypeCommit)
-	if err != nil && err != types.DbErrNotFound {
+	if err = s.checkRevertedBlocks(); err != nil {
 		return err
 	}
-	// No need to submit new transaction if there is any pending commit txs.
-	if pendingTx != nil {
-		return nil
+	rollupTxs, err := s.submitRollupTxs(l1rolluptx.TxTypeCommit, s.prepareCommitCall)
+	for _, rollupTx := range rollupTxs {
+		logx.Infof("new blocks have been committed(height): %v:%s", rollupTx.L2BlockHeight, rollupTx.L1TxHash)
 	}
+	return err
+}
 
-	lastHandledTx, err := s.l1RollupTxModel.GetLatestHandledTx(l1rolluptx.TxTypeCommit)
-	if err != nil && err != types.DbErrNotFound {
-		return err
-	}
-	start := int64(1)
-	if lastHandledTx != nil {
-		start = lastHandledTx.L2BlockHeight + 1
+// checkRevertedBlocks stops the sender while blocks reverted on l1 are not
+// rolled back, the contract rejects blocks which do not follow the last
+// committed one and the reverted blocks can not be verified.
+func (s *Sender) checkRevertedBlocks() error {
+	revertedBlocks, err := s.blockModel.GetBlocksByStatus(block.StatusReverted)
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get reverted blocks, err: %v", err)
 	}
-	// commit new blocks
-	blocks, err := s.compressedBlockModel.GetCompressedBlocksBetween(start,
-		start+int64(s.config.ChainConfig.MaxBlockCount))
+	return fmt.Errorf("blocks from %d are reverted on l1, roll them back before sending rollup txs",
+		revertedBlocks[0].BlockHeight)
+}
+
+// prepareCommitCall packs the commitBlocks calldata of the compressed blocks
+// in [start, end], it returns nil calldata if there is no block to commit.
+func (s *Sender) prepareCommitCall(start, end int64) (data []byte, lastHeight int64, err error) {
+	blocks, err := s.compressedBlockModel.GetCompressedBlocksBetween(start, end)
 	if err != nil && err != types.DbErrNotFound {
-		return fmt.Errorf("failed to get compress block err: %v", err)
+		return nil, 0, fmt.Errorf("failed to get compress block err: %v", err)
 	}
 	if len(blocks) == 0 {
-		return nil
+		return nil, 0, nil
 	}
 	pendingCommitBlocks, err := ConvertBlocksForCommitToCommitBlockInfos(blocks)
 	if err != nil {
-		return fmt.Errorf("failed to get commit block info, err: %v", err)
+		return nil, 0, fmt.Errorf("failed to get commit block info, err: %v", err)
 	}
 	// get last block info
 	lastStoredBlockInfo := defaultBlockHeader()
-	if lastHandledTx != nil {
-		lastHandledBlockInfo, err := s.blockModel.GetBlockByHeight(lastHandledTx.L2BlockHeight)
+	if start > 1 {
+		lastHandledBlockInfo, err := s.blockModel.GetBlockByHeight(start - 1)
 		if err != nil {
-			return fmt.Errorf("failed to get block info, err: %v", err)
+			return nil, 0, fmt.Errorf("failed to get block info, err: %v", err)
 		}
 		// construct last stored block header
 		lastStoredBlockInfo = chain.ConstructStoredBlockInfo(lastHandledBlockInfo)
 	}
 
-	var gasPrice *big.Int
-	if s.config.ChainConfig.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(s.config.ChainConfig.GasPrice))
-	} else {
-		gasPrice, err = s.cli.SuggestGasPrice(context.Background())
-		if err != nil {
-			logx.Errorf("failed to fetch gas price: %v", err)
-			return err
-		}
-	}
-
-	// commit blocks on-chain
-	txHash, err := zkbnb.CommitBlocks(
-		cli, authCli,
-		zkbnbInstance,
-		lastStoredBlockInfo,
-		pendingCommitBlocks,
-		gasPrice,
-		s.config.ChainConfig.GasLimit)
+	data, err = ZkBNBContractAbi.Pack("commitBlocks", lastStoredBlockInfo, pendingCommitBlocks)
 	if err != nil {
-		return fmt.Errorf("failed to send commit tx, errL %v:%s", err, txHash)
+		return nil, 0, fmt.Errorf("failed to pack commit blocks call, err: %v", err)
 	}
-	newRollupTx := &l1rolluptx.L1RollupTx{
-		L1TxHash:      txHash,
-		TxStatus:      l1rolluptx.StatusPending,
-		TxType:        l1rolluptx.TxTypeCommit,
-		L2BlockHeight: int64(pendingCommitBlocks[len(pendingCommitBlocks)-1].BlockNumber),
-	}
-	err = s.l1RollupTxModel.CreateL1RollupTx(newRollupTx)
-	if err != nil {
-		return fmt.Errorf("failed to create tx in database, err: %v", err)
-	}
-	logx.Infof("new blocks have been committed(height): %v:%s", newRollupTx.L2BlockHeight, newRollupTx.L1TxHash)
-	return nil
+	return data, int64(pendingCommitBlocks[len(pendingCommitBlocks)-1].BlockNumber), nil
 }
 
 func (s *Sender) UpdateSentTxs() (err error) {
@@ -205,27 +212,38 @@ func (s *Sender) UpdateSentTxs() (err error) {
 	var (
 		pendingUpdateRxs         []*l1rolluptx.L1RollupTx
 		pendingUpdateProofStatus = make(map[int64]int)
+		failedTxs                []*l1rolluptx.L1RollupTx
 	)
 	for _, pendingTx := range pendingTxs {
-		txHash := pendingTx.L1TxHash
-		receipt, err := s.cli.GetTransactionReceipt(txHash)
+		receipt, minedTx, err := s.getMinedReceipt(pendingTx)
 		if err != nil {
-			logx.Errorf("query transaction receipt %s failed, err: %v", txHash, err)
-			if time.Now().After(pendingTx.UpdatedAt.Add(time.Duration(s.config.ChainConfig.MaxWaitingTime) * time.Second)) {
-				// No need to check the response, do best effort.
-				logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", pendingTx.L1TxHash)
-				//nolint:errcheck
-				s.l1RollupTxModel.DeleteL1RollupTx(pendingTx)
+			logx.Errorf("query transaction receipt %s failed, err: %v", pendingTx.L1TxHash, err)
+			continue
+		}
+		if receipt == nil {
+			if time.Now().After(pendingTx.CreatedAt.Add(time.Duration(s.config.ChainConfig.MaxWaitingTime) * time.Second)) {
+				err = s.replaceStuckTx(pendingTx)
+				if err != nil {
+					logx.Errorf("failed to replace stuck l1 rollup tx, tx_hash=%s, err: %v", pendingTx.L1TxHash, err)
+				}
 			}
 			continue
 		}
+		if minedTx != pendingTx {
+			// One of the replaced txs has been mined instead of the latest replacement.
+			logx.Infof("replaced l1 rollup tx has been mined, tx_hash=%s, replacement=%s", minedTx.L1TxHash, pendingTx.L1TxHash)
+			pendingTx.TxStatus = l1rolluptx.StatusReplaced
+			minedTx.TxStatus = l1rolluptx.StatusPending
+			pendingUpdateRxs = append(pendingUpdateRxs, pendingTx, minedTx)
+			pendingTx = minedTx
+		}
+		txHash := pendingTx.L1TxHash
 		if receipt.Status == 0 {
-			// Should direct mark tx deleted
-			logx.Infof("delete timeout l1 rollup tx, tx_hash=%s", pendingTx.L1TxHash)
-			//nolint:errcheck
-			s.l1RollupTxModel.DeleteL1RollupTx(pendingTx)
 			// It is critical to have any failed transactions
-			panic(fmt.Sprintf("unexpected failed tx: %v", txHash))
+			logx.Severef("unexpected failed tx: %v, blocks from %d to %d will be resent",
+				txHash, pendingTx.L2StartBlockHeight, pendingTx.L2BlockHeight)
+			failedTxs = append(failedTxs, pendingTx)
+			continue
 		}
 
 		// not finalized yet
@@ -249,7 +267,7 @@ func (s *Sender) UpdateSentTxs() (err error) {
 				validTx = int64(event.BlockNumber) == pendingTx.L2BlockHeight
 				pendingUpdateProofStatus[int64(event.BlockNumber)] = proof.Confirmed
 			case zkbnbLogBlocksRevertSigHash.Hex():
-				// TODO revert
+				// reverted blocks are marked by the monitor and rolled back by the operator
 			default:
 			}
 		}
@@ -260,6 +278,8 @@ func (s *Sender) UpdateSentTxs() (err error) {
 		}
 	}
 
+	droppedTxs := dropFailedTxs(pendingTxs, failedTxs)
+
 	//update db
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		//update l1 rollup txs
@@ -267,6 +287,11 @@ func (s *Sender) UpdateSentTxs() (err error) {
 		if err != nil {
 			return err
 		}
+		//drop failed txs and the ones building on them
+		err = s.l1RollupTxModel.DeleteL1RollupTxsInTransact(tx, droppedTxs)
+		if err != nil {
+			return err
+		}
 		//update proof status
 		err = s.proofModel.UpdateProofsInTransact(tx, pendingUpdateProofStatus)
 		return err
@@ -278,53 +303,43 @@ func (s *Sender) UpdateSentTxs() (err error) {
 }
 
 func (s *Sender) VerifyAndExecuteBlocks() (err error) {
-	var (
-		cli           = s.cli
-		authCli       = s.authCli
-		zkbnbInstance = s.zkbnbInstance
-	)
-	pendingTx, err := s.l1RollupTxModel.GetLatestPendingTx(l1rolluptx.TxTypeVerifyAndExecute)
-	if err != nil && err != types.DbErrNotFound {
+	if err = s.checkRevertedBlocks(); err != nil {
 		return err
 	}
-	// No need to submit new transaction if there is any pending verification txs.
-	if pendingTx != nil {
-		return nil
-	}
-
-	lastHandledTx, err := s.l1RollupTxModel.GetLatestHandledTx(l1rolluptx.TxTypeVerifyAndExecute)
-	if err != nil && err != types.DbErrNotFound {
-		return err
+	rollupTxs, err := s.submitRollupTxs(l1rolluptx.TxTypeVerifyAndExecute, s.prepareVerifyAndExecuteCall)
+	for _, rollupTx := range rollupTxs {
+		logx.Infof("new blocks have been verified and executed(height): %d:%s", rollupTx.L2BlockHeight, rollupTx.L1TxHash)
 	}
+	return err
+}
 
-	start := int64(1)
-	if lastHandledTx != nil {
-		start = lastHandledTx.L2BlockHeight + 1
-	}
-	blocks, err := s.blockModel.GetCommittedBlocksBetween(start,
-		start+int64(s.config.ChainConfig.MaxBlockCount))
+// prepareVerifyAndExecuteCall packs the verifyAndExecuteBlocks calldata of
+// the committed blocks in [start, end], it returns nil calldata if there is
+// no block to verify.
+func (s *Sender) prepareVerifyAndExecuteCall(start, end int64) (data []byte, lastHeight int64, err error) {
+	blocks, err := s.blockModel.GetCommittedBlocksBetween(start, end)
 	if err != nil && err != types.DbErrNotFound {
-		return fmt.Errorf("unable to get blocks to prove, err: %v", err)
+		return nil, 0, fmt.Errorf("unable to get blocks to prove, err: %v", err)
 	}
 	if len(blocks) == 0 {
-		return nil
+		return nil, 0, nil
 	}
 	pendingVerifyAndExecuteBlocks, err := ConvertBlocksToVerifyAndExecuteBlockInfos(blocks)
 	if err != nil {
-		return fmt.Errorf("unable to convert blocks to commit block infos: %v", err)
+		return nil, 0, fmt.Errorf("unable to convert blocks to commit block infos: %v", err)
 	}
 
 	blockProofs, err := s.proofModel.GetProofsBetween(start, start+int64(len(blocks))-1)
 	if err != nil {
-		return fmt.Errorf("unable to get proofs, err: %v", err)
+		return nil, 0, fmt.Errorf("unable to get proofs, err: %v", err)
 	}
 	if len(blockProofs) != len(blocks) {
-		return errors.New("related proofs not ready")
+		return nil, 0, errors.New("related proofs not ready")
 	}
 	// add sanity check
 	for i := range blockProofs {
 		if blockProofs[i].BlockNumber != blocks[i].BlockHeight {
-			return errors.New("proof number not match")
+			return nil, 0, errors.New("proof number not match")
 		}
 	}
 	var proofs []*big.Int
@@ -332,7 +347,7 @@ func (s *Sender) VerifyAndExecuteBlocks() (err error) {
 		var proofInfo *prove.FormattedProof
 		err = json.Unmarshal([]byte(bProof.ProofInfo), &proofInfo)
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
 		proofs = append(proofs, proofInfo.A[:]...)
 		proofs = append(proofs, proofInfo.B[0][0], proofInfo.B[0][1])
@@ -340,36 +355,11 @@ func (s *Sender) VerifyAndExecuteBlocks() (err error) {
 		proofs = append(proofs, proofInfo.C[:]...)
 	}
 
-	var gasPrice *big.Int
-	if s.config.ChainConfig.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(s.config.ChainConfig.GasPrice))
-	} else {
-		gasPrice, err = s.cli.SuggestGasPrice(context.Background())
-		if err != nil {
-			logx.Errorf("failed to fetch gas price: %v", err)
-			return err
-		}
-	}
-
-	// Verify blocks on-chain
-	txHash, err := zkbnb.VerifyAndExecuteBlocks(cli, authCli, zkbnbInstance,
-		pendingVerifyAndExecuteBlocks, proofs, gasPrice, s.config.ChainConfig.GasLimit)
-	if err != nil {
-		return fmt.Errorf("failed to send verify tx: %v:%s", err, txHash)
-	}
-
-	newRollupTx := &l1rolluptx.L1RollupTx{
-		L1TxHash:      txHash,
-		TxStatus:      l1rolluptx.StatusPending,
-		TxType:        l1rolluptx.TxTypeVerifyAndExecute,
-		L2BlockHeight: int64(pendingVerifyAndExecuteBlocks[len(pendingVerifyAndExecuteBlocks)-1].BlockHeader.BlockNumber),
-	}
-	err = s.l1RollupTxModel.CreateL1RollupTx(newRollupTx)
+	data, err = ZkBNBContractAbi.Pack("verifyAndExecuteBlocks", pendingVerifyAndExecuteBlocks, proofs)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to create rollup tx in db %v", err))
+		return nil, 0, fmt.Errorf("failed to pack verify and execute blocks call, err: %v", err)
 	}
-	logx.Infof("new blocks have been verified and executed(height): %d:%s", newRollupTx.L2BlockHeight, newRollupTx.L1TxHash)
-	return nil
+	return data, int64(pendingVerifyAndExecuteBlocks[len(pendingVerifyAndExecuteBlocks)-1].BlockHeader.BlockNumber), nil
 }
 
 func (s *Sender) Shutdown() {
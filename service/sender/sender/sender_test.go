@@ -0,0 +1,50 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+type revertedBlockModel struct {
+	block.BlockModel
+	reverted []*block.Block
+}
+
+func (m *revertedBlockModel) GetBlocksByStatus(status int64) ([]*block.Block, error) {
+	if status != block.StatusReverted || len(m.reverted) == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return m.reverted, nil
+}
+
+func TestSenderStopsOnRevertedBlocks(t *testing.T) {
+	blockModel := &revertedBlockModel{reverted: []*block.Block{{BlockHeight: 5}, {BlockHeight: 6}}}
+	s := &Sender{blockModel: blockModel}
+
+	// the guard fails before any rollup tx is prepared
+	assert.ErrorContains(t, s.CommitBlocks(), "blocks from 5 are reverted")
+	assert.ErrorContains(t, s.VerifyAndExecuteBlocks(), "blocks from 5 are reverted")
+
+	blockModel.reverted = nil
+	assert.NoError(t, s.checkRevertedBlocks())
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+)
+
+var (
+	errGasLimitExceeded = errors.New("rollup tx exceeds the gas limit")
+
+	l1TxSimulationFailureMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "zkbnb",
+		Name:      "l1_rollup_tx_simulation_failure",
+		Help:      "Number of rollup txs which failed the pre-flight simulation.",
+	}, []string{"type", "reason"})
+
+	l1TxEstimatedGasMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "zkbnb",
+		Name:      "l1_rollup_tx_estimated_gas",
+		Help:      "Estimated gas of the latest rollup tx.",
+	}, []string{"type"})
+)
+
+// prepareFunc packs the calldata of the blocks in [start, end], it returns
+// nil calldata if there is no block to submit.
+type prepareFunc func(start, end int64) (data []byte, lastHeight int64, err error)
+
+// prepareSimulatedCall packs the calldata of up to MaxBlockCount blocks from
+// start and simulates it, the batch is halved until it fits into the gas limit.
+func (s *Sender) prepareSimulatedCall(txType uint8, start int64, nonce uint64, prepare prepareFunc) (data []byte, lastHeight int64, gasLimit uint64, err error) {
+	count := int64(s.config.ChainConfig.MaxBlockCount)
+	canSimulate, err := s.canSimulate(nonce)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canSimulate {
+		data, lastHeight, err = prepare(start, start+count-1)
+		if err != nil || data == nil {
+			return nil, 0, 0, err
+		}
+		// A pipelined tx can never be included with more gas than a block
+		// has, so its gas limit is capped at the one of the latest block.
+		gasLimit, err = s.maxGasLimit()
+		return data, lastHeight, gasLimit, err
+	}
+	for {
+		data, lastHeight, err = prepare(start, start+count-1)
+		if err != nil || data == nil {
+			return nil, 0, 0, err
+		}
+		gasLimit, err = s.simulateRollupTx(txType, data)
+		if err == nil {
+			return data, lastHeight, gasLimit, nil
+		}
+		if err != errGasLimitExceeded || lastHeight == start {
+			return nil, 0, 0, err
+		}
+		count = (lastHeight - start + 1) / 2
+		logx.Infof("rollup tx of blocks from %d to %d exceeds the gas limit, retry with %d blocks",
+			start, lastHeight, count)
+	}
+}
+
+// simulateRollupTx runs the calldata against the latest l1 state and returns
+// the gas limit to send it with.
+func (s *Sender) simulateRollupTx(txType uint8, data []byte) (uint64, error) {
+	rollupAddress := common.HexToAddress(s.rollupAddress)
+	msg := ethereum.CallMsg{
+		From: s.signer.Address(),
+		To:   &rollupAddress,
+		Data: data,
+	}
+	maxGasLimit, err := s.maxGasLimit()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = s.cli.CallContract(msg); err != nil {
+		return 0, s.simulationFailure(txType, err)
+	}
+	gas, err := s.cli.EstimateGas(msg)
+	if err != nil {
+		return 0, s.simulationFailure(txType, err)
+	}
+	l1TxEstimatedGasMetric.WithLabelValues(txTypeName(txType)).Set(float64(gas))
+
+	gasLimit := percentOf(new(big.Int).SetUint64(gas), 100+s.config.ChainConfig.GasEstimateBufferPercent).Uint64()
+	if gas > maxGasLimit {
+		return 0, errGasLimitExceeded
+	}
+	if gasLimit > maxGasLimit {
+		gasLimit = maxGasLimit
+	}
+	return gasLimit, nil
+}
+
+// canSimulate reports whether the tx with the nonce is the next one to be
+// mined, later txs build on in-flight txs which are not part of the latest
+// l1 state yet, so they cannot be simulated.
+func (s *Sender) canSimulate(nonce uint64) (bool, error) {
+	confirmedNonce, err := s.cli.GetConfirmedNonce(s.signer.Address())
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmed nonce, err: %v", err)
+	}
+	return nonce <= confirmedNonce, nil
+}
+
+// maxGasLimit returns the configured gas limit capped by the gas limit of
+// the latest l1 block.
+func (s *Sender) maxGasLimit() (uint64, error) {
+	header, err := s.cli.GetLatestBlockHeader()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest l1 block header, err: %v", err)
+	}
+	maxGasLimit := s.config.ChainConfig.GasLimit
+	if maxGasLimit == 0 || maxGasLimit > header.GasLimit {
+		maxGasLimit = header.GasLimit
+	}
+	return maxGasLimit, nil
+}
+
+func (s *Sender) simulationFailure(txType uint8, err error) error {
+	if isGasLimitError(err) {
+		return errGasLimitExceeded
+	}
+	reason, ok := revertReason(err)
+	if !ok {
+		// The call did not get executed, e.g. the l1 endpoints are down.
+		return fmt.Errorf("failed to simulate rollup tx, err: %v", err)
+	}
+	l1TxSimulationFailureMetric.WithLabelValues(txTypeName(txType), reason).Inc()
+	logx.Errorf("rollup tx reverted in simulation, type=%s, reason: %s", txTypeName(txType), reason)
+	return fmt.Errorf("rollup tx reverted in simulation: %s", reason)
+}
+
+// revertReason decodes the reason of a reverted call, ok is false if the
+// error is not a revert.
+func revertReason(err error) (reason string, ok bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		if strings.Contains(err.Error(), "execution reverted") {
+			return "unknown", true
+		}
+		return "", false
+	}
+	if data, isHex := dataErr.ErrorData().(string); isHex {
+		if reason, err = abi.UnpackRevert(common.FromHex(data)); err == nil {
+			return reason, true
+		}
+	}
+	return "unknown", true
+}
+
+func isGasLimitError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "gas required exceeds allowance") ||
+		strings.Contains(msg, "exceeds block gas limit")
+}
+
+func txTypeName(txType uint8) string {
+	switch txType {
+	case l1rolluptx.TxTypeCommit:
+		return "commit"
+	case l1rolluptx.TxTypeVerifyAndExecute:
+		return "verify_and_execute"
+	}
+	return "unknown"
+}
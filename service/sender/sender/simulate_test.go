@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb/common/l1client"
+	sconfig "github.com/bnb-chain/zkbnb/service/sender/config"
+	"github.com/bnb-chain/zkbnb/service/sender/signer"
+)
+
+type revertError struct {
+	data interface{}
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+func TestRevertReason(t *testing.T) {
+	// Error(string) of "blk"
+	data := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000003" +
+		"626c6b0000000000000000000000000000000000000000000000000000000000"
+	reason, ok := revertReason(&revertError{data: data})
+	assert.True(t, ok)
+	assert.Equal(t, "blk", reason)
+
+	reason, ok = revertReason(&revertError{})
+	assert.True(t, ok)
+	assert.Equal(t, "unknown", reason)
+
+	_, ok = revertReason(errors.New("connection refused"))
+	assert.False(t, ok)
+
+	assert.True(t, isGasLimitError(errors.New("gas required exceeds allowance (30000000)")))
+}
+
+// stubL1 answers the confirmed nonce, the latest header and the simulation
+// of the rollup txs.
+func stubL1(t *testing.T, confirmedNonce, blockGasLimit, gas uint64, simulations *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = "0x64"
+		case "eth_getTransactionCount":
+			result = hexUint64(confirmedNonce)
+		case "eth_getBlockByNumber":
+			result = &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(0), GasLimit: blockGasLimit}
+		case "eth_call":
+			atomic.AddInt32(simulations, 1)
+			result = "0x"
+		case "eth_estimateGas":
+			atomic.AddInt32(simulations, 1)
+			result = hexUint64(gas)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  result,
+		}))
+	}))
+}
+
+func hexUint64(v uint64) string {
+	return "0x" + new(big.Int).SetUint64(v).Text(16)
+}
+
+func TestPrepareSimulatedCallGasLimit(t *testing.T) {
+	var simulations int32
+	server := stubL1(t, 5, 30_000_000, 1_000_000, &simulations)
+	defer server.Close()
+
+	cli, err := l1client.NewClient([]string{server.URL}, l1client.Config{})
+	assert.NoError(t, err)
+	txSigner, err := signer.NewPrivateKeySigner("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	assert.NoError(t, err)
+	c := sconfig.Config{}
+	c.ChainConfig.MaxBlockCount = 4
+	c.ChainConfig.GasLimit = 50_000_000
+	c.ChainConfig.GasEstimateBufferPercent = 20
+	s := &Sender{config: c, cli: cli, signer: txSigner, rollupAddress: "0x0000000000000000000000000000000000000001"}
+	prepare := func(start, end int64) ([]byte, int64, error) {
+		return []byte{0x01}, end, nil
+	}
+
+	// the next tx is simulated and sent with the estimated gas and buffer
+	_, _, gasLimit, err := s.prepareSimulatedCall(0, 1, 5, prepare)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1_200_000), gasLimit)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&simulations))
+
+	// a pipelined tx is not simulated, its gas limit is capped at the one of
+	// the latest block rather than the configured one
+	_, _, gasLimit, err = s.prepareSimulatedCall(0, 5, 7, prepare)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(30_000_000), gasLimit)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&simulations))
+}
@@ -37,6 +37,20 @@ import (
 const (
 	EventNameBlockCommit       = "BlockCommit"
 	EventNameBlockVerification = "BlockVerification"
+
+	// replacements must pay at least 10% more to be accepted by the tx pool
+	defaultGasBumpPercent    = 10
+	defaultMaxGasBumpPercent = 100
+
+	// only one tx of each type is in flight unless configured otherwise
+	defaultMaxPendingTxs = 1
+
+	// estimated gas is padded as the l1 state may change before the tx is mined
+	defaultGasEstimateBufferPercent = 20
+
+	defaultFeeHistoryBlocks      = 20
+	defaultPriorityFeePercentile = 50
+	defaultBaseFeeMultiplier     = 2
 )
 
 var (
@@ -0,0 +1,150 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type RemoteProtocol string
+
+const (
+	// Web3Signer serves eth_signTransaction and returns the raw signed tx.
+	Web3Signer RemoteProtocol = "web3signer"
+	// Clef serves account_signTransaction and returns the raw tx and its json.
+	Clef RemoteProtocol = "clef"
+
+	remoteSignTimeout = 30 * time.Second
+)
+
+var (
+	ErrInvalidRemoteSignature = errors.New("remote signer returned a tx which does not match the request")
+)
+
+type remoteSigner struct {
+	cli      *rpc.Client
+	protocol RemoteProtocol
+	address  common.Address
+}
+
+// sendTxArgs is the tx encoding accepted by eth_signTransaction and
+// account_signTransaction.
+type sendTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big     `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+type clefSignTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func NewRemoteSigner(url string, protocol RemoteProtocol, address common.Address) (Signer, error) {
+	if protocol == "" {
+		protocol = Web3Signer
+	}
+	if protocol != Web3Signer && protocol != Clef {
+		return nil, fmt.Errorf("unsupported remote signer protocol: %s", protocol)
+	}
+	if address == (common.Address{}) {
+		return nil, errors.New("address of the remote signer account is not configured")
+	}
+	cli, err := rpc.DialHTTP(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer, err: %v", err)
+	}
+	return &remoteSigner{cli: cli, protocol: protocol, address: address}, nil
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	args := sendTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainId),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignTimeout)
+	defer cancel()
+	var raw hexutil.Bytes
+	switch s.protocol {
+	case Clef:
+		var result clefSignTxResult
+		if err := s.cli.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+			return nil, fmt.Errorf("remote signer failed to sign tx, err: %v", err)
+		}
+		raw = result.Raw
+	default:
+		if err := s.cli.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+			return nil, fmt.Errorf("remote signer failed to sign tx, err: %v", err)
+		}
+	}
+
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode remotely signed tx, err: %v", err)
+	}
+	// Never broadcast anything else than what was asked for.
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover signer of remotely signed tx, err: %v", err)
+	}
+	if from != s.address || !sameTx(signedTx, tx) {
+		return nil, ErrInvalidRemoteSignature
+	}
+	return signedTx, nil
+}
+
+// sameTx compares the signed fields of two txs.
+func sameTx(a, b *types.Transaction) bool {
+	if (a.To() == nil) != (b.To() == nil) || (a.To() != nil && *a.To() != *b.To()) {
+		return false
+	}
+	return a.Type() == b.Type() && a.Nonce() == b.Nonce() && a.Gas() == b.Gas() &&
+		a.Value().Cmp(b.Value()) == 0 && a.GasFeeCap().Cmp(b.GasFeeCap()) == 0 &&
+		a.GasTipCap().Cmp(b.GasTipCap()) == 0 && bytes.Equal(a.Data(), b.Data())
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type Type string
+
+const (
+	PrivateKey Type = "privatekey"
+	Keystore   Type = "keystore"
+	Remote     Type = "remote"
+)
+
+var (
+	ErrUnsupportedSigner = errors.New("unsupported signer type")
+	ErrMissingPassphrase = errors.New("keystore passphrase is not configured")
+)
+
+// Signer signs the l1 txs of the sender, so that the key does not have to be
+// part of the sender config.
+type Signer interface {
+	Address() common.Address
+	SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+}
+
+type Config struct {
+	//nolint:staticcheck
+	Type Type `json:",optional"`
+	// Encrypted keystore file, the passphrase is read from the environment
+	// variable PassphraseEnv or the file PassphraseFile.
+	//nolint:staticcheck
+	KeystoreFile string `json:",optional"`
+	//nolint:staticcheck
+	PassphraseEnv string `json:",optional"`
+	//nolint:staticcheck
+	PassphraseFile string `json:",optional"`
+	// Remote signer endpoint and the address of the account to sign with.
+	//nolint:staticcheck
+	RemoteURL string `json:",optional"`
+	//nolint:staticcheck
+	RemoteProtocol RemoteProtocol `json:",optional"`
+	//nolint:staticcheck
+	Address string `json:",optional"`
+}
+
+// New creates the signer of the config, the hex private key sk is only used
+// by the private key signer, which is the default for backward compatibility.
+func New(c Config, sk string) (Signer, error) {
+	switch c.Type {
+	case PrivateKey, "":
+		return NewPrivateKeySigner(sk)
+	case Keystore:
+		passphrase, err := readPassphrase(c)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeystoreSigner(c.KeystoreFile, passphrase)
+	case Remote:
+		return NewRemoteSigner(c.RemoteURL, c.RemoteProtocol, common.HexToAddress(c.Address))
+	}
+	return nil, ErrUnsupportedSigner
+}
+
+func readPassphrase(c Config) (string, error) {
+	if c.PassphraseEnv != "" {
+		if passphrase, ok := os.LookupEnv(c.PassphraseEnv); ok {
+			return passphrase, nil
+		}
+	}
+	if c.PassphraseFile != "" {
+		data, err := ioutil.ReadFile(c.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file, err: %v", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return "", ErrMissingPassphrase
+}
+
+type keySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewPrivateKeySigner(sk string) (Signer, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(sk, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key, err: %v", err)
+	}
+	return &keySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+func NewKeystoreSigner(file string, passphrase string) (Signer, error) {
+	keyJson, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file, err: %v", err)
+	}
+	key, err := keystore.DecryptKey(keyJson, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file, err: %v", err)
+	}
+	return &keySigner{key: key.PrivateKey, address: key.Address}, nil
+}
+
+func (s *keySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *keySigner) SignTx(tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainId), s.key)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSk = "107f9d2a50ce2d8337e0c5220574e9fcf2bf60002da5acf07718f4d531ea3faa"
+
+var testChainId = big.NewInt(97)
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	return types.NewTx(&types.DynamicFeeTx{
+		Nonce:     7,
+		To:        &to,
+		Gas:       100000,
+		GasFeeCap: big.NewInt(2000),
+		GasTipCap: big.NewInt(100),
+		Value:     big.NewInt(0),
+		Data:      []byte{0x01, 0x02},
+	})
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testSk)
+	assert.NoError(t, err)
+	keyJson, err := keystore.EncryptKey(&keystore.Key{
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	assert.NoError(t, err)
+	keystoreFile := filepath.Join(t.TempDir(), "key.json")
+	assert.NoError(t, ioutil.WriteFile(keystoreFile, keyJson, 0600))
+
+	_, err = New(Config{Type: Keystore, KeystoreFile: keystoreFile}, "")
+	assert.Equal(t, ErrMissingPassphrase, err)
+
+	assert.NoError(t, os.Setenv("TEST_SENDER_PASSPHRASE", "secret"))
+	defer os.Unsetenv("TEST_SENDER_PASSPHRASE") //nolint:errcheck
+	s, err := New(Config{Type: Keystore, KeystoreFile: keystoreFile, PassphraseEnv: "TEST_SENDER_PASSPHRASE"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), s.Address())
+
+	signedTx, err := s.SignTx(testTx(), testChainId)
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(testChainId), signedTx)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Address(), from)
+}
+
+// remoteSignerStub serves eth_signTransaction like web3signer, tamper
+// changes the nonce of the signed tx.
+func remoteSignerStub(t *testing.T, tamper bool) *httptest.Server {
+	key, err := crypto.HexToECDSA(testSk)
+	assert.NoError(t, err)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []sendTxArgs    `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_signTransaction", req.Method)
+		args := req.Params[0]
+		nonce := uint64(args.Nonce)
+		if tamper {
+			nonce++
+		}
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   args.ChainID.ToInt(),
+			Nonce:     nonce,
+			To:        args.To,
+			Gas:       uint64(args.Gas),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		})
+		signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), key)
+		assert.NoError(t, err)
+		raw, err := signedTx.MarshalBinary()
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  hexutil.Bytes(raw),
+		}))
+	}))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testSk)
+	assert.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	server := remoteSignerStub(t, false)
+	defer server.Close()
+	s, err := New(Config{Type: Remote, RemoteURL: server.URL, Address: address.Hex()}, "")
+	assert.NoError(t, err)
+	tx := testTx()
+	signedTx, err := s.SignTx(tx, testChainId)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.Nonce(), signedTx.Nonce())
+
+	tamperingServer := remoteSignerStub(t, true)
+	defer tamperingServer.Close()
+	s, err = New(Config{Type: Remote, RemoteURL: tamperingServer.URL, Address: address.Hex()}, "")
+	assert.NoError(t, err)
+	_, err = s.SignTx(tx, testChainId)
+	assert.Equal(t, ErrInvalidRemoteSignature, err)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/bnb-chain/zkbnb/common/storage"
 	"github.com/bnb-chain/zkbnb/tree"
 )
 
@@ -19,6 +20,11 @@ type Config struct {
 		//nolint:staticcheck
 		RoutinePoolSize    int `json:",optional"`
 		AssetTreeCacheSize int
+		//nolint:staticcheck
+		Prune tree.PruneOption `json:",optional"`
 	}
 	LogConf logx.LogConf
+	// Storage of the witness data, it is kept in the database by default.
+	//nolint:staticcheck
+	WitnessStorage storage.Config `json:",optional"`
 }
@@ -38,6 +38,15 @@ func Run(configFile string) error {
 	if err != nil {
 		panic(err)
 	}
+	_, err = cronJob.AddFunc("@every 1m", func() {
+		err := w.PruneBlockWitnesses()
+		if err != nil {
+			logx.Errorf("failed to prune block witnesses, %v", err)
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
 	cronJob.Start()
 
 	exit := make(chan struct{})
@@ -2,19 +2,17 @@ package witness
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/zeromicro/go-zero/core/logx"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"github.com/bnb-chain/zkbnb-crypto/circuit"
 	bsmt "github.com/bnb-chain/zkbnb-smt"
 	smt "github.com/bnb-chain/zkbnb-smt"
 	utils "github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/common/storage"
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/block"
 	"github.com/bnb-chain/zkbnb/dao/blockwitness"
@@ -29,6 +27,8 @@ const (
 	UnprovedBlockWitnessTimeout = 10 * time.Minute
 
 	BlockProcessDelta = 10
+
+	PruneBatchSize = 100
 )
 
 type Witness struct {
@@ -41,6 +41,7 @@ type Witness struct {
 	accountTree smt.SparseMerkleTree
 	assetTrees  *tree.AssetTreeCache
 	nftTree     smt.SparseMerkleTree
+	treePruner  *tree.Pruner
 
 	// The data access object
 	db                  *gorm.DB
@@ -50,6 +51,7 @@ type Witness struct {
 	nftHistoryModel     nft.L2NftHistoryModel
 	proofModel          proof.ProofModel
 	blockWitnessModel   blockwitness.BlockWitnessModel
+	witnessStorage      *blockwitness.WitnessStorage
 }
 
 func NewWitness(c config.Config) (*Witness, error) {
@@ -59,6 +61,11 @@ func NewWitness(c config.Config) (*Witness, error) {
 		return nil, fmt.Errorf("gorm connect db error, err: %v", err)
 	}
 
+	blobs, err := storage.New(c.WitnessStorage)
+	if err != nil {
+		return nil, fmt.Errorf("init witness storage error, err: %v", err)
+	}
+
 	w := &Witness{
 		config:              c,
 		db:                  db,
@@ -68,6 +75,7 @@ func NewWitness(c config.Config) (*Witness, error) {
 		accountHistoryModel: account.NewAccountHistoryModel(db),
 		nftHistoryModel:     nft.NewL2NftHistoryModel(db),
 		proofModel:          proof.NewProofModel(db),
+		witnessStorage:      blockwitness.NewWitnessStorage(blobs),
 	}
 	err = w.initState()
 	return w, err
@@ -116,6 +124,8 @@ func (w *Witness) initState() error {
 		return fmt.Errorf("initNftTree error: %v", err)
 	}
 	w.helper = utils.NewWitnessHelper(w.treeCtx, w.accountTree, w.nftTree, w.assetTrees, w.accountModel, w.accountHistoryModel)
+	w.treePruner = tree.NewPruner(w.treeCtx, w.config.TreeDB.Prune, w.blockModel.GetLatestVerifiedHeight, w.assetTrees)
+	w.treePruner.Start()
 	return nil
 }
 
@@ -148,7 +158,9 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 			return fmt.Errorf("failed to construct block witness, block:%d, err: %v", block.BlockHeight, err)
 		}
 		// Step2: commit trees for witness
+		w.treePruner.Lock()
 		err = tree.CommitTrees(uint64(latestVerifiedBlockNr), w.accountTree, w.assetTrees, w.nftTree)
+		w.treePruner.Unlock()
 		if err != nil {
 			return fmt.Errorf("unable to commit trees after txs is executed, block:%d, error: %v", block.BlockHeight, err)
 		}
@@ -156,7 +168,9 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 		err = w.blockWitnessModel.CreateBlockWitness(blockWitness)
 		if err != nil {
 			// rollback trees
+			w.treePruner.Lock()
 			rollBackErr := tree.RollBackTrees(uint64(block.BlockHeight)-1, w.accountTree, w.assetTrees, w.nftTree)
+			w.treePruner.Unlock()
 			if rollBackErr != nil {
 				logx.Errorf("unable to rollback trees %v", rollBackErr)
 			}
@@ -181,6 +195,10 @@ func (w *Witness) RescheduleBlockWitness() {
 	if nextBlockWitness.Status == blockwitness.StatusPublished {
 		return
 	}
+	// skip if the proof of next block failed, it is proved again when the prover is restarted
+	if nextBlockWitness.Status == blockwitness.StatusFailed {
+		return
+	}
 
 	// skip if the next block proof exists
 	// if the proof is not submitted and verified in L1, there should be another alerts
@@ -232,63 +250,58 @@ func (w *Witness) getNextWitnessToCheck() (int64, error) {
 }
 
 func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockNr int64) (*blockwitness.BlockWitness, error) {
-	var oldStateRoot, newStateRoot []byte
-	txsWitness := make([]*utils.TxWitness, 0, block.BlockSize)
-	// scan each transaction
-	err := w.helper.ResetCache(block.BlockHeight)
+	b, err := w.helper.ConstructBlockWitness(block, uint64(latestVerifiedBlockNr))
 	if err != nil {
 		return nil, err
 	}
-	for idx, tx := range block.Txs {
-		txWitness, err := w.helper.ConstructTxWitness(tx, uint64(latestVerifiedBlockNr))
-		if err != nil {
-			return nil, err
-		}
-		txsWitness = append(txsWitness, txWitness)
-		// if it is the first tx of the block
-		if idx == 0 {
-			oldStateRoot = txWitness.StateRootBefore
-		}
-		// if it is the last tx of the block
-		if idx == len(block.Txs)-1 {
-			newStateRoot = txWitness.StateRootAfter
-		}
+	bz, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
 	}
-
-	emptyTxCount := int(block.BlockSize) - len(block.Txs)
-	for i := 0; i < emptyTxCount; i++ {
-		txsWitness = append(txsWitness, circuit.EmptyTx(newStateRoot))
+	blockWitness := blockwitness.BlockWitness{
+		Height:    block.BlockHeight,
+		BlockSize: block.BlockSize,
+		Status:    blockwitness.StatusPublished,
 	}
-
-	gasWitness, err := w.helper.ConstructGasWitness(block)
+	err = w.witnessStorage.SetWitnessData(&blockWitness, bz)
 	if err != nil {
 		return nil, err
 	}
+	return &blockWitness, nil
+}
 
-	newStateRoot = tree.ComputeStateRootHash(w.accountTree.Root(), w.nftTree.Root())
-	if common.Bytes2Hex(newStateRoot) != block.StateRoot {
-		return nil, errors.New("state root doesn't match")
+// PruneBlockWitnesses deletes the witness data of blocks which are verified
+// on l1, except for the latest RetainBlocks of them.
+func (w *Witness) PruneBlockWitnesses() error {
+	if !w.config.WitnessStorage.Prune {
+		return nil
 	}
-
-	b := &circuit.Block{
-		BlockNumber:     block.BlockHeight,
-		CreatedAt:       block.CreatedAt.UnixMilli(),
-		OldStateRoot:    oldStateRoot,
-		NewStateRoot:    newStateRoot,
-		BlockCommitment: common.FromHex(block.BlockCommitment),
-		Txs:             txsWitness,
-		Gas:             gasWitness,
+	latestVerifiedBlockNr, err := w.blockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		if err == types.DbErrNotFound {
+			return nil
+		}
+		return err
 	}
-	bz, err := json.Marshal(b)
+	witnesses, err := w.blockWitnessModel.GetBlockWitnessesToPrune(latestVerifiedBlockNr-w.config.WitnessStorage.RetainBlocks, PruneBatchSize)
 	if err != nil {
-		return nil, err
+		if err == types.DbErrNotFound {
+			return nil
+		}
+		return err
 	}
-	blockWitness := blockwitness.BlockWitness{
-		Height:      block.BlockHeight,
-		WitnessData: string(bz),
-		Status:      blockwitness.StatusPublished,
+	for _, blockWitness := range witnesses {
+		err = w.witnessStorage.DeleteWitnessData(blockWitness)
+		if err != nil {
+			return fmt.Errorf("failed to delete witness data, block: %d, err: %v", blockWitness.Height, err)
+		}
+		err = w.blockWitnessModel.UpdateBlockWitnessPruned(blockWitness)
+		if err != nil {
+			return fmt.Errorf("failed to mark witness pruned, block: %d, err: %v", blockWitness.Height, err)
+		}
 	}
-	return &blockWitness, nil
+	logx.Infof("pruned witnesses of blocks %d to %d", witnesses[0].Height, witnesses[len(witnesses)-1].Height)
+	return nil
 }
 
 func (w *Witness) Shutdown() {
@@ -300,6 +313,7 @@ func (w *Witness) Shutdown() {
 		logx.Errorf("close db error: %s", err.Error())
 	}
 
+	w.treePruner.Stop()
 	err = w.treeCtx.TreeDB.Close()
 	if err != nil {
 		logx.Errorf("close treedb error: %s", err.Error())
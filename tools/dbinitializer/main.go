@@ -30,6 +30,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/account"
 	"github.com/bnb-chain/zkbnb/dao/asset"
 	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockcheckpoint"
 	"github.com/bnb-chain/zkbnb/dao/blockwitness"
 	"github.com/bnb-chain/zkbnb/dao/compressedblock"
 	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
@@ -37,6 +38,7 @@ import (
 	"github.com/bnb-chain/zkbnb/dao/nft"
 	"github.com/bnb-chain/zkbnb/dao/priorityrequest"
 	"github.com/bnb-chain/zkbnb/dao/proof"
+	"github.com/bnb-chain/zkbnb/dao/stats"
 	"github.com/bnb-chain/zkbnb/dao/sysconfig"
 	"github.com/bnb-chain/zkbnb/dao/tx"
 	"github.com/bnb-chain/zkbnb/tree"
@@ -69,6 +71,8 @@ type dao struct {
 	blockModel           block.BlockModel
 	compressedBlockModel compressedblock.CompressedBlockModel
 	blockWitnessModel    blockwitness.BlockWitnessModel
+	blockCheckpointModel blockcheckpoint.BlockCheckpointModel
+	statModel            stats.StatModel
 	proofModel           proof.ProofModel
 	l1SyncedBlockModel   l1syncedblock.L1SyncedBlockModel
 	priorityRequestModel priorityrequest.PriorityRequestModel
@@ -103,6 +107,8 @@ func Initialize(
 		blockModel:           block.NewBlockModel(db),
 		compressedBlockModel: compressedblock.NewCompressedBlockModel(db),
 		blockWitnessModel:    blockwitness.NewBlockWitnessModel(db),
+		blockCheckpointModel: blockcheckpoint.NewBlockCheckpointModel(db),
+		statModel:            stats.NewStatModel(db),
 		proofModel:           proof.NewProofModel(db),
 		l1SyncedBlockModel:   l1syncedblock.NewL1SyncedBlockModel(db),
 		priorityRequestModel: priorityrequest.NewPriorityRequestModel(db),
@@ -237,6 +243,8 @@ func dropTables(dao *dao) {
 	assert.Nil(nil, dao.blockModel.DropBlockTable())
 	assert.Nil(nil, dao.compressedBlockModel.DropCompressedBlockTable())
 	assert.Nil(nil, dao.blockWitnessModel.DropBlockWitnessTable())
+	assert.Nil(nil, dao.blockCheckpointModel.DropBlockCheckpointTable())
+	assert.Nil(nil, dao.statModel.DropStatTables())
 	assert.Nil(nil, dao.proofModel.DropProofTable())
 	assert.Nil(nil, dao.l1SyncedBlockModel.DropL1SyncedBlockTable())
 	assert.Nil(nil, dao.priorityRequestModel.DropPriorityRequestTable())
@@ -256,6 +264,8 @@ func initTable(dao *dao, svrConf *contractAddr, bscTestNetworkRPC, localTestNetw
 	assert.Nil(nil, dao.txDetailModel.CreateTxDetailTable())
 	assert.Nil(nil, dao.compressedBlockModel.CreateCompressedBlockTable())
 	assert.Nil(nil, dao.blockWitnessModel.CreateBlockWitnessTable())
+	assert.Nil(nil, dao.blockCheckpointModel.CreateBlockCheckpointTable())
+	assert.Nil(nil, dao.statModel.CreateStatTables())
 	assert.Nil(nil, dao.proofModel.CreateProofTable())
 	assert.Nil(nil, dao.l1SyncedBlockModel.CreateL1SyncedBlockTable())
 	assert.Nil(nil, dao.priorityRequestModel.CreatePriorityRequestTable())
@@ -11,7 +11,8 @@ type Config struct {
 	Postgres struct {
 		DataSource string
 	}
-	CacheRedis cache.CacheConf
+	//nolint:staticcheck
+	CacheRedis cache.CacheConf `json:",optional"`
 	TreeDB     struct {
 		Driver tree.Driver
 		//nolint:staticcheck
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+type Config struct {
+	Postgres struct {
+		DataSource string
+	}
+	// CacheDriver is redis by default, memory means there is no cache to clean.
+	//nolint:staticcheck
+	CacheDriver dbcache.Driver `json:",optional"`
+	//nolint:staticcheck
+	CacheRedis cache.CacheConf `json:",optional"`
+	TreeDB     struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		LevelDBOption tree.LevelDBOption `json:",optional"`
+		//nolint:staticcheck
+		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		RoutinePoolSize    int `json:",optional"`
+		AssetTreeCacheSize int
+	}
+	LogConf logx.LogConf
+}
@@ -0,0 +1,71 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockcheckpoint"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/compressedblock"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/proof"
+	"github.com/bnb-chain/zkbnb/dao/stats"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tools/rollback/internal/config"
+)
+
+type ServiceContext struct {
+	Config config.Config
+
+	DB                   *gorm.DB
+	RedisCache           dbcache.Cache
+	BlockModel           block.BlockModel
+	BlockCheckpointModel blockcheckpoint.BlockCheckpointModel
+	CompressedBlockModel compressedblock.CompressedBlockModel
+	TxModel              tx.TxModel
+	TxPoolModel          tx.TxPoolModel
+	AccountModel         account.AccountModel
+	AccountHistoryModel  account.AccountHistoryModel
+	L2NftModel           nft.L2NftModel
+	L2NftHistoryModel    nft.L2NftHistoryModel
+	L1RollupTxModel      l1rolluptx.L1RollupTxModel
+	ProofModel           proof.ProofModel
+	BlockWitnessModel    blockwitness.BlockWitnessModel
+	StatModel            stats.StatModel
+}
+
+func NewServiceContext(c config.Config) *ServiceContext {
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		logx.Errorf("gorm connect db error, err = %s", err.Error())
+	}
+	redisCache, err := dbcache.NewCache(c.CacheDriver, c.CacheRedis, 15*time.Minute)
+	if err != nil {
+		logx.Must(err)
+	}
+	return &ServiceContext{
+		Config:               c,
+		DB:                   db,
+		RedisCache:           redisCache,
+		BlockModel:           block.NewBlockModel(db),
+		BlockCheckpointModel: blockcheckpoint.NewBlockCheckpointModel(db),
+		CompressedBlockModel: compressedblock.NewCompressedBlockModel(db),
+		TxModel:              tx.NewTxModel(db),
+		TxPoolModel:          tx.NewTxPoolModel(db),
+		AccountModel:         account.NewAccountModel(db),
+		AccountHistoryModel:  account.NewAccountHistoryModel(db),
+		L2NftModel:           nft.NewL2NftModel(db),
+		L2NftHistoryModel:    nft.NewL2NftHistoryModel(db),
+		L1RollupTxModel:      l1rolluptx.NewL1RollupTxModel(db),
+		ProofModel:           proof.NewProofModel(db),
+		BlockWitnessModel:    blockwitness.NewBlockWitnessModel(db),
+		StatModel:            stats.NewStatModel(db),
+	}
+}
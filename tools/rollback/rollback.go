@@ -0,0 +1,324 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+	"gorm.io/gorm"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/dbcache"
+	"github.com/bnb-chain/zkbnb/dao/l1rolluptx"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/dao/tx"
+	"github.com/bnb-chain/zkbnb/tools/rollback/internal/config"
+	"github.com/bnb-chain/zkbnb/tools/rollback/internal/svc"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// treeServices are the services keeping their own state trees.
+var treeServices = []string{"committer", "witness"}
+
+// RollbackToHeight rolls the l2 state, the state trees, the rollup txs, the
+// proofs and the witnesses back to the block height, the txs of the later
+// blocks are put back to the tx pool. Blocks up to the height which were
+// reverted on l1 are committed again by the sender.
+// All services have to be stopped while rolling back.
+func RollbackToHeight(configFile string, height int64) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	ctx := svc.NewServiceContext(c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	proc.AddShutdownListener(func() {
+		logx.Close()
+	})
+
+	latestVerifiedHeight, err := ctx.BlockModel.GetLatestVerifiedHeight()
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get latest verified height, err: %v", err)
+	}
+	if height < latestVerifiedHeight {
+		return fmt.Errorf("cannot roll back verified blocks, latest verified height: %d", latestVerifiedHeight)
+	}
+	// Blocks which stay on l2 but were reverted on l1 have to be committed again.
+	lastCommittedHeight := height
+	revertedBlocks, err := ctx.BlockModel.GetBlocksByStatus(block.StatusReverted)
+	if err != nil && err != types.DbErrNotFound {
+		return fmt.Errorf("failed to get reverted blocks, err: %v", err)
+	}
+	var recommitBlocks []*block.Block
+	recommitTxStatus := make(map[int64]int)
+	for _, revertedBlock := range revertedBlocks {
+		if revertedBlock.BlockHeight-1 < lastCommittedHeight {
+			lastCommittedHeight = revertedBlock.BlockHeight - 1
+		}
+		if revertedBlock.BlockHeight <= height {
+			revertedBlock.BlockStatus = block.StatusPending
+			revertedBlock.CommittedTxHash = ""
+			revertedBlock.CommittedAt = 0
+			recommitBlocks = append(recommitBlocks, revertedBlock)
+			recommitTxStatus[revertedBlock.BlockHeight] = tx.StatusPacked
+		}
+	}
+
+	pendingUpdateAccounts, pendingDeleteAccounts, err := rollbackAccounts(ctx, height)
+	if err != nil {
+		return err
+	}
+	pendingUpdateNfts, pendingDeleteNfts, err := rollbackNfts(ctx, height)
+	if err != nil {
+		return err
+	}
+	committedBlocks, err := committedBlocksAfter(ctx, height)
+	if err != nil {
+		return err
+	}
+
+	var requeuedTxs int64
+	err = ctx.DB.Transaction(func(dbTx *gorm.DB) error {
+		// state
+		err := ctx.AccountModel.UpdateAccountsInTransact(dbTx, pendingUpdateAccounts)
+		if err != nil {
+			return err
+		}
+		err = ctx.AccountModel.DeleteAccountsInTransact(dbTx, pendingDeleteAccounts)
+		if err != nil {
+			return err
+		}
+		err = ctx.AccountHistoryModel.DeleteAccountHistoriesAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.L2NftModel.UpdateNftsInTransact(dbTx, pendingUpdateNfts)
+		if err != nil {
+			return err
+		}
+		err = ctx.L2NftModel.DeleteNftsInTransact(dbTx, pendingDeleteNfts)
+		if err != nil {
+			return err
+		}
+		err = ctx.L2NftHistoryModel.DeleteNftHistoriesAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		// analytics
+		for _, committedBlock := range committedBlocks {
+			err = ctx.StatModel.SubtractTxsInTransact(dbTx, committedBlock.CreatedAt, committedBlock.Txs)
+			if err != nil {
+				return err
+			}
+		}
+		// txs
+		requeuedTxs, err = ctx.TxPoolModel.RequeueTxsAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.TxModel.DeleteTxsAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		// blocks
+		err = ctx.CompressedBlockModel.DeleteCompressedBlocksAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.BlockModel.DeleteBlocksAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.BlockCheckpointModel.DeleteBlockCheckpointsAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.BlockModel.UpdateBlocksWithoutTxsInTransact(dbTx, recommitBlocks)
+		if err != nil {
+			return err
+		}
+		if len(recommitTxStatus) > 0 {
+			err = ctx.TxModel.UpdateTxsStatusInTransact(dbTx, recommitTxStatus)
+			if err != nil {
+				return err
+			}
+		}
+		// rollup txs, proofs and witnesses
+		err = ctx.L1RollupTxModel.TruncateL1RollupTxsInTransact(dbTx, l1rolluptx.TxTypeCommit, lastCommittedHeight)
+		if err != nil {
+			return err
+		}
+		err = ctx.L1RollupTxModel.TruncateL1RollupTxsInTransact(dbTx, l1rolluptx.TxTypeVerifyAndExecute, height)
+		if err != nil {
+			return err
+		}
+		err = ctx.ProofModel.DeleteProofsAfterHeightInTransact(dbTx, height)
+		if err != nil {
+			return err
+		}
+		return ctx.BlockWitnessModel.DeleteBlockWitnessesAfterHeightInTransact(dbTx, height)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back database, err: %v", err)
+	}
+	logx.Infof("database rolled back to height %d, %d txs requeued, blocks after %d will be committed again",
+		height, requeuedTxs, lastCommittedHeight)
+
+	// The cached state of the touched accounts and nfts is stale now.
+	for _, accountIndex := range append(pendingDeleteAccounts, accountIndexes(pendingUpdateAccounts)...) {
+		if err = ctx.RedisCache.Delete(context.Background(), dbcache.AccountKeyByIndex(accountIndex)); err != nil {
+			return fmt.Errorf("failed to delete account cache, err: %v", err)
+		}
+	}
+	for _, nftIndex := range append(pendingDeleteNfts, nftIndexes(pendingUpdateNfts)...) {
+		if err = ctx.RedisCache.Delete(context.Background(), dbcache.NftKeyByIndex(nftIndex)); err != nil {
+			return fmt.Errorf("failed to delete nft cache, err: %v", err)
+		}
+	}
+	if err = ctx.RedisCache.Delete(context.Background(), dbcache.GasAccountKey); err != nil {
+		return fmt.Errorf("failed to delete gas account cache, err: %v", err)
+	}
+
+	for _, serviceName := range treeServices {
+		err = rollbackTrees(c, ctx, serviceName, height, pendingDeleteAccounts)
+		if err != nil {
+			return fmt.Errorf("failed to roll back %s trees, err: %v", serviceName, err)
+		}
+	}
+	logx.Infof("rolled back to height %d", height)
+	return nil
+}
+
+// committedBlocksAfter returns the blocks after height except the proposing one, whose txs are not in the stats.
+func committedBlocksAfter(ctx *svc.ServiceContext, height int64) ([]*block.Block, error) {
+	currentHeight, err := ctx.BlockModel.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current height, err: %v", err)
+	}
+	blocks := make([]*block.Block, 0, currentHeight-height)
+	for blockHeight := height + 1; blockHeight <= currentHeight; blockHeight++ {
+		b, err := ctx.BlockModel.GetBlockByHeight(blockHeight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d, err: %v", blockHeight, err)
+		}
+		if b.BlockStatus <= block.StatusProposing {
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// rollbackAccounts restores the accounts changed after height from their
+// history, accounts created after height are deleted.
+func rollbackAccounts(ctx *svc.ServiceContext, height int64) (pendingUpdate []*account.Account, pendingDelete []int64, err error) {
+	indexes, err := ctx.AccountHistoryModel.GetAccountIndexesAfterHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get changed accounts, err: %v", err)
+	}
+	for _, accountIndex := range indexes {
+		history, err := ctx.AccountHistoryModel.GetLatestAccountHistory(accountIndex, height+1)
+		if err == types.DbErrNotFound {
+			pendingDelete = append(pendingDelete, accountIndex)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account history, index: %d, err: %v", accountIndex, err)
+		}
+		accountInfo, err := ctx.AccountModel.GetAccountByIndex(accountIndex)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account, index: %d, err: %v", accountIndex, err)
+		}
+		accountInfo.Nonce = history.Nonce
+		accountInfo.CollectionNonce = history.CollectionNonce
+		accountInfo.AssetInfo = history.AssetInfo
+		accountInfo.AssetRoot = history.AssetRoot
+		pendingUpdate = append(pendingUpdate, accountInfo)
+	}
+	return pendingUpdate, pendingDelete, nil
+}
+
+// rollbackNfts restores the nfts changed after height from their history,
+// nfts minted after height are deleted.
+func rollbackNfts(ctx *svc.ServiceContext, height int64) (pendingUpdate []*nft.L2Nft, pendingDelete []int64, err error) {
+	indexes, err := ctx.L2NftHistoryModel.GetNftIndexesAfterHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get changed nfts, err: %v", err)
+	}
+	for _, nftIndex := range indexes {
+		history, err := ctx.L2NftHistoryModel.GetLatestNftHistory(nftIndex, height+1)
+		if err == types.DbErrNotFound {
+			pendingDelete = append(pendingDelete, nftIndex)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get nft history, index: %d, err: %v", nftIndex, err)
+		}
+		nftInfo, err := ctx.L2NftModel.GetNft(nftIndex)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get nft, index: %d, err: %v", nftIndex, err)
+		}
+		nftInfo.CreatorAccountIndex = history.CreatorAccountIndex
+		nftInfo.OwnerAccountIndex = history.OwnerAccountIndex
+		nftInfo.NftContentHash = history.NftContentHash
+		nftInfo.NftL1Address = history.NftL1Address
+		nftInfo.NftL1TokenId = history.NftL1TokenId
+		nftInfo.CreatorTreasuryRate = history.CreatorTreasuryRate
+		nftInfo.CollectionId = history.CollectionId
+		pendingUpdate = append(pendingUpdate, nftInfo)
+	}
+	return pendingUpdate, pendingDelete, nil
+}
+
+// rollbackTrees rolls the persistent trees of the service back to height,
+// in-memory trees are rebuilt from the database on start anyway.
+func rollbackTrees(c config.Config, ctx *svc.ServiceContext, serviceName string, height int64, deletedAccounts []int64) error {
+	if c.TreeDB.Driver == tree.MemoryDB {
+		return nil
+	}
+	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize, &c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption)
+	if err != nil {
+		return err
+	}
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return err
+	}
+
+	// Trees with versions after height are rolled back when they are loaded.
+	_, assetTrees, err := tree.InitAccountTree(ctx.AccountModel, ctx.AccountHistoryModel, height, treeCtx, c.TreeDB.AssetTreeCacheSize)
+	if err != nil {
+		return err
+	}
+	// The asset trees of deleted accounts are not loaded, roll them back explicitly.
+	for _, accountIndex := range deletedAccounts {
+		assetTree := assetTrees.Get(accountIndex)
+		if assetTree.LatestVersion() > bsmt.Version(height) && !assetTree.IsEmpty() {
+			if err = assetTree.Rollback(bsmt.Version(height)); err != nil {
+				return fmt.Errorf("unable to rollback asset tree [%d], err: %v", accountIndex, err)
+			}
+		}
+	}
+	_, err = tree.InitNftTree(ctx.L2NftHistoryModel, height, treeCtx)
+	return err
+}
+
+func accountIndexes(accounts []*account.Account) []int64 {
+	indexes := make([]int64, 0, len(accounts))
+	for _, accountInfo := range accounts {
+		indexes = append(indexes, accountInfo.AccountIndex)
+	}
+	return indexes
+}
+
+func nftIndexes(nfts []*nft.L2Nft) []int64 {
+	indexes := make([]int64, 0, len(nfts))
+	for _, nftInfo := range nfts {
+		indexes = append(indexes, nftInfo.NftIndex)
+	}
+	return indexes
+}
@@ -0,0 +1,190 @@
+package snapshot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+
+	"github.com/bnb-chain/zkbnb/common/chain"
+	"github.com/bnb-chain/zkbnb/common/snapshot"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/tools/snapshot/internal/config"
+	"github.com/bnb-chain/zkbnb/tools/snapshot/internal/svc"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+func loadServiceContext(configFile string) *svc.ServiceContext {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	proc.AddShutdownListener(func() {
+		logx.Close()
+	})
+	return svc.NewServiceContext(c)
+}
+
+// ExportSnapshot writes the accounts and nfts at the verified block height
+// into the snapshot file. The roots are recomputed and must match the state
+// root of the block.
+func ExportSnapshot(configFile string, height int64, file string, chunkSize int) error {
+	ctx := loadServiceContext(configFile)
+	if chunkSize <= 0 {
+		return errors.New("invalid batch size")
+	}
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("snapshot file %s already exists", file)
+	}
+
+	b, err := ctx.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block %d, err: %v", height, err)
+	}
+	if height <= 0 || b.BlockStatus != block.StatusVerifiedAndExecuted {
+		return fmt.Errorf("block %d is not verified", height)
+	}
+
+	tmpFile := file + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	footer, err := writeSnapshot(ctx, b, w, chunkSize)
+	if err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile, file); err != nil {
+		return err
+	}
+	logx.Infof("exported %d accounts and %d nfts at height %d", footer.Accounts, footer.Nfts, height)
+	return nil
+}
+
+func writeSnapshot(ctx *svc.ServiceContext, b *block.Block, w *bufio.Writer, chunkSize int) (*snapshot.Footer, error) {
+	height := b.BlockHeight
+	sw, err := snapshot.NewWriter(w, &snapshot.Header{
+		Height: height,
+		Block: &snapshot.Block{
+			BlockSize:                       b.BlockSize,
+			BlockCommitment:                 b.BlockCommitment,
+			BlockHeight:                     b.BlockHeight,
+			StateRoot:                       b.StateRoot,
+			PriorityOperations:              b.PriorityOperations,
+			PendingOnChainOperationsHash:    b.PendingOnChainOperationsHash,
+			PendingOnChainOperationsPubData: b.PendingOnChainOperationsPubData,
+			CommittedTxHash:                 b.CommittedTxHash,
+			CommittedAt:                     b.CommittedAt,
+			VerifiedTxHash:                  b.VerifiedTxHash,
+			VerifiedAt:                      b.VerifiedAt,
+			CreatedAt:                       b.CreatedAt.UnixMilli(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	trees, err := newMemStateTrees()
+	if err != nil {
+		return nil, err
+	}
+
+	accountNums, err := ctx.AccountHistoryModel.GetValidAccountCount(height)
+	if err != nil {
+		return nil, err
+	}
+	var accounts int64
+	for offset := 0; offset < int(accountNums); offset += chunkSize {
+		accountInfos, err := tree.LoadValidAccounts(ctx.AccountModel, ctx.AccountHistoryModel, height, offset, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		chunk := make([]*snapshot.Account, 0, len(accountInfos))
+		for _, accountInfo := range accountInfos {
+			a, err := chain.FromFormatAccountInfo(accountInfo)
+			if err != nil {
+				return nil, err
+			}
+			snapshotAccount := &snapshot.Account{
+				AccountIndex:    a.AccountIndex,
+				AccountName:     a.AccountName,
+				PublicKey:       a.PublicKey,
+				AccountNameHash: a.AccountNameHash,
+				L1Address:       a.L1Address,
+				Nonce:           a.Nonce,
+				CollectionNonce: a.CollectionNonce,
+				AssetInfo:       a.AssetInfo,
+				AssetRoot:       a.AssetRoot,
+			}
+			if err = trees.setAccount(snapshotAccount); err != nil {
+				return nil, err
+			}
+			chunk = append(chunk, snapshotAccount)
+		}
+		if err = sw.WriteAccounts(chunk); err != nil {
+			return nil, err
+		}
+		accounts += int64(len(chunk))
+	}
+	if accounts != accountNums {
+		return nil, fmt.Errorf("exported %d accounts, expected %d", accounts, accountNums)
+	}
+
+	nftNums, err := ctx.L2NftHistoryModel.GetLatestNftsCountByBlockHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset < int(nftNums); offset += chunkSize {
+		_, nftAssets, err := ctx.L2NftHistoryModel.GetLatestNftsByBlockHeight(height, chunkSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		chunk := make([]*snapshot.Nft, 0, len(nftAssets))
+		for _, nftAsset := range nftAssets {
+			snapshotNft := &snapshot.Nft{
+				NftIndex:            nftAsset.NftIndex,
+				CreatorAccountIndex: nftAsset.CreatorAccountIndex,
+				OwnerAccountIndex:   nftAsset.OwnerAccountIndex,
+				NftContentHash:      nftAsset.NftContentHash,
+				NftL1Address:        nftAsset.NftL1Address,
+				NftL1TokenId:        nftAsset.NftL1TokenId,
+				CreatorTreasuryRate: nftAsset.CreatorTreasuryRate,
+				CollectionId:        nftAsset.CollectionId,
+			}
+			if err = trees.setNft(snapshotNft); err != nil {
+				return nil, err
+			}
+			chunk = append(chunk, snapshotNft)
+		}
+		if err = sw.WriteNfts(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	accountRoot, nftRoot := trees.accountTree.Root(), trees.nftTree.Root()
+	stateRoot := common.Bytes2Hex(tree.ComputeStateRootHash(accountRoot, nftRoot))
+	if stateRoot != b.StateRoot {
+		return nil, fmt.Errorf("state root mismatch at height %d, block: %s, database: %s", height, b.StateRoot, stateRoot)
+	}
+	if err = sw.Close(common.Bytes2Hex(accountRoot), common.Bytes2Hex(nftRoot)); err != nil {
+		return nil, err
+	}
+	return sw.Footer(), nil
+}
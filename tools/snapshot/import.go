@@ -0,0 +1,252 @@
+package snapshot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/common/snapshot"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/tools/snapshot/internal/svc"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+var treeServices = []string{"committer", "witness"}
+
+// ImportSnapshot restores the state of the snapshot file into a database
+// which only contains the genesis block, and into the empty trees of the
+// service, or of the committer and the witness if serviceName is empty.
+// The trees are built from the snapshot leaves directly, the services then
+// start from the snapshot height without reloading the trees.
+func ImportSnapshot(configFile string, file string, serviceName string) error {
+	ctx := loadServiceContext(configFile)
+	c := ctx.Config
+
+	// The whole file is verified before anything is written.
+	header, footer, err := verifyFile(file)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot file, err: %v", err)
+	}
+	height := header.Height
+	stateRoot := common.Bytes2Hex(tree.ComputeStateRootHash(common.FromHex(footer.AccountRoot), common.FromHex(footer.NftRoot)))
+	if height <= 0 || stateRoot != header.Block.StateRoot {
+		return errors.New("invalid snapshot file, the roots don't match the state root of the block")
+	}
+
+	currentHeight, err := ctx.BlockModel.GetCurrentBlockHeight()
+	if err == types.DbErrNotFound {
+		return errors.New("the genesis block does not exist, initialize the database first")
+	}
+	if err != nil {
+		return err
+	}
+	accountCount, err := ctx.AccountModel.GetAccountsTotalCount()
+	if err != nil {
+		return err
+	}
+	if currentHeight != 0 || accountCount != 0 {
+		return errors.New("the database is not empty")
+	}
+
+	var targets []*stateTrees
+	if c.TreeDB.Driver == tree.MemoryDB {
+		// The trees are rebuilt by the services, they are only checked.
+		trees, err := newMemStateTrees()
+		if err != nil {
+			return err
+		}
+		targets = append(targets, trees)
+	} else {
+		services := treeServices
+		if serviceName != "" {
+			services = []string{serviceName}
+		}
+		for _, service := range services {
+			treeCtx, err := tree.NewContext(service, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize, &c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption)
+			if err != nil {
+				return err
+			}
+			if err = tree.SetupTreeDB(treeCtx); err != nil {
+				return err
+			}
+			defer treeCtx.TreeDB.Close()
+			trees, err := newStateTrees(treeCtx, height)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, trees)
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := snapshot.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	err = ctx.DB.Transaction(func(dbTx *gorm.DB) error {
+		return importSnapshot(ctx, dbTx, r, targets)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import snapshot, the treedb must be cleared before retrying, err: %v", err)
+	}
+	logx.Infof("imported %d accounts and %d nfts at height %d", footer.Accounts, footer.Nfts, height)
+	return nil
+}
+
+func verifyFile(file string) (*snapshot.Header, *snapshot.Footer, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return snapshot.Verify(bufio.NewReader(f))
+}
+
+func importSnapshot(ctx *svc.ServiceContext, dbTx *gorm.DB, r *snapshot.Reader, targets []*stateTrees) error {
+	height := r.Header().Height
+	for {
+		chunk, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch chunk.Kind {
+		case snapshot.ChunkAccounts:
+			err = importAccounts(ctx, dbTx, height, chunk.Accounts, targets)
+		case snapshot.ChunkNfts:
+			err = importNfts(ctx, dbTx, height, chunk.Nfts, targets)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The roots are checked before the account and nft trees are written.
+	for _, trees := range targets {
+		if err := trees.checkRoots(r.Footer()); err != nil {
+			return err
+		}
+		if err := trees.commit(height); err != nil {
+			return err
+		}
+	}
+
+	b := r.Header().Block
+	err := ctx.BlockModel.CreateBlockInTransact(dbTx, &block.Block{
+		Model: gorm.Model{
+			CreatedAt: time.UnixMilli(b.CreatedAt),
+		},
+		BlockSize:                       b.BlockSize,
+		BlockCommitment:                 b.BlockCommitment,
+		BlockHeight:                     b.BlockHeight,
+		StateRoot:                       b.StateRoot,
+		PriorityOperations:              b.PriorityOperations,
+		PendingOnChainOperationsHash:    b.PendingOnChainOperationsHash,
+		PendingOnChainOperationsPubData: b.PendingOnChainOperationsPubData,
+		CommittedTxHash:                 b.CommittedTxHash,
+		CommittedAt:                     b.CommittedAt,
+		VerifiedTxHash:                  b.VerifiedTxHash,
+		VerifiedAt:                      b.VerifiedAt,
+		BlockStatus:                     block.StatusVerifiedAndExecuted,
+	})
+	if err != nil {
+		return err
+	}
+	// The witness continues with the next block, the data of this one is
+	// not needed as it is verified already.
+	return ctx.BlockWitnessModel.CreateBlockWitnessInTransact(dbTx, &blockwitness.BlockWitness{
+		Height:    b.BlockHeight,
+		BlockSize: b.BlockSize,
+		Status:    blockwitness.StatusPruned,
+	})
+}
+
+func importAccounts(ctx *svc.ServiceContext, dbTx *gorm.DB, height int64, accounts []*snapshot.Account, targets []*stateTrees) error {
+	rows := make([]*account.Account, 0, len(accounts))
+	histories := make([]*account.AccountHistory, 0, len(accounts))
+	for _, a := range accounts {
+		for _, trees := range targets {
+			if err := trees.setAccount(a); err != nil {
+				return err
+			}
+		}
+		rows = append(rows, &account.Account{
+			AccountIndex:    a.AccountIndex,
+			AccountName:     a.AccountName,
+			PublicKey:       a.PublicKey,
+			AccountNameHash: a.AccountNameHash,
+			L1Address:       a.L1Address,
+			Nonce:           a.Nonce,
+			CollectionNonce: a.CollectionNonce,
+			AssetInfo:       a.AssetInfo,
+			AssetRoot:       a.AssetRoot,
+			Status:          account.AccountStatusConfirmed,
+		})
+		histories = append(histories, &account.AccountHistory{
+			AccountIndex:    a.AccountIndex,
+			Nonce:           a.Nonce,
+			CollectionNonce: a.CollectionNonce,
+			AssetInfo:       a.AssetInfo,
+			AssetRoot:       a.AssetRoot,
+			L2BlockHeight:   height,
+		})
+	}
+	if err := ctx.AccountModel.CreateAccountsInTransact(dbTx, rows); err != nil {
+		return err
+	}
+	return ctx.AccountHistoryModel.CreateAccountHistoriesInTransact(dbTx, histories)
+}
+
+func importNfts(ctx *svc.ServiceContext, dbTx *gorm.DB, height int64, nfts []*snapshot.Nft, targets []*stateTrees) error {
+	rows := make([]*nft.L2Nft, 0, len(nfts))
+	histories := make([]*nft.L2NftHistory, 0, len(nfts))
+	for _, n := range nfts {
+		for _, trees := range targets {
+			if err := trees.setNft(n); err != nil {
+				return err
+			}
+		}
+		rows = append(rows, &nft.L2Nft{
+			NftIndex:            n.NftIndex,
+			CreatorAccountIndex: n.CreatorAccountIndex,
+			OwnerAccountIndex:   n.OwnerAccountIndex,
+			NftContentHash:      n.NftContentHash,
+			NftL1Address:        n.NftL1Address,
+			NftL1TokenId:        n.NftL1TokenId,
+			CreatorTreasuryRate: n.CreatorTreasuryRate,
+			CollectionId:        n.CollectionId,
+		})
+		histories = append(histories, &nft.L2NftHistory{
+			NftIndex:            n.NftIndex,
+			CreatorAccountIndex: n.CreatorAccountIndex,
+			OwnerAccountIndex:   n.OwnerAccountIndex,
+			NftContentHash:      n.NftContentHash,
+			NftL1Address:        n.NftL1Address,
+			NftL1TokenId:        n.NftL1TokenId,
+			CreatorTreasuryRate: n.CreatorTreasuryRate,
+			CollectionId:        n.CollectionId,
+			L2BlockHeight:       height,
+		})
+	}
+	if err := ctx.L2NftModel.CreateNftsInTransact(dbTx, rows); err != nil {
+		return err
+	}
+	return ctx.L2NftHistoryModel.CreateNftHistoriesInTransact(dbTx, histories)
+}
@@ -0,0 +1,42 @@
+package svc
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/tools/snapshot/internal/config"
+)
+
+type ServiceContext struct {
+	Config config.Config
+
+	DB                  *gorm.DB
+	BlockModel          block.BlockModel
+	BlockWitnessModel   blockwitness.BlockWitnessModel
+	AccountModel        account.AccountModel
+	AccountHistoryModel account.AccountHistoryModel
+	L2NftModel          nft.L2NftModel
+	L2NftHistoryModel   nft.L2NftHistoryModel
+}
+
+func NewServiceContext(c config.Config) *ServiceContext {
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		logx.Errorf("gorm connect db error, err = %s", err.Error())
+	}
+	return &ServiceContext{
+		Config:              c,
+		DB:                  db,
+		BlockModel:          block.NewBlockModel(db),
+		BlockWitnessModel:   blockwitness.NewBlockWitnessModel(db),
+		AccountModel:        account.NewAccountModel(db),
+		AccountHistoryModel: account.NewAccountHistoryModel(db),
+		L2NftModel:          nft.NewL2NftModel(db),
+		L2NftHistoryModel:   nft.NewL2NftHistoryModel(db),
+	}
+}
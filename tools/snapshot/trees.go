@@ -0,0 +1,163 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database/memory"
+	"github.com/bnb-chain/zkbnb/common/snapshot"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// stateTrees are the trees the leaves of a snapshot are set in, an asset
+// tree is only kept while its account is set.
+type stateTrees struct {
+	accountTree  bsmt.SparseMerkleTree
+	nftTree      bsmt.SparseMerkleTree
+	newAssetTree func(accountIndex int64) (bsmt.SparseMerkleTree, error)
+	// persisted asset trees are committed once their account is set
+	commitAssets bool
+}
+
+func newMemStateTrees() (*stateTrees, error) {
+	hasher := bsmt.NewHasherPool(func() hash.Hash { return mimc.NewMiMC() })
+	accountTree, err := bsmt.NewBASSparseMerkleTree(hasher, memory.NewMemoryDB(), tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	nftTree, err := bsmt.NewBASSparseMerkleTree(hasher, memory.NewMemoryDB(), tree.NftTreeHeight, tree.NilNftNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	return &stateTrees{
+		accountTree: accountTree,
+		nftTree:     nftTree,
+		newAssetTree: func(accountIndex int64) (bsmt.SparseMerkleTree, error) {
+			return tree.NewMemAccountAssetTree()
+		},
+	}, nil
+}
+
+// newStateTrees opens the persisted trees of the service, they must be empty.
+// The account and nft trees are committed at the snapshot height.
+func newStateTrees(treeCtx *tree.Context, height int64) (*stateTrees, error) {
+	opts := append(treeCtx.Options(0), bsmt.InitializeVersion(bsmt.Version(height-1)))
+	accountTree, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+		tree.SetNamespace(treeCtx, tree.AccountPrefix), tree.AccountTreeHeight, tree.NilAccountNodeHash,
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	nftTree, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+		tree.SetNamespace(treeCtx, tree.NFTPrefix), tree.NftTreeHeight, tree.NilNftNodeHash,
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !accountTree.IsEmpty() || !nftTree.IsEmpty() {
+		return nil, fmt.Errorf("treedb of %s is not empty", treeCtx.Name)
+	}
+	return &stateTrees{
+		accountTree: accountTree,
+		nftTree:     nftTree,
+		newAssetTree: func(accountIndex int64) (bsmt.SparseMerkleTree, error) {
+			return bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+				tree.SetNamespace(treeCtx, tree.AccountAssetNamespace(accountIndex)), tree.AssetTreeHeight, tree.NilAccountAssetNodeHash,
+				treeCtx.Options(0)...)
+		},
+		commitAssets: true,
+	}, nil
+}
+
+// setAccount sets the assets and the account leaf, the asset root must
+// match the one of the account.
+func (t *stateTrees) setAccount(accountInfo *snapshot.Account) error {
+	var assetInfo map[int64]*types.AccountAsset
+	if err := json.Unmarshal([]byte(accountInfo.AssetInfo), &assetInfo); err != nil {
+		return fmt.Errorf("invalid assets of account %d: %v", accountInfo.AccountIndex, err)
+	}
+	assetTree, err := t.newAssetTree(accountInfo.AccountIndex)
+	if err != nil {
+		return err
+	}
+	if t.commitAssets && !assetTree.IsEmpty() {
+		return fmt.Errorf("asset tree of account %d is not empty", accountInfo.AccountIndex)
+	}
+	for assetId, asset := range assetInfo {
+		assetLeaf, err := tree.AssetToNode(asset.Balance.String(), asset.OfferCanceledOrFinalized.String())
+		if err != nil {
+			return err
+		}
+		if err = assetTree.Set(uint64(assetId), assetLeaf); err != nil {
+			return err
+		}
+	}
+	if t.commitAssets && len(assetInfo) > 0 {
+		version := assetTree.LatestVersion()
+		if _, err = assetTree.Commit(&version); err != nil {
+			return err
+		}
+	}
+	if common.Bytes2Hex(assetTree.Root()) != accountInfo.AssetRoot {
+		return fmt.Errorf("asset root mismatch of account %d", accountInfo.AccountIndex)
+	}
+
+	accountLeaf, err := tree.AccountToNode(
+		accountInfo.AccountNameHash,
+		accountInfo.PublicKey,
+		accountInfo.Nonce,
+		accountInfo.CollectionNonce,
+		assetTree.Root(),
+	)
+	if err != nil {
+		return err
+	}
+	return t.accountTree.Set(uint64(accountInfo.AccountIndex), accountLeaf)
+}
+
+func (t *stateTrees) setNft(nftInfo *snapshot.Nft) error {
+	nftLeaf, err := tree.NftAssetToNode(&nft.L2NftHistory{
+		NftIndex:            nftInfo.NftIndex,
+		CreatorAccountIndex: nftInfo.CreatorAccountIndex,
+		OwnerAccountIndex:   nftInfo.OwnerAccountIndex,
+		NftContentHash:      nftInfo.NftContentHash,
+		NftL1Address:        nftInfo.NftL1Address,
+		NftL1TokenId:        nftInfo.NftL1TokenId,
+		CreatorTreasuryRate: nftInfo.CreatorTreasuryRate,
+		CollectionId:        nftInfo.CollectionId,
+	})
+	if err != nil {
+		return err
+	}
+	return t.nftTree.Set(uint64(nftInfo.NftIndex), nftLeaf)
+}
+
+// commit commits the account and nft trees at the height, the versions
+// before it can't be rolled back to.
+func (t *stateTrees) commit(height int64) error {
+	recentVersion := bsmt.Version(height - 1)
+	for _, stateTree := range []bsmt.SparseMerkleTree{t.accountTree, t.nftTree} {
+		if _, err := stateTree.Commit(&recentVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRoots compares the roots of the trees with the ones of the footer.
+func (t *stateTrees) checkRoots(footer *snapshot.Footer) error {
+	if common.Bytes2Hex(t.accountTree.Root()) != footer.AccountRoot {
+		return fmt.Errorf("account root mismatch, expected: %s, actual: %s", footer.AccountRoot, common.Bytes2Hex(t.accountTree.Root()))
+	}
+	if common.Bytes2Hex(t.nftTree.Root()) != footer.NftRoot {
+		return fmt.Errorf("nft root mismatch, expected: %s, actual: %s", footer.NftRoot, common.Bytes2Hex(t.nftTree.Root()))
+	}
+	return nil
+}
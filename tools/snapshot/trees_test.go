@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb/common/snapshot"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestStateTrees(t *testing.T) {
+	sk, err := eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assets := map[int64]*types.AccountAsset{
+		0: {AssetId: 0, Balance: big.NewInt(100), OfferCanceledOrFinalized: big.NewInt(0)},
+	}
+	assetInfo, err := json.Marshal(assets)
+	require.NoError(t, err)
+	assetTree, err := tree.NewMemAccountAssetTree()
+	require.NoError(t, err)
+	leaf, err := tree.AssetToNode("100", "0")
+	require.NoError(t, err)
+	require.NoError(t, assetTree.Set(0, leaf))
+	accountInfo := &snapshot.Account{
+		AccountIndex:    1,
+		AccountNameHash: "0x01",
+		PublicKey:       common.Bytes2Hex(sk.PublicKey.Bytes()),
+		Nonce:           2,
+		AssetInfo:       string(assetInfo),
+		AssetRoot:       common.Bytes2Hex(assetTree.Root()),
+	}
+	nftInfo := &snapshot.Nft{NftIndex: 3, OwnerAccountIndex: 1, NftContentHash: "02", NftL1Address: "0x0000000000000000000000000000000000000000", NftL1TokenId: "0"}
+
+	expected, err := newMemStateTrees()
+	require.NoError(t, err)
+	require.NoError(t, expected.setAccount(accountInfo))
+	require.NoError(t, expected.setNft(nftInfo))
+	footer := &snapshot.Footer{
+		AccountRoot: common.Bytes2Hex(expected.accountTree.Root()),
+		NftRoot:     common.Bytes2Hex(expected.nftTree.Root()),
+	}
+
+	treeCtx, err := tree.NewContext("committer", tree.LevelDB, false, 0, &tree.LevelDBOption{File: t.TempDir()}, &tree.RedisDBOption{})
+	require.NoError(t, err)
+	require.NoError(t, tree.SetupTreeDB(treeCtx))
+	defer treeCtx.TreeDB.Close()
+	trees, err := newStateTrees(treeCtx, 10)
+	require.NoError(t, err)
+	require.NoError(t, trees.setAccount(accountInfo))
+	require.NoError(t, trees.setNft(nftInfo))
+	require.NoError(t, trees.checkRoots(footer))
+	require.NoError(t, trees.commit(10))
+	assert.Equal(t, bsmt.Version(10), trees.accountTree.LatestVersion())
+	assert.Equal(t, bsmt.Version(10), trees.nftTree.LatestVersion())
+
+	// the trees are persisted at the height, so they can't be imported again
+	_, err = newStateTrees(treeCtx, 10)
+	assert.Error(t, err)
+
+	// the asset root of the account does not match its assets
+	accountInfo.AssetRoot = common.Bytes2Hex(tree.NilAccountAssetRoot)
+	assert.Error(t, expected.setAccount(accountInfo))
+}
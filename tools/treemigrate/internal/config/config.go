@@ -0,0 +1,16 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+type Config struct {
+	// TreeDB is the pebble tree database to migrate into.
+	TreeDB struct {
+		Driver        tree.Driver
+		LevelDBOption tree.LevelDBOption
+	}
+	LogConf logx.LogConf
+}
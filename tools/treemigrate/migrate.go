@@ -0,0 +1,43 @@
+package treemigrate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+
+	"github.com/bnb-chain/zkbnb/tools/treemigrate/internal/config"
+	"github.com/bnb-chain/zkbnb/tree"
+)
+
+// MigrateTreeDB copies the leveldb tree database of a service into the
+// configured pebble tree database. The service must be stopped.
+func MigrateTreeDB(configFile string, levelDBFile string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	proc.AddShutdownListener(func() {
+		logx.Close()
+	})
+
+	if c.TreeDB.Driver != tree.PebbleDB {
+		return fmt.Errorf("migration target must use the %s driver, got %s", tree.PebbleDB, c.TreeDB.Driver)
+	}
+	if _, err := os.Stat(levelDBFile); err != nil {
+		return fmt.Errorf("leveldb tree database error: %v", err)
+	}
+	// Never mix the migrated keys with an existing database.
+	if _, err := os.Stat(c.TreeDB.LevelDBOption.File); err == nil {
+		return fmt.Errorf("pebble tree database %s already exists", c.TreeDB.LevelDBOption.File)
+	}
+
+	keys, err := tree.MigrateLevelDBToPebble(levelDBFile, &c.TreeDB.LevelDBOption)
+	if err != nil {
+		return fmt.Errorf("migrate tree database failed after %d keys: %v", keys, err)
+	}
+	logx.Infof("migrated %d keys from %s to %s", keys, levelDBFile, c.TreeDB.LevelDBOption.File)
+	return nil
+}
@@ -0,0 +1,360 @@
+package treeverify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database/memory"
+	"github.com/bnb-chain/zkbnb/tools/treeverify/internal/config"
+	"github.com/bnb-chain/zkbnb/tools/treeverify/internal/svc"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+var treeServices = []string{"committer", "witness"}
+
+// AssetMismatch is an asset leaf of an account which differs from the
+// database, AssetId is -1 if the persisted asset tree has assets which are
+// not in the database.
+type AssetMismatch struct {
+	AccountIndex int64
+	AssetId      int64
+}
+
+// Report lists the differences between the persisted trees of a service and
+// the state in the database at a height.
+type Report struct {
+	Service string
+	Height  int64
+
+	AccountMismatches []int64
+	AssetMismatches   []AssetMismatch
+	NftMismatches     []int64
+
+	// roots of the trees recomputed from the database
+	AccountRoot []byte
+	NftRoot     []byte
+	StateRoot   []byte
+	// state root of the block
+	BlockStateRoot string
+	// state root of the persisted trees, it is only known at their latest version
+	PersistedStateRoot []byte
+}
+
+func (r *Report) Consistent() bool {
+	return len(r.AccountMismatches) == 0 && len(r.AssetMismatches) == 0 && len(r.NftMismatches) == 0 &&
+		r.BlockStateRoot == common.Bytes2Hex(r.StateRoot) &&
+		(r.PersistedStateRoot == nil || bytes.Equal(r.PersistedStateRoot, r.StateRoot))
+}
+
+func (r *Report) Print() {
+	fmt.Printf("trees of %s at height %d:\n", r.Service, r.Height)
+	for _, index := range r.AccountMismatches {
+		fmt.Printf("  account leaf mismatch, account: %d\n", index)
+	}
+	for _, mismatch := range r.AssetMismatches {
+		if mismatch.AssetId < 0 {
+			fmt.Printf("  asset root mismatch, account: %d\n", mismatch.AccountIndex)
+			continue
+		}
+		fmt.Printf("  asset leaf mismatch, account: %d, asset: %d\n", mismatch.AccountIndex, mismatch.AssetId)
+	}
+	for _, index := range r.NftMismatches {
+		fmt.Printf("  nft leaf mismatch, nft: %d\n", index)
+	}
+	fmt.Printf("  state root of the database: %s\n", common.Bytes2Hex(r.StateRoot))
+	fmt.Printf("  state root of the block:    %s\n", r.BlockStateRoot)
+	if r.PersistedStateRoot != nil {
+		fmt.Printf("  state root of the trees:    %s\n", common.Bytes2Hex(r.PersistedStateRoot))
+	} else {
+		fmt.Println("  the roots and the asset trees are only compared at the latest tree version")
+	}
+	if r.Consistent() {
+		fmt.Println("  consistent")
+	}
+}
+
+// VerifyTreeDB recomputes the account, asset and nft leaves at the height
+// from the database and compares them with the persisted trees of the
+// service, or of the committer and the witness if serviceName is empty. The
+// services must be stopped.
+func VerifyTreeDB(configFile string, height int64, serviceName string, batchSize int) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	proc.AddShutdownListener(func() {
+		logx.Close()
+	})
+	ctx := svc.NewServiceContext(c)
+
+	if c.TreeDB.Driver == tree.MemoryDB {
+		return errors.New("the trees of memorydb are not persisted")
+	}
+	if batchSize <= 0 {
+		return errors.New("invalid batch size")
+	}
+	services := treeServices
+	if serviceName != "" {
+		services = []string{serviceName}
+	}
+
+	var inconsistent []string
+	for _, service := range services {
+		report, err := verifyServiceTrees(ctx, service, height, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to verify trees of %s, err: %v", service, err)
+		}
+		report.Print()
+		if !report.Consistent() {
+			inconsistent = append(inconsistent, service)
+		}
+	}
+	if len(inconsistent) > 0 {
+		return fmt.Errorf("trees of %v are inconsistent with the database at height %d", inconsistent, height)
+	}
+	return nil
+}
+
+func verifyServiceTrees(ctx *svc.ServiceContext, serviceName string, height int64, batchSize int) (*Report, error) {
+	c := ctx.Config
+	b, err := ctx.BlockModel.GetBlockByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d, err: %v", height, err)
+	}
+	treeCtx, err := tree.NewContext(serviceName, c.TreeDB.Driver, false, c.TreeDB.RoutinePoolSize, &c.TreeDB.LevelDBOption, &c.TreeDB.RedisDBOption)
+	if err != nil {
+		return nil, err
+	}
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer treeCtx.TreeDB.Close()
+
+	accountTree, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+		tree.SetNamespace(treeCtx, tree.AccountPrefix), tree.AccountTreeHeight, tree.NilAccountNodeHash,
+		treeCtx.Options(height)...)
+	if err != nil {
+		return nil, err
+	}
+	nftTree, err := bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+		tree.SetNamespace(treeCtx, tree.NFTPrefix), tree.NftTreeHeight, tree.NilNftNodeHash,
+		treeCtx.Options(height)...)
+	if err != nil {
+		return nil, err
+	}
+	version := bsmt.Version(height)
+	for _, t := range []bsmt.SparseMerkleTree{accountTree, nftTree} {
+		if version > t.LatestVersion() || version < t.RecentVersion() {
+			return nil, fmt.Errorf("tree can't be read at height %d, it keeps versions %d to %d",
+				height, t.RecentVersion(), t.LatestVersion())
+		}
+	}
+	// The asset trees are not versioned by height, they are only compared
+	// at the latest version.
+	latest := version == accountTree.LatestVersion()
+	var assetTrees func(index int64) (bsmt.SparseMerkleTree, error)
+	if latest {
+		assetTrees = func(index int64) (bsmt.SparseMerkleTree, error) {
+			return bsmt.NewBASSparseMerkleTree(treeCtx.Hasher(),
+				tree.SetNamespace(treeCtx, tree.AccountAssetNamespace(index)), tree.AssetTreeHeight, tree.NilAccountAssetNodeHash,
+				treeCtx.Options(height)...)
+		}
+	}
+
+	report := &Report{Service: serviceName, Height: height, BlockStateRoot: b.StateRoot}
+	accountRoot, err := verifyAccounts(ctx, height, batchSize, accountTree, &version, assetTrees, report)
+	if err != nil {
+		return nil, err
+	}
+	nftRoot, err := verifyNfts(ctx, height, batchSize, nftTree, &version, report)
+	if err != nil {
+		return nil, err
+	}
+	report.AccountRoot = accountRoot
+	report.NftRoot = nftRoot
+	report.StateRoot = tree.ComputeStateRootHash(accountRoot, nftRoot)
+	if latest {
+		report.PersistedStateRoot = tree.ComputeStateRootHash(accountTree.Root(), nftTree.Root())
+	}
+	return report, nil
+}
+
+// verifyAccounts compares the account leaves with the database and returns
+// the account root recomputed from the database.
+func verifyAccounts(
+	ctx *svc.ServiceContext,
+	height int64, batchSize int,
+	accountTree bsmt.SparseMerkleTree, version *bsmt.Version,
+	assetTrees func(index int64) (bsmt.SparseMerkleTree, error),
+	report *Report,
+) ([]byte, error) {
+	accountNums, err := ctx.AccountHistoryModel.GetValidAccountCount(height)
+	if err != nil {
+		return nil, err
+	}
+	expectedTree, err := newMemTree(tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset < int(accountNums); offset += batchSize {
+		accounts, err := tree.LoadValidAccounts(ctx.AccountModel, ctx.AccountHistoryModel, height, offset, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, accountInfo := range accounts {
+			var assetTree bsmt.SparseMerkleTree
+			if assetTrees != nil {
+				assetTree, err = assetTrees(accountInfo.AccountIndex)
+				if err != nil {
+					return nil, err
+				}
+			}
+			err = compareAccount(accountInfo, accountTree, version, assetTree, expectedTree, report)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	// No account is persisted after the accounts of the database.
+	leaf, err := getLeaf(accountTree, uint64(accountNums), version, tree.NilAccountNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(leaf, tree.NilAccountNodeHash) {
+		report.AccountMismatches = append(report.AccountMismatches, accountNums)
+	}
+	return expectedTree.Root(), nil
+}
+
+// compareAccount compares the leaves of the account and its assets, the
+// asset tree is nil if it is not compared. The account leaf recomputed from
+// the database is set in the expected tree.
+func compareAccount(
+	accountInfo *types.AccountInfo,
+	accountTree bsmt.SparseMerkleTree, version *bsmt.Version,
+	assetTree bsmt.SparseMerkleTree,
+	expectedTree bsmt.SparseMerkleTree,
+	report *Report,
+) error {
+	expectedAssetTree, err := tree.NewMemAccountAssetTree()
+	if err != nil {
+		return err
+	}
+	assetMismatch := false
+	for assetId, assetInfo := range accountInfo.AssetInfo {
+		assetLeaf, err := tree.AssetToNode(assetInfo.Balance.String(), assetInfo.OfferCanceledOrFinalized.String())
+		if err != nil {
+			return err
+		}
+		err = expectedAssetTree.Set(uint64(assetId), assetLeaf)
+		if err != nil {
+			return err
+		}
+		if assetTree == nil {
+			continue
+		}
+		leaf, err := getLeaf(assetTree, uint64(assetId), nil, tree.NilAccountAssetNodeHash)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(leaf, assetLeaf) {
+			assetMismatch = true
+			report.AssetMismatches = append(report.AssetMismatches, AssetMismatch{AccountIndex: accountInfo.AccountIndex, AssetId: assetId})
+		}
+	}
+	if assetTree != nil && !assetMismatch && !bytes.Equal(assetTree.Root(), expectedAssetTree.Root()) {
+		report.AssetMismatches = append(report.AssetMismatches, AssetMismatch{AccountIndex: accountInfo.AccountIndex, AssetId: -1})
+	}
+
+	accountLeaf, err := tree.AccountToNode(
+		accountInfo.AccountNameHash,
+		accountInfo.PublicKey,
+		accountInfo.Nonce,
+		accountInfo.CollectionNonce,
+		expectedAssetTree.Root(),
+	)
+	if err != nil {
+		return err
+	}
+	err = expectedTree.Set(uint64(accountInfo.AccountIndex), accountLeaf)
+	if err != nil {
+		return err
+	}
+	leaf, err := getLeaf(accountTree, uint64(accountInfo.AccountIndex), version, tree.NilAccountNodeHash)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(leaf, accountLeaf) {
+		report.AccountMismatches = append(report.AccountMismatches, accountInfo.AccountIndex)
+	}
+	return nil
+}
+
+// verifyNfts compares the nft leaves with the database and returns the nft
+// root recomputed from the database.
+func verifyNfts(
+	ctx *svc.ServiceContext,
+	height int64, batchSize int,
+	nftTree bsmt.SparseMerkleTree, version *bsmt.Version,
+	report *Report,
+) ([]byte, error) {
+	nums, err := ctx.NftHistoryModel.GetLatestNftsCountByBlockHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	expectedTree, err := newMemTree(tree.NftTreeHeight, tree.NilNftNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset < int(nums); offset += batchSize {
+		_, nftAssets, err := ctx.NftHistoryModel.GetLatestNftsByBlockHeight(height, batchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, nftAsset := range nftAssets {
+			nftLeaf, err := tree.NftAssetToNode(nftAsset)
+			if err != nil {
+				return nil, err
+			}
+			err = expectedTree.Set(uint64(nftAsset.NftIndex), nftLeaf)
+			if err != nil {
+				return nil, err
+			}
+			leaf, err := getLeaf(nftTree, uint64(nftAsset.NftIndex), version, tree.NilNftNodeHash)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(leaf, nftLeaf) {
+				report.NftMismatches = append(report.NftMismatches, nftAsset.NftIndex)
+			}
+		}
+	}
+	return expectedTree.Root(), nil
+}
+
+func newMemTree(height uint8, nilHash []byte) (bsmt.SparseMerkleTree, error) {
+	return bsmt.NewBASSparseMerkleTree(bsmt.NewHasherPool(func() hash.Hash { return mimc.NewMiMC() }), memory.NewMemoryDB(), height, nilHash)
+}
+
+// getLeaf reads a leaf of the tree, the leaves which were never set are nil.
+func getLeaf(t bsmt.SparseMerkleTree, key uint64, version *bsmt.Version, nilHash []byte) ([]byte, error) {
+	if t.IsEmpty() {
+		return nilHash, nil
+	}
+	leaf, err := t.Get(key, version)
+	if errors.Is(err, bsmt.ErrNodeNotFound) {
+		return nilHash, nil
+	}
+	return leaf, err
+}
@@ -0,0 +1,74 @@
+package treeverify
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+func TestCompareAccount(t *testing.T) {
+	sk, err := eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	accountInfo := &types.AccountInfo{
+		AccountIndex:    1,
+		AccountNameHash: "0x01",
+		PublicKey:       common.Bytes2Hex(sk.PublicKey.Bytes()),
+		Nonce:           2,
+		AssetInfo: map[int64]*types.AccountAsset{
+			0: {AssetId: 0, Balance: big.NewInt(100), OfferCanceledOrFinalized: big.NewInt(0)},
+			2: {AssetId: 2, Balance: big.NewInt(5), OfferCanceledOrFinalized: big.NewInt(0)},
+		},
+	}
+
+	// persisted trees derived from the same account
+	accountTree, err := newMemTree(tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	require.NoError(t, err)
+	assetTree, err := tree.NewMemAccountAssetTree()
+	require.NoError(t, err)
+	report := &Report{}
+	require.NoError(t, compareAccount(accountInfo, accountTree, nil, nil, accountTree, report))
+	for assetId, asset := range accountInfo.AssetInfo {
+		leaf, err := tree.AssetToNode(asset.Balance.String(), asset.OfferCanceledOrFinalized.String())
+		require.NoError(t, err)
+		require.NoError(t, assetTree.Set(uint64(assetId), leaf))
+	}
+	_, err = accountTree.Commit(nil)
+	require.NoError(t, err)
+	_, err = assetTree.Commit(nil)
+	require.NoError(t, err)
+
+	expectedTree, err := newMemTree(tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	require.NoError(t, err)
+	report = &Report{}
+	require.NoError(t, compareAccount(accountInfo, accountTree, nil, assetTree, expectedTree, report))
+	assert.Empty(t, report.AccountMismatches)
+	assert.Empty(t, report.AssetMismatches)
+	assert.Equal(t, accountTree.Root(), expectedTree.Root())
+
+	// the balance in the database differs from the trees
+	accountInfo.AssetInfo[2].Balance = big.NewInt(6)
+	report = &Report{}
+	require.NoError(t, compareAccount(accountInfo, accountTree, nil, assetTree, expectedTree, report))
+	assert.Equal(t, []int64{1}, report.AccountMismatches)
+	assert.Equal(t, []AssetMismatch{{AccountIndex: 1, AssetId: 2}}, report.AssetMismatches)
+
+	// an asset which is only in the trees
+	accountInfo.AssetInfo[2].Balance = big.NewInt(5)
+	leaf, err := tree.AssetToNode("1", "0")
+	require.NoError(t, err)
+	require.NoError(t, assetTree.Set(3, leaf))
+	_, err = assetTree.Commit(nil)
+	require.NoError(t, err)
+	report = &Report{}
+	require.NoError(t, compareAccount(accountInfo, accountTree, nil, assetTree, expectedTree, report))
+	assert.Empty(t, report.AccountMismatches)
+	assert.Equal(t, []AssetMismatch{{AccountIndex: 1, AssetId: -1}}, report.AssetMismatches)
+}
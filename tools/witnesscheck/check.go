@@ -0,0 +1,209 @@
+package witnesscheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb/common/prove"
+	"github.com/bnb-chain/zkbnb/common/storage"
+	"github.com/bnb-chain/zkbnb/dao/account"
+	"github.com/bnb-chain/zkbnb/dao/block"
+	"github.com/bnb-chain/zkbnb/dao/blockwitness"
+	"github.com/bnb-chain/zkbnb/dao/nft"
+	"github.com/bnb-chain/zkbnb/service/witness/config"
+	"github.com/bnb-chain/zkbnb/tree"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+// CheckBlockWitness runs the constraint solver on the stored witness of the
+// block, to tell a wrong witness from wrong keys when proving fails. With
+// rederive the witness is also constructed again from the database state and
+// compared with the stored one.
+func CheckBlockWitness(configFile string, height int64, rederive bool) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	proc.AddShutdownListener(func() {
+		logx.Close()
+	})
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db error, err: %v", err)
+	}
+	blockWitness, err := blockwitness.NewBlockWitnessModel(db).GetBlockWitnessByHeight(height)
+	if err != nil {
+		return fmt.Errorf("failed to get block witness %d, err: %v", height, err)
+	}
+	blobs, err := storage.New(c.WitnessStorage)
+	if err != nil {
+		return err
+	}
+	witnessData, err := blockwitness.NewWitnessStorage(blobs).GetWitnessData(blockWitness)
+	if err != nil {
+		return fmt.Errorf("failed to read block witness %d, err: %v", height, err)
+	}
+	var cBlock *circuit.Block
+	err = json.Unmarshal(witnessData, &cBlock)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal block witness, err: %v", err)
+	}
+
+	cs, err := prove.CompileBlockCircuit(len(cBlock.Txs))
+	if err != nil {
+		return fmt.Errorf("failed to compile block circuit, err: %v", err)
+	}
+	err = prove.CheckBlockWitness(cs, cBlock)
+	if err == nil {
+		fmt.Printf("witness of block %d satisfies the circuit of block size %d\n", height, len(cBlock.Txs))
+	} else {
+		fmt.Printf("witness of block %d does not satisfy the circuit: %v\n", height, err)
+		txIndex, txErr := prove.FindUnsatisfiedTx(cBlock)
+		if txIndex >= 0 {
+			fmt.Printf("first invalid tx: %d, tx type: %d, err: %v\n", txIndex, cBlock.Txs[txIndex].TxType, txErr)
+		} else if txErr != nil {
+			return fmt.Errorf("failed to check txs, err: %v", txErr)
+		} else {
+			fmt.Println("all txs are valid, the gas or the block commitment is invalid")
+		}
+	}
+
+	if !rederive {
+		return nil
+	}
+	derived, err := deriveBlockWitness(c, db, height)
+	if err != nil {
+		return fmt.Errorf("failed to derive block witness, err: %v", err)
+	}
+	diffs, err := diffBlockWitness(cBlock, derived)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("stored witness matches the witness derived from the database")
+	}
+	for _, diff := range diffs {
+		fmt.Println(diff)
+	}
+	return nil
+}
+
+// deriveBlockWitness constructs the witness of the block like the witness
+// service does, on in-memory trees of the state before the block.
+func deriveBlockWitness(c config.Config, db *gorm.DB, height int64) (*circuit.Block, error) {
+	blockModel := block.NewBlockModel(db)
+	accountModel := account.NewAccountModel(db)
+	accountHistoryModel := account.NewAccountHistoryModel(db)
+	nftHistoryModel := nft.NewL2NftHistoryModel(db)
+
+	blocks, err := blockModel.GetBlocksBetween(height, height)
+	if err != nil {
+		return nil, err
+	}
+	latestVerifiedHeight, err := blockModel.GetLatestVerifiedHeight()
+	if err != nil && err != types.DbErrNotFound {
+		return nil, err
+	}
+
+	treeCtx, err := tree.NewContext("witnesscheck", tree.MemoryDB, true, c.TreeDB.RoutinePoolSize, &tree.LevelDBOption{}, &tree.RedisDBOption{})
+	if err != nil {
+		return nil, err
+	}
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return nil, err
+	}
+	accountTree, assetTrees, err := tree.InitAccountTree(accountModel, accountHistoryModel, height-1, treeCtx, c.TreeDB.AssetTreeCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	nftTree, err := tree.InitNftTree(nftHistoryModel, height-1, treeCtx)
+	if err != nil {
+		return nil, err
+	}
+	helper := prove.NewWitnessHelper(treeCtx, accountTree, nftTree, assetTrees, accountModel, accountHistoryModel)
+	return helper.ConstructBlockWitness(blocks[0], uint64(latestVerifiedHeight))
+}
+
+// diffBlockWitness lists the fields of the stored witness which differ from
+// the derived one.
+func diffBlockWitness(stored, derived *circuit.Block) ([]string, error) {
+	var diffs []string
+	if !bytes.Equal(stored.OldStateRoot, derived.OldStateRoot) {
+		diffs = append(diffs, "old state root differs")
+	}
+	if !bytes.Equal(stored.NewStateRoot, derived.NewStateRoot) {
+		diffs = append(diffs, "new state root differs")
+	}
+	if !bytes.Equal(stored.BlockCommitment, derived.BlockCommitment) {
+		diffs = append(diffs, "block commitment differs")
+	}
+	if stored.CreatedAt != derived.CreatedAt {
+		diffs = append(diffs, "created at differs")
+	}
+	if len(stored.Txs) != len(derived.Txs) {
+		return append(diffs, fmt.Sprintf("tx count differs, stored: %d, derived: %d", len(stored.Txs), len(derived.Txs))), nil
+	}
+	for i := range stored.Txs {
+		fields, err := diffFields(stored.Txs[i], derived.Txs[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			diffs = append(diffs, fmt.Sprintf("tx %d differs in %v", i, fields))
+		}
+	}
+	fields, err := diffFields(stored.Gas, derived.Gas)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		diffs = append(diffs, fmt.Sprintf("gas differs in %v", fields))
+	}
+	return diffs, nil
+}
+
+// diffFields compares the json encodings of a and b field by field.
+func diffFields(a, b interface{}) ([]string, error) {
+	aFields, err := jsonFields(a)
+	if err != nil {
+		return nil, err
+	}
+	bFields, err := jsonFields(b)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for name, value := range aFields {
+		if !reflect.DeepEqual(value, bFields[name]) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range bFields {
+		if _, ok := aFields[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func jsonFields(v interface{}) (fields map[string]interface{}, err error) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bz, &fields)
+	return fields, err
+}
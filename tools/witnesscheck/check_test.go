@@ -0,0 +1,31 @@
+package witnesscheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+)
+
+func TestDiffBlockWitness(t *testing.T) {
+	stored := &circuit.Block{
+		OldStateRoot: []byte{1},
+		NewStateRoot: []byte{2},
+		Txs:          []*circuit.Tx{{TxType: 1, Nonce: 1}, {TxType: 2}},
+		Gas:          &circuit.Gas{GasAssetCount: 2},
+	}
+	derived := &circuit.Block{
+		OldStateRoot: []byte{1},
+		NewStateRoot: []byte{3},
+		Txs:          []*circuit.Tx{{TxType: 1, Nonce: 2}, {TxType: 2}},
+		Gas:          &circuit.Gas{GasAssetCount: 2},
+	}
+	diffs, err := diffBlockWitness(stored, derived)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new state root differs", "tx 0 differs in [Nonce]"}, diffs)
+
+	diffs, err = diffBlockWitness(stored, stored)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+}
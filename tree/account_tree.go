@@ -18,7 +18,6 @@
 package tree
 
 import (
-	"errors"
 	"hash"
 	"strconv"
 
@@ -32,7 +31,8 @@ import (
 	"github.com/bnb-chain/zkbnb/types"
 )
 
-func accountAssetNamespace(index int64) string {
+// AccountAssetNamespace is the namespace of the asset tree of the account.
+func AccountAssetNamespace(index int64) string {
 	return AccountAssetPrefix + strconv.Itoa(int(index)) + ":"
 }
 
@@ -56,7 +56,7 @@ func InitAccountTree(
 	// init account state trees
 	accountAssetTrees = NewLazyTreeCache(assetCacheSize, accountNums-1, blockHeight, func(index, block int64) bsmt.SparseMerkleTree {
 		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
-			SetNamespace(ctx, accountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
+			SetNamespace(ctx, AccountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
 			ctx.Options(block)...)
 		if err != nil {
 			logx.Errorf("unable to create new tree by assets: %s", err.Error())
@@ -136,11 +136,63 @@ func reloadAccountTreeFromRDB(
 	accountTree bsmt.SparseMerkleTree,
 	accountAssetTrees *AssetTreeCache,
 ) error {
+	accounts, err := LoadValidAccounts(accountModel, accountHistoryModel, blockHeight, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, accountInfo := range accounts {
+		accountIndex := accountInfo.AccountIndex
+		// create account assets node
+		for assetId, assetInfo := range accountInfo.AssetInfo {
+			hashVal, err := AssetToNode(
+				assetInfo.Balance.String(),
+				assetInfo.OfferCanceledOrFinalized.String(),
+			)
+			if err != nil {
+				logx.Errorf("unable to convert asset to node: %s", err.Error())
+				return err
+			}
+			err = accountAssetTrees.Get(accountIndex).Set(uint64(assetId), hashVal)
+			if err != nil {
+				logx.Errorf("unable to set asset to tree: %s", err.Error())
+				return err
+			}
+		}
+		accountHashVal, err := AccountToNode(
+			accountInfo.AccountNameHash,
+			accountInfo.PublicKey,
+			accountInfo.Nonce,
+			accountInfo.CollectionNonce,
+			accountAssetTrees.Get(accountIndex).Root(),
+		)
+		if err != nil {
+			logx.Errorf("unable to convert account to node: %s", err.Error())
+			return err
+		}
+		err = accountTree.Set(uint64(accountIndex), accountHashVal)
+		if err != nil {
+			logx.Errorf("unable to set account to tree: %s", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadValidAccounts reads the accounts at the block height from the account
+// histories, ordered by the account index.
+func LoadValidAccounts(
+	accountModel account.AccountModel,
+	accountHistoryModel account.AccountHistoryModel,
+	blockHeight int64,
+	offset, limit int,
+) ([]*types.AccountInfo, error) {
 	_, accountHistories, err := accountHistoryModel.GetValidAccounts(blockHeight,
 		limit, offset)
 	if err != nil {
 		logx.Errorf("unable to get all accountHistories")
-		return err
+		return nil, err
 	}
 
 	var (
@@ -152,7 +204,7 @@ func reloadAccountTreeFromRDB(
 			accountInfo, err := accountModel.GetAccountByIndex(accountHistory.AccountIndex)
 			if err != nil {
 				logx.Errorf("unable to get account by account index: %s", err.Error())
-				return err
+				return nil, err
 			}
 			accountInfoMap[accountHistory.AccountIndex] = &account.Account{
 				AccountIndex:    accountInfo.AccountIndex,
@@ -176,53 +228,22 @@ func reloadAccountTreeFromRDB(
 	}
 
 	// get related account info
+	accounts := make([]*types.AccountInfo, 0, len(accountInfoMap))
 	for i := int64(0); i < int64(len(accountHistories)); i++ {
 		accountIndex := accountHistories[i].AccountIndex
 		if accountInfoMap[accountIndex] == nil {
-			logx.Errorf("invalid account index")
-			return errors.New("invalid account index")
+			// converted already
+			continue
 		}
-		oAccountInfo := accountInfoMap[accountIndex]
-		accountInfo, err := chain.ToFormatAccountInfo(oAccountInfo)
+		accountInfo, err := chain.ToFormatAccountInfo(accountInfoMap[accountIndex])
 		if err != nil {
 			logx.Errorf("unable to convert to format account info: %s", err.Error())
-			return err
-		}
-		// create account assets node
-		for assetId, assetInfo := range accountInfo.AssetInfo {
-			hashVal, err := AssetToNode(
-				assetInfo.Balance.String(),
-				assetInfo.OfferCanceledOrFinalized.String(),
-			)
-			if err != nil {
-				logx.Errorf("unable to convert asset to node: %s", err.Error())
-				return err
-			}
-			err = accountAssetTrees.Get(accountIndex).Set(uint64(assetId), hashVal)
-			if err != nil {
-				logx.Errorf("unable to set asset to tree: %s", err.Error())
-				return err
-			}
-		}
-		accountHashVal, err := AccountToNode(
-			accountInfoMap[accountIndex].AccountNameHash,
-			accountInfoMap[accountIndex].PublicKey,
-			accountInfoMap[accountIndex].Nonce,
-			accountInfoMap[accountIndex].CollectionNonce,
-			accountAssetTrees.Get(accountIndex).Root(),
-		)
-		if err != nil {
-			logx.Errorf("unable to convert account to node: %s", err.Error())
-			return err
-		}
-		err = accountTree.Set(uint64(accountIndex), accountHashVal)
-		if err != nil {
-			logx.Errorf("unable to set account to tree: %s", err.Error())
-			return err
+			return nil, err
 		}
+		accounts = append(accounts, accountInfo)
+		delete(accountInfoMap, accountIndex)
 	}
-
-	return nil
+	return accounts, nil
 }
 
 func AssetToNode(balance string, offerCanceledOrFinalized string) (hashVal []byte, err error) {
@@ -0,0 +1,198 @@
+//go:build pebble
+// +build pebble
+
+package tree
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbnb-smt/database"
+)
+
+const (
+	// minimum cache and open files like the leveldb driver
+	minPebbleCache   = 16
+	minPebbleHandles = 16
+
+	migrateBatchSize = 16 * 1024 * 1024
+)
+
+var (
+	_ database.TreeDB  = (*pebbleDB)(nil)
+	_ database.Batcher = (*pebbleBatch)(nil)
+)
+
+type pebbleDB struct {
+	namespace []byte
+	db        *pebble.DB
+}
+
+func newPebbleDB(option *LevelDBOption) (database.TreeDB, error) {
+	cache := option.Cache
+	if cache < minPebbleCache {
+		cache = minPebbleCache
+	}
+	handles := option.Handles
+	if handles < minPebbleHandles {
+		handles = minPebbleHandles
+	}
+	blockCache := pebble.NewCache(int64(cache) * 1024 * 1024)
+	defer blockCache.Unref()
+	opts := &pebble.Options{
+		Cache:        blockCache,
+		MaxOpenFiles: handles,
+	}
+	if option.WriteBuffer > 0 {
+		opts.MemTableSize = option.WriteBuffer * 1024 * 1024
+	}
+	if option.MaxConcurrentCompactions > 0 {
+		compactions := option.MaxConcurrentCompactions
+		opts.MaxConcurrentCompactions = func() int { return compactions }
+	}
+	if option.L0CompactionThreshold > 0 {
+		opts.L0CompactionThreshold = option.L0CompactionThreshold
+	}
+	db, err := pebble.Open(option.File, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleDB{db: db}, nil
+}
+
+func wrapPebbleWithNamespace(db database.TreeDB, namespace string) database.TreeDB {
+	return &pebbleDB{
+		namespace: []byte(namespace),
+		db:        db.(*pebbleDB).db,
+	}
+}
+
+// wrapPebbleKey prefixes the key with the namespace like the leveldb driver,
+// so that the keys of both drivers are the same.
+func wrapPebbleKey(namespace, key []byte) []byte {
+	if len(namespace) > 0 {
+		return bytes.Join([][]byte{namespace, key}, []byte(":"))
+	}
+	return key
+}
+
+func (db *pebbleDB) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(wrapPebbleKey(db.namespace, key))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, closer.Close()
+}
+
+func (db *pebbleDB) Get(key []byte) ([]byte, error) {
+	value, closer, err := db.db.Get(wrapPebbleKey(db.namespace, key))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, database.ErrDatabaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the value is only valid until the closer is closed
+	dat := make([]byte, len(value))
+	copy(dat, value)
+	return dat, closer.Close()
+}
+
+func (db *pebbleDB) Set(key []byte, value []byte) error {
+	return db.db.Set(wrapPebbleKey(db.namespace, key), value, pebble.NoSync)
+}
+
+func (db *pebbleDB) Delete(key []byte) error {
+	return db.db.Delete(wrapPebbleKey(db.namespace, key), pebble.NoSync)
+}
+
+func (db *pebbleDB) NewBatch() database.Batcher {
+	return &pebbleBatch{
+		namespace: db.namespace,
+		b:         db.db.NewBatch(),
+	}
+}
+
+func (db *pebbleDB) Close() error {
+	return db.db.Close()
+}
+
+// pebbleBatch buffers the changes until Write is called, it cannot be used
+// concurrently.
+type pebbleBatch struct {
+	namespace []byte
+	b         *pebble.Batch
+	size      int
+}
+
+func (b *pebbleBatch) Set(key, value []byte) error {
+	b.size += len(value)
+	return b.b.Set(wrapPebbleKey(b.namespace, key), value, nil)
+}
+
+func (b *pebbleBatch) Delete(key []byte) error {
+	b.size += len(key)
+	return b.b.Delete(wrapPebbleKey(b.namespace, key), nil)
+}
+
+func (b *pebbleBatch) Write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+func (b *pebbleBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *pebbleBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// MigrateLevelDBToPebble copies all keys of the leveldb tree database into a
+// new pebble tree database.
+func MigrateLevelDBToPebble(levelDBFile string, pebbleOption *LevelDBOption) (keys int64, err error) {
+	src, err := leveldb.OpenFile(levelDBFile, &opt.Options{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	dst, err := newPebbleDB(pebbleOption)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	iter := src.NewIterator(nil, nil)
+	defer iter.Release()
+	batch := dst.NewBatch()
+	for iter.Next() {
+		// Set copies key and value into the batch.
+		if err = batch.Set(iter.Key(), iter.Value()); err != nil {
+			return keys, err
+		}
+		keys++
+		if batch.ValueSize() >= migrateBatchSize {
+			if err = batch.Write(); err != nil {
+				return keys, err
+			}
+			batch.Reset()
+			logx.Infof("migrated %d keys", keys)
+		}
+	}
+	if err = iter.Error(); err != nil {
+		return keys, err
+	}
+	if err = batch.Write(); err != nil {
+		return keys, err
+	}
+	// Flush the memtables, so that the migrated database does not depend on the wal.
+	return keys, dst.(*pebbleDB).db.Flush()
+}
@@ -0,0 +1,12 @@
+//go:build pebble
+// +build pebble
+
+package tree
+
+import (
+	"testing"
+)
+
+func BenchmarkCommitTreesPebble(b *testing.B) {
+	benchmarkCommitTrees(b, PebbleDB, &LevelDBOption{File: b.TempDir()})
+}
@@ -0,0 +1,26 @@
+//go:build !pebble
+// +build !pebble
+
+package tree
+
+import (
+	"errors"
+
+	"github.com/bnb-chain/zkbnb-smt/database"
+)
+
+var ErrPebbleNotBuilt = errors.New("pebble driver is not built in, rebuild with -tags pebble")
+
+func newPebbleDB(option *LevelDBOption) (database.TreeDB, error) {
+	return nil, ErrPebbleNotBuilt
+}
+
+func wrapPebbleWithNamespace(db database.TreeDB, namespace string) database.TreeDB {
+	return db
+}
+
+// MigrateLevelDBToPebble copies all keys of the leveldb tree database into a
+// new pebble tree database.
+func MigrateLevelDBToPebble(levelDBFile string, pebbleOption *LevelDBOption) (keys int64, err error) {
+	return 0, ErrPebbleNotBuilt
+}
@@ -0,0 +1,111 @@
+//go:build pebble
+// +build pebble
+
+package tree
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database"
+)
+
+func TestPebbleDB(t *testing.T) {
+	ctx, err := NewContext("pebble", PebbleDB, false, 0, &LevelDBOption{File: t.TempDir()}, &RedisDBOption{})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(ctx))
+	defer ctx.TreeDB.Close()
+
+	db := SetNamespace(ctx, AccountPrefix)
+	other := SetNamespace(ctx, NFTPrefix)
+
+	_, err = db.Get([]byte("a"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+	require.NoError(t, db.Set([]byte("a"), []byte("1")))
+	value, err := db.Get([]byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	has, err := db.Has([]byte("a"))
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	// the keys of the namespaces don't collide
+	has, err = other.Has([]byte("a"))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	require.NoError(t, db.Delete([]byte("a")))
+	has, err = db.Has([]byte("a"))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	// the changes of a batch are only visible once written
+	batch := db.NewBatch()
+	require.NoError(t, batch.Set([]byte("b"), []byte("22")))
+	require.NoError(t, batch.Set([]byte("c"), []byte("333")))
+	assert.Equal(t, 5, batch.ValueSize())
+	_, err = db.Get([]byte("b"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+	require.NoError(t, batch.Write())
+	value, err = db.Get([]byte("c"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("333"), value)
+
+	batch.Reset()
+	assert.Zero(t, batch.ValueSize())
+	require.NoError(t, batch.Delete([]byte("b")))
+	require.NoError(t, batch.Write())
+	_, err = db.Get([]byte("b"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+}
+
+func TestMigrateLevelDBToPebble(t *testing.T) {
+	newAccountTree := func(ctx *Context) bsmt.SparseMerkleTree {
+		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+			SetNamespace(ctx, AccountPrefix), AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+		require.NoError(t, err)
+		return tree
+	}
+
+	levelDBFile := filepath.Join(t.TempDir(), "leveldb")
+	src, err := NewContext("migrate", LevelDB, false, 0, &LevelDBOption{File: levelDBFile}, &RedisDBOption{})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(src))
+	srcTree := newAccountTree(src)
+	for version := 1; version <= 3; version++ {
+		for i := 0; i < 10; i++ {
+			leaf, err := AssetToNode(string(rune('0'+version)), string(rune('0'+i)))
+			require.NoError(t, err)
+			require.NoError(t, srcTree.Set(uint64(i*version), leaf))
+		}
+		_, err = srcTree.Commit(nil)
+		require.NoError(t, err)
+	}
+	root := srcTree.Root()
+	version := srcTree.LatestVersion()
+	expected, err := srcTree.Get(9, &version)
+	require.NoError(t, err)
+	require.NoError(t, src.TreeDB.Close())
+
+	pebbleOption := &LevelDBOption{File: filepath.Join(t.TempDir(), "pebble")}
+	keys, err := MigrateLevelDBToPebble(levelDBFile, pebbleOption)
+	require.NoError(t, err)
+	assert.Positive(t, keys)
+
+	dst, err := NewContext("migrate", PebbleDB, false, 0, pebbleOption, &RedisDBOption{})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(dst))
+	defer dst.TreeDB.Close()
+	dstTree := newAccountTree(dst)
+	assert.Equal(t, root, dstTree.Root())
+	assert.Equal(t, version, dstTree.LatestVersion())
+
+	// the migrated tree keeps its history
+	leaf, err := dstTree.Get(9, &version)
+	require.NoError(t, err)
+	assert.Equal(t, expected, leaf)
+}
@@ -0,0 +1,334 @@
+/*
+ * Copyright © 2021 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+	"github.com/bnb-chain/zkbnb-smt/database"
+	"github.com/bnb-chain/zkbnb/types"
+)
+
+const (
+	defaultPruneBatchSize = 1000
+	// pause between two batches, so that the commits are not starved
+	pruneBatchInterval = 100 * time.Millisecond
+	// pause after all trees are pruned
+	pruneRoundInterval = time.Minute
+)
+
+var (
+	// storage keys of zkbnb-smt
+	recentVersionKey = []byte("recentVersionNumber")
+	treeNodePrefix   = []byte("t")
+	treeNodeSep      = []byte(":")
+)
+
+type PruneOption struct {
+	//nolint:staticcheck
+	Enabled bool `json:",optional"`
+	// the versions of the latest RetainBlocks verified blocks are kept
+	//nolint:staticcheck
+	RetainBlocks int64 `json:",optional"`
+	// number of tree nodes pruned at once, the trees can't be committed meanwhile
+	//nolint:staticcheck
+	BatchSize int `json:",optional"`
+}
+
+type treeNodeKey struct {
+	depth uint8
+	path  uint64
+}
+
+func (k treeNodeKey) bytes() []byte {
+	pathBuf := make([]byte, 8)
+	binary.BigEndian.PutUint64(pathBuf, k.path)
+	return bytes.Join([][]byte{treeNodePrefix, {k.depth}, pathBuf}, treeNodeSep)
+}
+
+// Pruner deletes the versions of the account, nft and asset trees which are
+// older than the latest verified block minus the retention window. The trees
+// only prune the nodes they write on commit, so the nodes which are not
+// updated anymore keep all their versions without it.
+//
+// The stored nodes are walked incrementally in the background, a batch of
+// nodes at a time. A tree is never pruned beyond its recent version, which
+// is the oldest version it can be rolled back to, so RollBackTrees is not
+// affected. The pruner must be locked while the trees are committed or
+// rolled back, otherwise a node written by the tree could be overwritten.
+type Pruner struct {
+	mu sync.Mutex
+
+	ctx        *Context
+	option     PruneOption
+	assetTrees *AssetTreeCache
+
+	latestVerifiedHeight func() (int64, error)
+
+	// state of the current round
+	roundVersion bsmt.Version
+	nextTree     int64
+	db           database.TreeDB
+	batch        database.Batcher
+	version      bsmt.Version
+	height       uint8
+	stack        []treeNodeKey
+	prunedTrees  int64
+	prunedNodes  int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPruner creates a pruner of the trees in the context, latestVerifiedHeight
+// is usually BlockModel.GetLatestVerifiedHeight.
+func NewPruner(ctx *Context, option PruneOption, latestVerifiedHeight func() (int64, error), assetTrees *AssetTreeCache) *Pruner {
+	if option.BatchSize <= 0 {
+		option.BatchSize = defaultPruneBatchSize
+	}
+	return &Pruner{
+		ctx:                  ctx,
+		option:               option,
+		assetTrees:           assetTrees,
+		latestVerifiedHeight: latestVerifiedHeight,
+	}
+}
+
+// Lock stops the pruning until Unlock is called.
+func (p *Pruner) Lock() {
+	p.mu.Lock()
+}
+
+func (p *Pruner) Unlock() {
+	p.mu.Unlock()
+}
+
+// Start prunes the trees in the background if it is enabled. The memorydb
+// driver is not supported, because its trees are not shared.
+func (p *Pruner) Start() {
+	if !p.option.Enabled || p.ctx.Driver == MemoryDB || p.done != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.run()
+}
+
+// Stop waits for the running batch, it must be called before the tree
+// database is closed.
+func (p *Pruner) Stop() {
+	if p.done == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+}
+
+func (p *Pruner) run() {
+	defer close(p.done)
+	for {
+		finished, err := p.pruneBatch()
+		if err != nil {
+			logx.Errorf("prune trees error: %s", err.Error())
+		}
+		interval := pruneBatchInterval
+		if finished || err != nil {
+			interval = pruneRoundInterval
+		}
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// pruneBatch prunes the next batch of nodes and returns whether all trees
+// are pruned.
+func (p *Pruner) pruneBatch() (finished bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.db == nil && p.nextTree == 0 {
+		latestVerifiedHeight, err := p.latestVerifiedHeight()
+		if err == types.DbErrNotFound {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		if latestVerifiedHeight-p.option.RetainBlocks <= 0 {
+			return true, nil
+		}
+		p.roundVersion = bsmt.Version(latestVerifiedHeight - p.option.RetainBlocks)
+	}
+
+	for i := 0; i < p.option.BatchSize; {
+		if len(p.stack) == 0 {
+			if err = p.nextTreeDB(); err != nil {
+				p.resetRound()
+				return true, err
+			}
+			if p.db == nil {
+				logx.Infof("pruned %d nodes of %d trees up to version %d", p.prunedNodes, p.prunedTrees, p.roundVersion)
+				p.resetRound()
+				return true, nil
+			}
+			continue
+		}
+		key := p.stack[len(p.stack)-1]
+		p.stack = p.stack[:len(p.stack)-1]
+		if err = p.pruneNode(key); err != nil {
+			p.resetRound()
+			return true, err
+		}
+		i++
+	}
+	if err = p.flush(); err != nil {
+		p.resetRound()
+		return true, err
+	}
+	return false, nil
+}
+
+// nextTreeDB moves to the next tree with versions to prune, db is nil once
+// all trees are visited.
+func (p *Pruner) nextTreeDB() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	p.db = nil
+	accountNumber := p.assetTrees.GetNextAccountIndex()
+	for p.nextTree < accountNumber+2 {
+		index := p.nextTree
+		p.nextTree++
+
+		var namespace string
+		switch index {
+		case 0:
+			namespace, p.height = AccountPrefix, AccountTreeHeight
+		case 1:
+			namespace, p.height = NFTPrefix, NftTreeHeight
+		default:
+			namespace, p.height = AccountAssetNamespace(index-2), AssetTreeHeight
+		}
+		db := SetNamespace(p.ctx, namespace)
+		buf, err := db.Get(recentVersionKey)
+		if errors.Is(err, database.ErrDatabaseNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		recentVersion := bsmt.Version(binary.BigEndian.Uint64(buf))
+		if recentVersion == 0 {
+			continue
+		}
+		p.version = p.roundVersion
+		if recentVersion < p.version {
+			p.version = recentVersion
+		}
+		p.db = db
+		p.batch = db.NewBatch()
+		p.stack = append(p.stack, treeNodeKey{})
+		p.prunedTrees++
+		return nil
+	}
+	return nil
+}
+
+func (p *Pruner) pruneNode(key treeNodeKey) error {
+	buf, err := p.db.Get(key.bytes())
+	if errors.Is(err, database.ErrDatabaseNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	node := &bsmt.StorageTreeNode{}
+	if err = rlp.DecodeBytes(buf, node); err != nil {
+		return err
+	}
+
+	// The parent keeps a copy of the versions of its children.
+	var pruned bool
+	node.Versions, pruned = pruneVersions(node.Versions, p.version)
+	for nibble, child := range node.Children {
+		if child == nil || len(child.Versions) == 0 {
+			continue
+		}
+		var childPruned bool
+		child.Versions, childPruned = pruneVersions(child.Versions, p.version)
+		pruned = pruned || childPruned
+		if key.depth+4 <= p.height {
+			p.stack = append(p.stack, treeNodeKey{depth: key.depth + 4, path: key.path<<4 | uint64(nibble)})
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	buf, err = rlp.EncodeToBytes(node)
+	if err != nil {
+		return err
+	}
+	p.prunedNodes++
+	return p.batch.Set(key.bytes(), buf)
+}
+
+func (p *Pruner) flush() error {
+	if p.batch == nil || p.batch.ValueSize() == 0 {
+		return nil
+	}
+	if err := p.batch.Write(); err != nil {
+		return err
+	}
+	p.batch.Reset()
+	return nil
+}
+
+func (p *Pruner) resetRound() {
+	p.nextTree = 0
+	p.db = nil
+	p.batch = nil
+	p.stack = p.stack[:0]
+	p.prunedTrees = 0
+	p.prunedNodes = 0
+}
+
+// pruneVersions drops the versions before the version, like the tree does,
+// the latest of them is kept as it is still the value at the version.
+func pruneVersions(versions []*bsmt.VersionInfo, version bsmt.Version) ([]*bsmt.VersionInfo, bool) {
+	i := 0
+	for ; i < len(versions)-1; i++ {
+		if versions[i+1].Ver > version {
+			break
+		}
+	}
+	if i == 0 {
+		return versions, false
+	}
+	return versions[i:], true
+}
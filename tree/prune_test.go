@@ -0,0 +1,88 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+)
+
+func TestPruneVersions(t *testing.T) {
+	versions := []*bsmt.VersionInfo{{Ver: 1}, {Ver: 3}, {Ver: 5}}
+
+	pruned, ok := pruneVersions(versions, 0)
+	assert.False(t, ok)
+	assert.Len(t, pruned, 3)
+
+	pruned, ok = pruneVersions(versions, 4)
+	assert.True(t, ok)
+	assert.Equal(t, []bsmt.Version{3, 5}, []bsmt.Version{pruned[0].Ver, pruned[1].Ver})
+
+	pruned, ok = pruneVersions(versions, 5)
+	assert.True(t, ok)
+	assert.Len(t, pruned, 1)
+	assert.Equal(t, bsmt.Version(5), pruned[0].Ver)
+}
+
+func TestPrunerKeepsRecentVersions(t *testing.T) {
+	ctx, err := NewContext("prune", LevelDB, false, 0, &LevelDBOption{File: t.TempDir()}, &RedisDBOption{})
+	require.NoError(t, err)
+	require.NoError(t, SetupTreeDB(ctx))
+	defer ctx.TreeDB.Close()
+
+	newAccountTree := func() bsmt.SparseMerkleTree {
+		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+			SetNamespace(ctx, AccountPrefix), AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+		require.NoError(t, err)
+		return tree
+	}
+	accountTree := newAccountTree()
+	leaves := make([][]byte, 6)
+	for i := range leaves {
+		leaves[i], err = AssetToNode(string(rune('1'+i)), "0")
+		require.NoError(t, err)
+	}
+	// Account 1 is updated in versions 1 to 3, account 0 in all versions.
+	for version := 1; version <= 5; version++ {
+		require.NoError(t, accountTree.Set(0, leaves[version]))
+		if version <= 3 {
+			require.NoError(t, accountTree.Set(1, leaves[version]))
+		}
+		prune := bsmt.Version(version - 1)
+		_, err = accountTree.Commit(&prune)
+		require.NoError(t, err)
+	}
+	root := accountTree.Root()
+
+	latestVerifiedHeight := func() (int64, error) { return 10, nil }
+	pruner := NewPruner(ctx, PruneOption{Enabled: true, BatchSize: 2}, latestVerifiedHeight,
+		NewLazyTreeCache(1, -1, 0, nil))
+	finished := false
+	for !finished {
+		finished, err = pruner.pruneBatch()
+		require.NoError(t, err)
+	}
+
+	db := SetNamespace(ctx, AccountPrefix)
+	buf, err := db.Get(treeNodeKey{depth: AccountTreeHeight, path: 1}.bytes())
+	require.NoError(t, err)
+	node := &bsmt.StorageTreeNode{}
+	require.NoError(t, rlp.DecodeBytes(buf, node))
+	// pruned to the recent version of the tree
+	require.Len(t, node.Versions, 1)
+	assert.Equal(t, bsmt.Version(3), node.Versions[0].Ver)
+
+	reloaded := newAccountTree()
+	assert.Equal(t, root, reloaded.Root())
+	recentVersion := reloaded.RecentVersion()
+	value, err := reloaded.Get(1, &recentVersion)
+	require.NoError(t, err)
+	assert.Equal(t, leaves[3], value)
+	require.NoError(t, reloaded.Rollback(recentVersion))
+	value, err = reloaded.Get(0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, leaves[4], value)
+}
@@ -24,12 +24,22 @@ var (
 
 type Driver string
 
+// LevelDBOption configures the leveldb and the pebble drivers, Cache is in MB
+// and Handles is the number of open files.
 type LevelDBOption struct {
 	File string
 	//nolint:staticcheck
 	Cache int `json:",optional"`
 	//nolint:staticcheck
 	Handles int `json:",optional"`
+	// Pebble only: memtable size in MB, number of concurrent compactions and
+	// number of L0 files which trigger a compaction.
+	//nolint:staticcheck
+	WriteBuffer int `json:",optional"`
+	//nolint:staticcheck
+	MaxConcurrentCompactions int `json:",optional"`
+	//nolint:staticcheck
+	L0CompactionThreshold int `json:",optional"`
 }
 
 type RedisDBOption struct {
@@ -134,6 +144,8 @@ const (
 	MemoryDB Driver = "memorydb"
 	LevelDB  Driver = "leveldb"
 	RedisDB  Driver = "redis"
+	// PebbleDB requires a build with the pebble tag.
+	PebbleDB Driver = "pebble"
 )
 
 func SetupTreeDB(
@@ -150,6 +162,13 @@ func SetupTreeDB(
 		}
 		context.TreeDB = db
 		return nil
+	case PebbleDB:
+		db, err := newPebbleDB(context.LevelDBOption)
+		if err != nil {
+			return err
+		}
+		context.TreeDB = db
+		return nil
 	case RedisDB:
 		bytes, err := json.Marshal(context.RedisDBOption)
 		if err != nil {
@@ -179,6 +198,8 @@ func SetNamespace(
 		return memory.NewMemoryDB()
 	case LevelDB:
 		return leveldb.WrapWithNamespace(context.TreeDB.(*leveldb.Database), strings.Join([]string{context.Name, namespace}, ":"))
+	case PebbleDB:
+		return wrapPebbleWithNamespace(context.TreeDB, strings.Join([]string{context.Name, namespace}, ":"))
 	case RedisDB:
 		return redis.WrapWithNamespace(context.TreeDB.(*redis.Database), strings.Join([]string{context.Name, namespace}, ":"))
 	}
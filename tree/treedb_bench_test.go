@@ -0,0 +1,78 @@
+package tree
+
+import (
+	"strconv"
+	"testing"
+
+	bsmt "github.com/bnb-chain/zkbnb-smt"
+)
+
+// accounts updated by each benchmarked block
+const benchAccountsPerBlock = 64
+
+func BenchmarkCommitTreesMemoryDB(b *testing.B) {
+	benchmarkCommitTrees(b, MemoryDB, &LevelDBOption{})
+}
+
+func BenchmarkCommitTreesLevelDB(b *testing.B) {
+	benchmarkCommitTrees(b, LevelDB, &LevelDBOption{File: b.TempDir()})
+}
+
+// benchmarkCommitTrees updates the trees like a block does and commits them.
+func benchmarkCommitTrees(b *testing.B, driver Driver, option *LevelDBOption) {
+	ctx, err := NewContext("bench", driver, false, 0, option, &RedisDBOption{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err = SetupTreeDB(ctx); err != nil {
+		b.Fatal(err)
+	}
+	defer ctx.TreeDB.Close()
+
+	assetTrees := NewLazyTreeCache(1024, -1, 0, func(index, block int64) bsmt.SparseMerkleTree {
+		tree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+			SetNamespace(ctx, AccountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
+			ctx.Options(block)...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return tree
+	})
+	accountTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+		SetNamespace(ctx, AccountPrefix), AccountTreeHeight, NilAccountNodeHash, ctx.Options(0)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	nftTree, err := bsmt.NewBASSparseMerkleTree(ctx.Hasher(),
+		SetNamespace(ctx, NFTPrefix), NftTreeHeight, NilNftNodeHash, ctx.Options(0)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		leaf, err := AssetToNode(strconv.Itoa(i+1), "0")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < benchAccountsPerBlock; j++ {
+			index := int64(i*benchAccountsPerBlock + j)
+			assetTrees.UpdateCache(index, int64(i))
+			if err = assetTrees.Get(index).Set(0, leaf); err != nil {
+				b.Fatal(err)
+			}
+			if err = accountTree.Set(uint64(index), leaf); err != nil {
+				b.Fatal(err)
+			}
+			if err = nftTree.Set(uint64(index), leaf); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StartTimer()
+
+		if err = CommitTrees(uint64(i), accountTree, assetTrees, nftTree); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
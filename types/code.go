@@ -46,7 +46,7 @@ var (
 
 	IoErrFailToRead = errors.New("ioutil.ReadAll err")
 
-	CmcNotListedErr = errors.New("cmc not listed")
+	PriceNotListedErr = errors.New("price not listed")
 
 	AppErrInvalidParam   = New(20001, "invalid param: ")
 	AppErrInvalidTxField = New(20002, "invalid tx field: ")